@@ -71,12 +71,17 @@ func (JobType) EnumDescriptor() ([]byte, []int) {
 }
 
 type RegisterReq struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Ip            string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
-	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Hostname        string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ip              string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Tags            []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Version         string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Os              string                 `protobuf:"bytes,5,opt,name=os,proto3" json:"os,omitempty"`
+	Arch            string                 `protobuf:"bytes,6,opt,name=arch,proto3" json:"arch,omitempty"`
+	UpdateError     string                 `protobuf:"bytes,7,opt,name=update_error,json=updateError,proto3" json:"update_error,omitempty"`             // 上一次自更新失败并回滚的原因, 注册成功后即清空
+	EnrollmentToken string                 `protobuf:"bytes,8,opt,name=enrollment_token,json=enrollmentToken,proto3" json:"enrollment_token,omitempty"` // 项目的 enrollment token, 只有首次加入项目时需要
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *RegisterReq) Reset() {
@@ -130,12 +135,48 @@ func (x *RegisterReq) GetTags() []string {
 	return nil
 }
 
+func (x *RegisterReq) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RegisterReq) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *RegisterReq) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *RegisterReq) GetUpdateError() string {
+	if x != nil {
+		return x.UpdateError
+	}
+	return ""
+}
+
+func (x *RegisterReq) GetEnrollmentToken() string {
+	if x != nil {
+		return x.EnrollmentToken
+	}
+	return ""
+}
+
 type RegisterResp struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AgentId         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Success         bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	AgentCredential string                 `protobuf:"bytes,3,opt,name=agent_credential,json=agentCredential,proto3" json:"agent_credential,omitempty"` // 新签发的 credential, 只返回这一次, Agent 需要保存下来; 沿用旧 credential 时为空
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *RegisterResp) Reset() {
@@ -182,12 +223,20 @@ func (x *RegisterResp) GetSuccess() bool {
 	return false
 }
 
+func (x *RegisterResp) GetAgentCredential() string {
+	if x != nil {
+		return x.AgentCredential
+	}
+	return ""
+}
+
 type HeartbeatReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	CpuUsage      float64                `protobuf:"fixed64,3,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
+	CpuUsage      float64                `protobuf:"fixed64,3,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"` // 百分比 0-100, Agent 取不到时 cpu / mem 都为 0
 	MemUsage      float64                `protobuf:"fixed64,4,opt,name=mem_usage,json=memUsage,proto3" json:"mem_usage,omitempty"`
+	ConfigVersion string                 `protobuf:"bytes,5,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"` // Agent 当前生效的远程配置版本, 和服务端不一致时返回 config_outdated
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -250,11 +299,24 @@ func (x *HeartbeatReq) GetMemUsage() float64 {
 	return 0
 }
 
+func (x *HeartbeatReq) GetConfigVersion() string {
+	if x != nil {
+		return x.ConfigVersion
+	}
+	return ""
+}
+
 type Job struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Type          JobType                `protobuf:"varint,2,opt,name=type,proto3,enum=sentinel.JobType" json:"type,omitempty"`
-	Payload       string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	JobId   string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Type    JobType                `protobuf:"varint,2,opt,name=type,proto3,enum=sentinel.JobType" json:"type,omitempty"`
+	Payload string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	// 以下字段由提交任务时的 Ed25519 签名覆盖, 见 pkg/jobsign
+	AgentId   string `protobuf:"bytes,4,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	ExpiresAt int64  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix 秒
+	Signature []byte `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	// 注入任务环境变量的密钥. env / name 同样由签名覆盖, value 在派发时才从密钥库解密填入, 不落库也不签名
+	Secrets       []*SecretEnv `protobuf:"bytes,7,rep,name=secrets,proto3" json:"secrets,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -310,6 +372,94 @@ func (x *Job) GetPayload() string {
 	return ""
 }
 
+func (x *Job) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Job) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *Job) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Job) GetSecrets() []*SecretEnv {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+type SecretEnv struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Env           string                 `protobuf:"bytes,1,opt,name=env,proto3" json:"env,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecretEnv) Reset() {
+	*x = SecretEnv{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecretEnv) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecretEnv) ProtoMessage() {}
+
+func (x *SecretEnv) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecretEnv.ProtoReflect.Descriptor instead.
+func (*SecretEnv) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SecretEnv) GetEnv() string {
+	if x != nil {
+		return x.Env
+	}
+	return ""
+}
+
+func (x *SecretEnv) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SecretEnv) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
 type ReportJobReq struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -322,7 +472,7 @@ type ReportJobReq struct {
 
 func (x *ReportJobReq) Reset() {
 	*x = ReportJobReq{}
-	mi := &file_api_proto_sentinel_proto_msgTypes[4]
+	mi := &file_api_proto_sentinel_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -334,7 +484,7 @@ func (x *ReportJobReq) String() string {
 func (*ReportJobReq) ProtoMessage() {}
 
 func (x *ReportJobReq) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_sentinel_proto_msgTypes[4]
+	mi := &file_api_proto_sentinel_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -347,7 +497,7 @@ func (x *ReportJobReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportJobReq.ProtoReflect.Descriptor instead.
 func (*ReportJobReq) Descriptor() ([]byte, []int) {
-	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{4}
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ReportJobReq) GetAgentId() string {
@@ -387,7 +537,7 @@ type ReportJobResp struct {
 
 func (x *ReportJobResp) Reset() {
 	*x = ReportJobResp{}
-	mi := &file_api_proto_sentinel_proto_msgTypes[5]
+	mi := &file_api_proto_sentinel_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -399,7 +549,7 @@ func (x *ReportJobResp) String() string {
 func (*ReportJobResp) ProtoMessage() {}
 
 func (x *ReportJobResp) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_sentinel_proto_msgTypes[5]
+	mi := &file_api_proto_sentinel_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -412,7 +562,7 @@ func (x *ReportJobResp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportJobResp.ProtoReflect.Descriptor instead.
 func (*ReportJobResp) Descriptor() ([]byte, []int) {
-	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{5}
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ReportJobResp) GetReceived() bool {
@@ -422,17 +572,87 @@ func (x *ReportJobResp) GetReceived() bool {
 	return false
 }
 
+type JobOutputChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Seq           int64                  `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"` // 同一个任务内从 1 开始递增
+	Data          string                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobOutputChunk) Reset() {
+	*x = JobOutputChunk{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobOutputChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobOutputChunk) ProtoMessage() {}
+
+func (x *JobOutputChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobOutputChunk.ProtoReflect.Descriptor instead.
+func (*JobOutputChunk) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *JobOutputChunk) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *JobOutputChunk) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobOutputChunk) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *JobOutputChunk) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
 type HeartbeatResp struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	ConfigOutdated bool                   `protobuf:"varint,1,opt,name=config_outdated,json=configOutdated,proto3" json:"config_outdated,omitempty"`
 	Job            *Job                   `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
+	CancelJobIds   []string               `protobuf:"bytes,3,rep,name=cancel_job_ids,json=cancelJobIds,proto3" json:"cancel_job_ids,omitempty"`
+	Update         *AgentUpdate           `protobuf:"bytes,4,opt,name=update,proto3" json:"update,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *HeartbeatResp) Reset() {
 	*x = HeartbeatResp{}
-	mi := &file_api_proto_sentinel_proto_msgTypes[6]
+	mi := &file_api_proto_sentinel_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -444,7 +664,7 @@ func (x *HeartbeatResp) String() string {
 func (*HeartbeatResp) ProtoMessage() {}
 
 func (x *HeartbeatResp) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_sentinel_proto_msgTypes[6]
+	mi := &file_api_proto_sentinel_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -457,7 +677,7 @@ func (x *HeartbeatResp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatResp.ProtoReflect.Descriptor instead.
 func (*HeartbeatResp) Descriptor() ([]byte, []int) {
-	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{6}
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *HeartbeatResp) GetConfigOutdated() bool {
@@ -474,90 +694,4712 @@ func (x *HeartbeatResp) GetJob() *Job {
 	return nil
 }
 
-var File_api_proto_sentinel_proto protoreflect.FileDescriptor
+func (x *HeartbeatResp) GetCancelJobIds() []string {
+	if x != nil {
+		return x.CancelJobIds
+	}
+	return nil
+}
 
-const file_api_proto_sentinel_proto_rawDesc = "" +
-	"\n" +
-	"\x18api/proto/sentinel.proto\x12\bsentinel\"M\n" +
-	"\vRegisterReq\x12\x1a\n" +
-	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
-	"\x02ip\x18\x02 \x01(\tR\x02ip\x12\x12\n" +
-	"\x04tags\x18\x03 \x03(\tR\x04tags\"C\n" +
-	"\fRegisterResp\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
-	"\asuccess\x18\x02 \x01(\bR\asuccess\"\x81\x01\n" +
-	"\fHeartbeatReq\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1c\n" +
-	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x1b\n" +
-	"\tcpu_usage\x18\x03 \x01(\x01R\bcpuUsage\x12\x1b\n" +
-	"\tmem_usage\x18\x04 \x01(\x01R\bmemUsage\"]\n" +
-	"\x03Job\x12\x15\n" +
-	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12%\n" +
-	"\x04type\x18\x02 \x01(\x0e2\x11.sentinel.JobTypeR\x04type\x12\x18\n" +
-	"\apayload\x18\x03 \x01(\tR\apayload\"p\n" +
-	"\fReportJobReq\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x15\n" +
-	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\x12\x16\n" +
-	"\x06result\x18\x04 \x01(\tR\x06result\"+\n" +
-	"\rReportJobResp\x12\x1a\n" +
-	"\breceived\x18\x01 \x01(\bR\breceived\"Y\n" +
-	"\rHeartbeatResp\x12'\n" +
-	"\x0fconfig_outdated\x18\x01 \x01(\bR\x0econfigOutdated\x12\x1f\n" +
-	"\x03job\x18\x02 \x01(\v2\r.sentinel.JobR\x03job*(\n" +
-	"\aJobType\x12\b\n" +
-	"\x04PING\x10\x00\x12\t\n" +
-	"\x05SHELL\x10\x01\x12\b\n" +
-	"\x04SCAN\x10\x022\xd2\x01\n" +
-	"\x0fSentinelService\x129\n" +
-	"\bRegister\x12\x15.sentinel.RegisterReq\x1a\x16.sentinel.RegisterResp\x12@\n" +
-	"\tHeartbeat\x12\x16.sentinel.HeartbeatReq\x1a\x17.sentinel.HeartbeatResp(\x010\x01\x12B\n" +
-	"\x0fReportJobStatus\x12\x16.sentinel.ReportJobReq\x1a\x17.sentinel.ReportJobRespB\aZ\x05./;pbb\x06proto3"
+func (x *HeartbeatResp) GetUpdate() *AgentUpdate {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
 
-var (
-	file_api_proto_sentinel_proto_rawDescOnce sync.Once
-	file_api_proto_sentinel_proto_rawDescData []byte
-)
+type GetAgentConfigReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-func file_api_proto_sentinel_proto_rawDescGZIP() []byte {
-	file_api_proto_sentinel_proto_rawDescOnce.Do(func() {
-		file_api_proto_sentinel_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_sentinel_proto_rawDesc), len(file_api_proto_sentinel_proto_rawDesc)))
-	})
-	return file_api_proto_sentinel_proto_rawDescData
+func (x *GetAgentConfigReq) Reset() {
+	*x = GetAgentConfigReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-var file_api_proto_sentinel_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_api_proto_sentinel_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
-var file_api_proto_sentinel_proto_goTypes = []any{
-	(JobType)(0),          // 0: sentinel.JobType
-	(*RegisterReq)(nil),   // 1: sentinel.RegisterReq
-	(*RegisterResp)(nil),  // 2: sentinel.RegisterResp
-	(*HeartbeatReq)(nil),  // 3: sentinel.HeartbeatReq
-	(*Job)(nil),           // 4: sentinel.Job
-	(*ReportJobReq)(nil),  // 5: sentinel.ReportJobReq
-	(*ReportJobResp)(nil), // 6: sentinel.ReportJobResp
-	(*HeartbeatResp)(nil), // 7: sentinel.HeartbeatResp
+func (x *GetAgentConfigReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-var file_api_proto_sentinel_proto_depIdxs = []int32{
-	0, // 0: sentinel.Job.type:type_name -> sentinel.JobType
-	4, // 1: sentinel.HeartbeatResp.job:type_name -> sentinel.Job
-	1, // 2: sentinel.SentinelService.Register:input_type -> sentinel.RegisterReq
-	3, // 3: sentinel.SentinelService.Heartbeat:input_type -> sentinel.HeartbeatReq
-	5, // 4: sentinel.SentinelService.ReportJobStatus:input_type -> sentinel.ReportJobReq
-	2, // 5: sentinel.SentinelService.Register:output_type -> sentinel.RegisterResp
-	7, // 6: sentinel.SentinelService.Heartbeat:output_type -> sentinel.HeartbeatResp
-	6, // 7: sentinel.SentinelService.ReportJobStatus:output_type -> sentinel.ReportJobResp
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+
+func (*GetAgentConfigReq) ProtoMessage() {}
+
+func (x *GetAgentConfigReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func init() { file_api_proto_sentinel_proto_init() }
-func file_api_proto_sentinel_proto_init() {
-	if File_api_proto_sentinel_proto != nil {
-		return
+// Deprecated: Use GetAgentConfigReq.ProtoReflect.Descriptor instead.
+func (*GetAgentConfigReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetAgentConfigReq) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+// AgentConfig 服务端下发的 Agent 配置, 零值字段表示沿用 Agent 本地配置
+type AgentConfig struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Version                  string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	HeartbeatIntervalSeconds int32                  `protobuf:"varint,2,opt,name=heartbeat_interval_seconds,json=heartbeatIntervalSeconds,proto3" json:"heartbeat_interval_seconds,omitempty"`
+	WorkerPoolSize           int32                  `protobuf:"varint,3,opt,name=worker_pool_size,json=workerPoolSize,proto3" json:"worker_pool_size,omitempty"`
+	AllowedJobTypes          []JobType              `protobuf:"varint,4,rep,packed,name=allowed_job_types,json=allowedJobTypes,proto3,enum=sentinel.JobType" json:"allowed_job_types,omitempty"` // 为空表示不限制
+	LogLevel                 string                 `protobuf:"bytes,5,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *AgentConfig) Reset() {
+	*x = AgentConfig{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConfig) ProtoMessage() {}
+
+func (x *AgentConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConfig.ProtoReflect.Descriptor instead.
+func (*AgentConfig) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AgentConfig) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetHeartbeatIntervalSeconds() int32 {
+	if x != nil {
+		return x.HeartbeatIntervalSeconds
+	}
+	return 0
+}
+
+func (x *AgentConfig) GetWorkerPoolSize() int32 {
+	if x != nil {
+		return x.WorkerPoolSize
+	}
+	return 0
+}
+
+func (x *AgentConfig) GetAllowedJobTypes() []JobType {
+	if x != nil {
+		return x.AllowedJobTypes
+	}
+	return nil
+}
+
+func (x *AgentConfig) GetLogLevel() string {
+	if x != nil {
+		return x.LogLevel
+	}
+	return ""
+}
+
+// AgentUpdate 通知 Agent 升级到指定版本, 二进制通过 DownloadAgent 拉取
+type AgentUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Sha256        string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Signature     []byte                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"` // 发布时用任务签名私钥对 version / os / arch / sha256 / size 的签名
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentUpdate) Reset() {
+	*x = AgentUpdate{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentUpdate) ProtoMessage() {}
+
+func (x *AgentUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentUpdate.ProtoReflect.Descriptor instead.
+func (*AgentUpdate) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AgentUpdate) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AgentUpdate) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *AgentUpdate) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *AgentUpdate) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type DownloadAgentReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Arch          string                 `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadAgentReq) Reset() {
+	*x = DownloadAgentReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadAgentReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadAgentReq) ProtoMessage() {}
+
+func (x *DownloadAgentReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadAgentReq.ProtoReflect.Descriptor instead.
+func (*DownloadAgentReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DownloadAgentReq) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DownloadAgentReq) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *DownloadAgentReq) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+type AgentChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentChunk) Reset() {
+	*x = AgentChunk{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentChunk) ProtoMessage() {}
+
+func (x *AgentChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentChunk.ProtoReflect.Descriptor instead.
+func (*AgentChunk) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AgentChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{14}
+}
+
+type NameReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NameReq) Reset() {
+	*x = NameReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NameReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NameReq) ProtoMessage() {}
+
+func (x *NameReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NameReq.ProtoReflect.Descriptor instead.
+func (*NameReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *NameReq) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// ScopeTarget 按 scope / target 定位的一条配置, 例如 agent/web-1, tag/prod, user/alice
+type ScopeTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scope         string                 `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScopeTarget) Reset() {
+	*x = ScopeTarget{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScopeTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScopeTarget) ProtoMessage() {}
+
+func (x *ScopeTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScopeTarget.ProtoReflect.Descriptor instead.
+func (*ScopeTarget) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ScopeTarget) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *ScopeTarget) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+type Agent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Project       string                 `protobuf:"bytes,2,opt,name=project,proto3" json:"project,omitempty"`
+	Hostname      string                 `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ip            string                 `protobuf:"bytes,4,opt,name=ip,proto3" json:"ip,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Tags          []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	LastSeen      int64                  `protobuf:"varint,7,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Version       string                 `protobuf:"bytes,8,opt,name=version,proto3" json:"version,omitempty"`
+	Os            string                 `protobuf:"bytes,9,opt,name=os,proto3" json:"os,omitempty"`
+	Arch          string                 `protobuf:"bytes,10,opt,name=arch,proto3" json:"arch,omitempty"`
+	TargetVersion string                 `protobuf:"bytes,11,opt,name=target_version,json=targetVersion,proto3" json:"target_version,omitempty"`
+	UpdateError   string                 `protobuf:"bytes,12,opt,name=update_error,json=updateError,proto3" json:"update_error,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Agent) Reset() {
+	*x = Agent{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Agent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Agent) ProtoMessage() {}
+
+func (x *Agent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Agent.ProtoReflect.Descriptor instead.
+func (*Agent) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Agent) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Agent) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Agent) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *Agent) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Agent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Agent) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Agent) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+func (x *Agent) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Agent) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *Agent) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *Agent) GetTargetVersion() string {
+	if x != nil {
+		return x.TargetVersion
+	}
+	return ""
+}
+
+func (x *Agent) GetUpdateError() string {
+	if x != nil {
+		return x.UpdateError
+	}
+	return ""
+}
+
+func (x *Agent) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ListAgentsReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAgentsReq) Reset() {
+	*x = ListAgentsReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAgentsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAgentsReq) ProtoMessage() {}
+
+func (x *ListAgentsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAgentsReq.ProtoReflect.Descriptor instead.
+func (*ListAgentsReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{18}
+}
+
+type AgentList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agents        []*Agent               `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentList) Reset() {
+	*x = AgentList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentList) ProtoMessage() {}
+
+func (x *AgentList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentList.ProtoReflect.Descriptor instead.
+func (*AgentList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AgentList) GetAgents() []*Agent {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+type AgentReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentReq) Reset() {
+	*x = AgentReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentReq) ProtoMessage() {}
+
+func (x *AgentReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentReq.ProtoReflect.Descriptor instead.
+func (*AgentReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AgentReq) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type Selector struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	All           bool                   `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+	AgentIds      []string               `protobuf:"bytes,2,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Selector) Reset() {
+	*x = Selector{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Selector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Selector) ProtoMessage() {}
+
+func (x *Selector) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Selector.ProtoReflect.Descriptor instead.
+func (*Selector) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Selector) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *Selector) GetAgentIds() []string {
+	if x != nil {
+		return x.AgentIds
+	}
+	return nil
+}
+
+func (x *Selector) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Selector) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type AgentMetricsReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WindowSeconds int64                  `protobuf:"varint,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"` // 默认 1 小时, 最长 24 小时
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentMetricsReq) Reset() {
+	*x = AgentMetricsReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentMetricsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentMetricsReq) ProtoMessage() {}
+
+func (x *AgentMetricsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentMetricsReq.ProtoReflect.Descriptor instead.
+func (*AgentMetricsReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *AgentMetricsReq) GetWindowSeconds() int64 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+type MetricPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          int64                  `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Cpu           float64                `protobuf:"fixed64,2,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Mem           float64                `protobuf:"fixed64,3,opt,name=mem,proto3" json:"mem,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricPoint) Reset() {
+	*x = MetricPoint{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricPoint) ProtoMessage() {}
+
+func (x *MetricPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricPoint.ProtoReflect.Descriptor instead.
+func (*MetricPoint) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *MetricPoint) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *MetricPoint) GetCpu() float64 {
+	if x != nil {
+		return x.Cpu
+	}
+	return 0
+}
+
+func (x *MetricPoint) GetMem() float64 {
+	if x != nil {
+		return x.Mem
+	}
+	return 0
+}
+
+type MetricSeries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Points        []*MetricPoint         `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricSeries) Reset() {
+	*x = MetricSeries{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricSeries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricSeries) ProtoMessage() {}
+
+func (x *MetricSeries) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricSeries.ProtoReflect.Descriptor instead.
+func (*MetricSeries) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *MetricSeries) GetPoints() []*MetricPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+type AgentMetricsResp struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Agents        map[string]*MetricSeries `protobuf:"bytes,1,rep,name=agents,proto3" json:"agents,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // key 为 Agent ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentMetricsResp) Reset() {
+	*x = AgentMetricsResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentMetricsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentMetricsResp) ProtoMessage() {}
+
+func (x *AgentMetricsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentMetricsResp.ProtoReflect.Descriptor instead.
+func (*AgentMetricsResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AgentMetricsResp) GetAgents() map[string]*MetricSeries {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+type SubmitJobReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"` // 和 selector 二选一
+	Selector      *Selector              `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // PING / SCAN / SHELL, 为空默认 PING
+	Cmd           string                 `protobuf:"bytes,4,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	Secrets       map[string]string      `protobuf:"bytes,5,rep,name=secrets,proto3" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 环境变量名 -> 密钥名
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobReq) Reset() {
+	*x = SubmitJobReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobReq) ProtoMessage() {}
+
+func (x *SubmitJobReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobReq.ProtoReflect.Descriptor instead.
+func (*SubmitJobReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SubmitJobReq) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *SubmitJobReq) GetSelector() *Selector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *SubmitJobReq) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SubmitJobReq) GetCmd() string {
+	if x != nil {
+		return x.Cmd
+	}
+	return ""
+}
+
+func (x *SubmitJobReq) GetSecrets() map[string]string {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+type SubmitJobResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*JobInfo             `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"` // 按 Agent ID 排序, 只有 target 时只有一个
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobResp) Reset() {
+	*x = SubmitJobResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobResp) ProtoMessage() {}
+
+func (x *SubmitJobResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobResp.ProtoReflect.Descriptor instead.
+func (*SubmitJobResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SubmitJobResp) GetJobs() []*JobInfo {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type JobReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobReq) Reset() {
+	*x = JobReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobReq) ProtoMessage() {}
+
+func (x *JobReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobReq.ProtoReflect.Descriptor instead.
+func (*JobReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *JobReq) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type JobOutputReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	AfterSeq      int64                  `protobuf:"varint,2,opt,name=after_seq,json=afterSeq,proto3" json:"after_seq,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // 0 表示默认值
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobOutputReq) Reset() {
+	*x = JobOutputReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobOutputReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobOutputReq) ProtoMessage() {}
+
+func (x *JobOutputReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobOutputReq.ProtoReflect.Descriptor instead.
+func (*JobOutputReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *JobOutputReq) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobOutputReq) GetAfterSeq() int64 {
+	if x != nil {
+		return x.AfterSeq
+	}
+	return 0
+}
+
+func (x *JobOutputReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type JobOutputResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunks        []*JobOutputChunk      `protobuf:"bytes,1,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Finished      bool                   `protobuf:"varint,3,opt,name=finished,proto3" json:"finished,omitempty"` // 任务已经结束, 不会再有新的分片
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobOutputResp) Reset() {
+	*x = JobOutputResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobOutputResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobOutputResp) ProtoMessage() {}
+
+func (x *JobOutputResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobOutputResp.ProtoReflect.Descriptor instead.
+func (*JobOutputResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *JobOutputResp) GetChunks() []*JobOutputChunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *JobOutputResp) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *JobOutputResp) GetFinished() bool {
+	if x != nil {
+		return x.Finished
+	}
+	return false
+}
+
+type SecretRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Env           string                 `protobuf:"bytes,1,opt,name=env,proto3" json:"env,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecretRef) Reset() {
+	*x = SecretRef{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecretRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecretRef) ProtoMessage() {}
+
+func (x *SecretRef) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecretRef.ProtoReflect.Descriptor instead.
+func (*SecretRef) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SecretRef) GetEnv() string {
+	if x != nil {
+		return x.Env
+	}
+	return ""
+}
+
+func (x *SecretRef) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type JobInfo struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	JobId             string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	AgentId           string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Project           string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	Type              string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Status            string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Payload           string                 `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+	Result            *string                `protobuf:"bytes,7,opt,name=result,proto3,oneof" json:"result,omitempty"` // 只有 GetJob 返回
+	SubmittedBy       string                 `protobuf:"bytes,8,opt,name=submitted_by,json=submittedBy,proto3" json:"submitted_by,omitempty"`
+	Secrets           []*SecretRef           `protobuf:"bytes,9,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	ApprovalRule      string                 `protobuf:"bytes,10,opt,name=approval_rule,json=approvalRule,proto3" json:"approval_rule,omitempty"`
+	ApprovalExpiresAt int64                  `protobuf:"varint,11,opt,name=approval_expires_at,json=approvalExpiresAt,proto3" json:"approval_expires_at,omitempty"`
+	ApprovedBy        string                 `protobuf:"bytes,12,opt,name=approved_by,json=approvedBy,proto3" json:"approved_by,omitempty"`
+	ApprovedAt        int64                  `protobuf:"varint,13,opt,name=approved_at,json=approvedAt,proto3" json:"approved_at,omitempty"`
+	CreatedAt         int64                  `protobuf:"varint,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt         int64                  `protobuf:"varint,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DispatchedAt      int64                  `protobuf:"varint,16,opt,name=dispatched_at,json=dispatchedAt,proto3" json:"dispatched_at,omitempty"`
+	ExecutedAt        int64                  `protobuf:"varint,17,opt,name=executed_at,json=executedAt,proto3" json:"executed_at,omitempty"`
+	ExpiresAt         int64                  `protobuf:"varint,18,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *JobInfo) Reset() {
+	*x = JobInfo{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobInfo) ProtoMessage() {}
+
+func (x *JobInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobInfo.ProtoReflect.Descriptor instead.
+func (*JobInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *JobInfo) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobInfo) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *JobInfo) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *JobInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *JobInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *JobInfo) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *JobInfo) GetResult() string {
+	if x != nil && x.Result != nil {
+		return *x.Result
+	}
+	return ""
+}
+
+func (x *JobInfo) GetSubmittedBy() string {
+	if x != nil {
+		return x.SubmittedBy
+	}
+	return ""
+}
+
+func (x *JobInfo) GetSecrets() []*SecretRef {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *JobInfo) GetApprovalRule() string {
+	if x != nil {
+		return x.ApprovalRule
+	}
+	return ""
+}
+
+func (x *JobInfo) GetApprovalExpiresAt() int64 {
+	if x != nil {
+		return x.ApprovalExpiresAt
+	}
+	return 0
+}
+
+func (x *JobInfo) GetApprovedBy() string {
+	if x != nil {
+		return x.ApprovedBy
+	}
+	return ""
+}
+
+func (x *JobInfo) GetApprovedAt() int64 {
+	if x != nil {
+		return x.ApprovedAt
+	}
+	return 0
+}
+
+func (x *JobInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *JobInfo) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *JobInfo) GetDispatchedAt() int64 {
+	if x != nil {
+		return x.DispatchedAt
+	}
+	return 0
+}
+
+func (x *JobInfo) GetExecutedAt() int64 {
+	if x != nil {
+		return x.ExecutedAt
+	}
+	return 0
+}
+
+func (x *JobInfo) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type ListJobsReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Status        []string               `protobuf:"bytes,2,rep,name=status,proto3" json:"status,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	SubmittedBy   string                 `protobuf:"bytes,4,opt,name=submitted_by,json=submittedBy,proto3" json:"submitted_by,omitempty"`
+	Since         int64                  `protobuf:"varint,5,opt,name=since,proto3" json:"since,omitempty"`
+	Until         int64                  `protobuf:"varint,6,opt,name=until,proto3" json:"until,omitempty"`
+	Text          string                 `protobuf:"bytes,7,opt,name=text,proto3" json:"text,omitempty"` // payload 和输出里不区分大小写的子串
+	Sort          string                 `protobuf:"bytes,8,opt,name=sort,proto3" json:"sort,omitempty"` // created_at / updated_at / executed_at, 前缀 "-" 表示降序
+	Cursor        string                 `protobuf:"bytes,9,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsReq) Reset() {
+	*x = ListJobsReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsReq) ProtoMessage() {}
+
+func (x *ListJobsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsReq.ProtoReflect.Descriptor instead.
+func (*ListJobsReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListJobsReq) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ListJobsReq) GetStatus() []string {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *ListJobsReq) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListJobsReq) GetSubmittedBy() string {
+	if x != nil {
+		return x.SubmittedBy
+	}
+	return ""
+}
+
+func (x *ListJobsReq) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *ListJobsReq) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *ListJobsReq) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ListJobsReq) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListJobsReq) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListJobsReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListJobsResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*JobInfo             `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // 为空表示没有下一页
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResp) Reset() {
+	*x = ListJobsResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResp) ProtoMessage() {}
+
+func (x *ListJobsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResp.ProtoReflect.Descriptor instead.
+func (*ListJobsResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListJobsResp) GetJobs() []*JobInfo {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *ListJobsResp) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type SearchJobsReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	Regex         string                 `protobuf:"bytes,2,opt,name=regex,proto3" json:"regex,omitempty"`
+	AgentId       string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Since         int64                  `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until         int64                  `protobuf:"varint,5,opt,name=until,proto3" json:"until,omitempty"`
+	Cursor        string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchJobsReq) Reset() {
+	*x = SearchJobsReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchJobsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchJobsReq) ProtoMessage() {}
+
+func (x *SearchJobsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchJobsReq.ProtoReflect.Descriptor instead.
+func (*SearchJobsReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SearchJobsReq) GetPhrase() string {
+	if x != nil {
+		return x.Phrase
+	}
+	return ""
+}
+
+func (x *SearchJobsReq) GetRegex() string {
+	if x != nil {
+		return x.Regex
+	}
+	return ""
+}
+
+func (x *SearchJobsReq) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SearchJobsReq) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *SearchJobsReq) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *SearchJobsReq) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *SearchJobsReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SnippetPart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Match         bool                   `protobuf:"varint,2,opt,name=match,proto3" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnippetPart) Reset() {
+	*x = SnippetPart{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnippetPart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnippetPart) ProtoMessage() {}
+
+func (x *SnippetPart) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnippetPart.ProtoReflect.Descriptor instead.
+func (*SnippetPart) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SnippetPart) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SnippetPart) GetMatch() bool {
+	if x != nil {
+		return x.Match
+	}
+	return false
+}
+
+type Snippet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Line          int32                  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Parts         []*SnippetPart         `protobuf:"bytes,2,rep,name=parts,proto3" json:"parts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snippet) Reset() {
+	*x = Snippet{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snippet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snippet) ProtoMessage() {}
+
+func (x *Snippet) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snippet.ProtoReflect.Descriptor instead.
+func (*Snippet) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *Snippet) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Snippet) GetParts() []*SnippetPart {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type SearchHit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *JobInfo               `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	Matches       int32                  `protobuf:"varint,2,opt,name=matches,proto3" json:"matches,omitempty"`
+	Snippets      []*Snippet             `protobuf:"bytes,3,rep,name=snippets,proto3" json:"snippets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SearchHit) GetJob() *JobInfo {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *SearchHit) GetMatches() int32 {
+	if x != nil {
+		return x.Matches
+	}
+	return 0
+}
+
+func (x *SearchHit) GetSnippets() []*Snippet {
+	if x != nil {
+		return x.Snippets
+	}
+	return nil
+}
+
+type SearchJobsResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          []*SearchHit           `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchJobsResp) Reset() {
+	*x = SearchJobsResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchJobsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchJobsResp) ProtoMessage() {}
+
+func (x *SearchJobsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchJobsResp.ProtoReflect.Descriptor instead.
+func (*SearchJobsResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SearchJobsResp) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+func (x *SearchJobsResp) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type AgentConfigRule struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Scope                    string                 `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"` // agent 或 tag
+	Target                   string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	HeartbeatIntervalSeconds int32                  `protobuf:"varint,3,opt,name=heartbeat_interval_seconds,json=heartbeatIntervalSeconds,proto3" json:"heartbeat_interval_seconds,omitempty"`
+	WorkerPoolSize           int32                  `protobuf:"varint,4,opt,name=worker_pool_size,json=workerPoolSize,proto3" json:"worker_pool_size,omitempty"`
+	AllowedJobTypes          string                 `protobuf:"bytes,5,opt,name=allowed_job_types,json=allowedJobTypes,proto3" json:"allowed_job_types,omitempty"` // 逗号分隔, 例如 "PING,SCAN"
+	LogLevel                 string                 `protobuf:"bytes,6,opt,name=log_level,json=logLevel,proto3" json:"log_level,omitempty"`
+	UpdatedAt                int64                  `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *AgentConfigRule) Reset() {
+	*x = AgentConfigRule{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConfigRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConfigRule) ProtoMessage() {}
+
+func (x *AgentConfigRule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConfigRule.ProtoReflect.Descriptor instead.
+func (*AgentConfigRule) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *AgentConfigRule) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *AgentConfigRule) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AgentConfigRule) GetHeartbeatIntervalSeconds() int32 {
+	if x != nil {
+		return x.HeartbeatIntervalSeconds
+	}
+	return 0
+}
+
+func (x *AgentConfigRule) GetWorkerPoolSize() int32 {
+	if x != nil {
+		return x.WorkerPoolSize
+	}
+	return 0
+}
+
+func (x *AgentConfigRule) GetAllowedJobTypes() string {
+	if x != nil {
+		return x.AllowedJobTypes
+	}
+	return ""
+}
+
+func (x *AgentConfigRule) GetLogLevel() string {
+	if x != nil {
+		return x.LogLevel
+	}
+	return ""
+}
+
+func (x *AgentConfigRule) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type AgentConfigRuleList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*AgentConfigRule     `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentConfigRuleList) Reset() {
+	*x = AgentConfigRuleList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConfigRuleList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConfigRuleList) ProtoMessage() {}
+
+func (x *AgentConfigRuleList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConfigRuleList.ProtoReflect.Descriptor instead.
+func (*AgentConfigRuleList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *AgentConfigRuleList) GetRules() []*AgentConfigRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type AgentRelease struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Arch          string                 `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
+	Sha256        string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentRelease) Reset() {
+	*x = AgentRelease{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentRelease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentRelease) ProtoMessage() {}
+
+func (x *AgentRelease) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentRelease.ProtoReflect.Descriptor instead.
+func (*AgentRelease) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *AgentRelease) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *AgentRelease) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *AgentRelease) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type AgentReleaseList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Releases      []*AgentRelease        `protobuf:"bytes,1,rep,name=releases,proto3" json:"releases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentReleaseList) Reset() {
+	*x = AgentReleaseList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentReleaseList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentReleaseList) ProtoMessage() {}
+
+func (x *AgentReleaseList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentReleaseList.ProtoReflect.Descriptor instead.
+func (*AgentReleaseList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AgentReleaseList) GetReleases() []*AgentRelease {
+	if x != nil {
+		return x.Releases
+	}
+	return nil
+}
+
+type UploadAgentReleaseReq struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Part:
+	//
+	//	*UploadAgentReleaseReq_Meta
+	//	*UploadAgentReleaseReq_Data
+	Part          isUploadAgentReleaseReq_Part `protobuf_oneof:"part"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadAgentReleaseReq) Reset() {
+	*x = UploadAgentReleaseReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAgentReleaseReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAgentReleaseReq) ProtoMessage() {}
+
+func (x *UploadAgentReleaseReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadAgentReleaseReq.ProtoReflect.Descriptor instead.
+func (*UploadAgentReleaseReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UploadAgentReleaseReq) GetPart() isUploadAgentReleaseReq_Part {
+	if x != nil {
+		return x.Part
+	}
+	return nil
+}
+
+func (x *UploadAgentReleaseReq) GetMeta() *AgentRelease {
+	if x != nil {
+		if x, ok := x.Part.(*UploadAgentReleaseReq_Meta); ok {
+			return x.Meta
+		}
+	}
+	return nil
+}
+
+func (x *UploadAgentReleaseReq) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Part.(*UploadAgentReleaseReq_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+type isUploadAgentReleaseReq_Part interface {
+	isUploadAgentReleaseReq_Part()
+}
+
+type UploadAgentReleaseReq_Meta struct {
+	Meta *AgentRelease `protobuf:"bytes,1,opt,name=meta,proto3,oneof"` // 只用 version / os / arch
+}
+
+type UploadAgentReleaseReq_Data struct {
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*UploadAgentReleaseReq_Meta) isUploadAgentReleaseReq_Part() {}
+
+func (*UploadAgentReleaseReq_Data) isUploadAgentReleaseReq_Part() {}
+
+type RolloutReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Selector      *Selector              `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolloutReq) Reset() {
+	*x = RolloutReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolloutReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolloutReq) ProtoMessage() {}
+
+func (x *RolloutReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolloutReq.ProtoReflect.Descriptor instead.
+func (*RolloutReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *RolloutReq) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RolloutReq) GetSelector() *Selector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+type RolloutSkip struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolloutSkip) Reset() {
+	*x = RolloutSkip{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolloutSkip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolloutSkip) ProtoMessage() {}
+
+func (x *RolloutSkip) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolloutSkip.ProtoReflect.Descriptor instead.
+func (*RolloutSkip) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *RolloutSkip) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RolloutSkip) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RolloutResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Targeted      []string               `protobuf:"bytes,2,rep,name=targeted,proto3" json:"targeted,omitempty"`
+	Skipped       []*RolloutSkip         `protobuf:"bytes,3,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolloutResp) Reset() {
+	*x = RolloutResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolloutResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolloutResp) ProtoMessage() {}
+
+func (x *RolloutResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolloutResp.ProtoReflect.Descriptor instead.
+func (*RolloutResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *RolloutResp) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RolloutResp) GetTargeted() []string {
+	if x != nil {
+		return x.Targeted
+	}
+	return nil
+}
+
+func (x *RolloutResp) GetSkipped() []*RolloutSkip {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type ApprovalRule struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	JobType        string                 `protobuf:"bytes,2,opt,name=job_type,json=jobType,proto3" json:"job_type,omitempty"`
+	Selector       *Selector              `protobuf:"bytes,3,opt,name=selector,proto3" json:"selector,omitempty"` // 不支持 status
+	PayloadPattern string                 `protobuf:"bytes,4,opt,name=payload_pattern,json=payloadPattern,proto3" json:"payload_pattern,omitempty"`
+	TtlSeconds     int32                  `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ApprovalRule) Reset() {
+	*x = ApprovalRule{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalRule) ProtoMessage() {}
+
+func (x *ApprovalRule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalRule.ProtoReflect.Descriptor instead.
+func (*ApprovalRule) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ApprovalRule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ApprovalRule) GetJobType() string {
+	if x != nil {
+		return x.JobType
+	}
+	return ""
+}
+
+func (x *ApprovalRule) GetSelector() *Selector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *ApprovalRule) GetPayloadPattern() string {
+	if x != nil {
+		return x.PayloadPattern
+	}
+	return ""
+}
+
+func (x *ApprovalRule) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type ApprovalRuleList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*ApprovalRule        `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApprovalRuleList) Reset() {
+	*x = ApprovalRuleList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalRuleList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalRuleList) ProtoMessage() {}
+
+func (x *ApprovalRuleList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalRuleList.ProtoReflect.Descriptor instead.
+func (*ApprovalRuleList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ApprovalRuleList) GetRules() []*ApprovalRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type SecretInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Project       string                 `protobuf:"bytes,3,opt,name=project,proto3" json:"project,omitempty"`
+	Agents        *Selector              `protobuf:"bytes,4,opt,name=agents,proto3" json:"agents,omitempty"`
+	AllowedUsers  []string               `protobuf:"bytes,5,rep,name=allowed_users,json=allowedUsers,proto3" json:"allowed_users,omitempty"`
+	UpdatedBy     string                 `protobuf:"bytes,6,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecretInfo) Reset() {
+	*x = SecretInfo{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecretInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecretInfo) ProtoMessage() {}
+
+func (x *SecretInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecretInfo.ProtoReflect.Descriptor instead.
+func (*SecretInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *SecretInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SecretInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *SecretInfo) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *SecretInfo) GetAgents() *Selector {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *SecretInfo) GetAllowedUsers() []string {
+	if x != nil {
+		return x.AllowedUsers
+	}
+	return nil
+}
+
+func (x *SecretInfo) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *SecretInfo) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type SecretList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Secrets       []*SecretInfo          `protobuf:"bytes,1,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecretList) Reset() {
+	*x = SecretList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecretList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecretList) ProtoMessage() {}
+
+func (x *SecretList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecretList.ProtoReflect.Descriptor instead.
+func (*SecretList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *SecretList) GetSecrets() []*SecretInfo {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+type PutSecretReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Secret        *SecretInfo            `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"` // updated_by / updated_at 忽略
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutSecretReq) Reset() {
+	*x = PutSecretReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutSecretReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutSecretReq) ProtoMessage() {}
+
+func (x *PutSecretReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutSecretReq.ProtoReflect.Descriptor instead.
+func (*PutSecretReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *PutSecretReq) GetSecret() *SecretInfo {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+func (x *PutSecretReq) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type UserList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserList) Reset() {
+	*x = UserList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserList) ProtoMessage() {}
+
+func (x *UserList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserList.ProtoReflect.Descriptor instead.
+func (*UserList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *UserList) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type CreateUserReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserReq) Reset() {
+	*x = CreateUserReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserReq) ProtoMessage() {}
+
+func (x *CreateUserReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserReq.ProtoReflect.Descriptor instead.
+func (*CreateUserReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *CreateUserReq) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateUserReq) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type CreateUserResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserResp) Reset() {
+	*x = CreateUserResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserResp) ProtoMessage() {}
+
+func (x *CreateUserResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserResp.ProtoReflect.Descriptor instead.
+func (*CreateUserResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *CreateUserResp) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *CreateUserResp) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type Project struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Project) Reset() {
+	*x = Project{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Project) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Project) ProtoMessage() {}
+
+func (x *Project) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Project.ProtoReflect.Descriptor instead.
+func (*Project) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *Project) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Project) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Project) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ProjectList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Projects      []*Project             `protobuf:"bytes,1,rep,name=projects,proto3" json:"projects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProjectList) Reset() {
+	*x = ProjectList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProjectList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProjectList) ProtoMessage() {}
+
+func (x *ProjectList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProjectList.ProtoReflect.Descriptor instead.
+func (*ProjectList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ProjectList) GetProjects() []*Project {
+	if x != nil {
+		return x.Projects
+	}
+	return nil
+}
+
+type ProjectReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProjectReq) Reset() {
+	*x = ProjectReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProjectReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProjectReq) ProtoMessage() {}
+
+func (x *ProjectReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProjectReq.ProtoReflect.Descriptor instead.
+func (*ProjectReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *ProjectReq) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+type CreateProjectReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProjectReq) Reset() {
+	*x = CreateProjectReq{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProjectReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProjectReq) ProtoMessage() {}
+
+func (x *CreateProjectReq) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProjectReq.ProtoReflect.Descriptor instead.
+func (*CreateProjectReq) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *CreateProjectReq) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateProjectReq) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type CreateProjectResp struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Project         *Project               `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	EnrollmentToken string                 `protobuf:"bytes,2,opt,name=enrollment_token,json=enrollmentToken,proto3" json:"enrollment_token,omitempty"` // 只在创建和轮换时返回
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateProjectResp) Reset() {
+	*x = CreateProjectResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProjectResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProjectResp) ProtoMessage() {}
+
+func (x *CreateProjectResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProjectResp.ProtoReflect.Descriptor instead.
+func (*CreateProjectResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *CreateProjectResp) GetProject() *Project {
+	if x != nil {
+		return x.Project
+	}
+	return nil
+}
+
+func (x *CreateProjectResp) GetEnrollmentToken() string {
+	if x != nil {
+		return x.EnrollmentToken
+	}
+	return ""
+}
+
+type EnrollmentToken struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EnrollmentToken string                 `protobuf:"bytes,1,opt,name=enrollment_token,json=enrollmentToken,proto3" json:"enrollment_token,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *EnrollmentToken) Reset() {
+	*x = EnrollmentToken{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrollmentToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrollmentToken) ProtoMessage() {}
+
+func (x *EnrollmentToken) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrollmentToken.ProtoReflect.Descriptor instead.
+func (*EnrollmentToken) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *EnrollmentToken) GetEnrollmentToken() string {
+	if x != nil {
+		return x.EnrollmentToken
+	}
+	return ""
+}
+
+type Member struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       string                 `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"` // DeleteMember 不用
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Member) Reset() {
+	*x = Member{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Member) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Member) ProtoMessage() {}
+
+func (x *Member) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Member.ProtoReflect.Descriptor instead.
+func (*Member) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *Member) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Member) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Member) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type MemberList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*Member              `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemberList) Reset() {
+	*x = MemberList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemberList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberList) ProtoMessage() {}
+
+func (x *MemberList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberList.ProtoReflect.Descriptor instead.
+func (*MemberList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *MemberList) GetMembers() []*Member {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type QuotaLimits struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	JobsPerMinute     int32                  `protobuf:"varint,1,opt,name=jobs_per_minute,json=jobsPerMinute,proto3" json:"jobs_per_minute,omitempty"`
+	MaxConcurrentJobs int32                  `protobuf:"varint,2,opt,name=max_concurrent_jobs,json=maxConcurrentJobs,proto3" json:"max_concurrent_jobs,omitempty"`
+	MaxFanOut         int32                  `protobuf:"varint,3,opt,name=max_fan_out,json=maxFanOut,proto3" json:"max_fan_out,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *QuotaLimits) Reset() {
+	*x = QuotaLimits{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaLimits) ProtoMessage() {}
+
+func (x *QuotaLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaLimits.ProtoReflect.Descriptor instead.
+func (*QuotaLimits) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *QuotaLimits) GetJobsPerMinute() int32 {
+	if x != nil {
+		return x.JobsPerMinute
+	}
+	return 0
+}
+
+func (x *QuotaLimits) GetMaxConcurrentJobs() int32 {
+	if x != nil {
+		return x.MaxConcurrentJobs
+	}
+	return 0
+}
+
+func (x *QuotaLimits) GetMaxFanOut() int32 {
+	if x != nil {
+		return x.MaxFanOut
+	}
+	return 0
+}
+
+type Quota struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scope         string                 `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"` // user 或 project
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Limits        *QuotaLimits           `protobuf:"bytes,3,opt,name=limits,proto3" json:"limits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quota) Reset() {
+	*x = Quota{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quota) ProtoMessage() {}
+
+func (x *Quota) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quota.ProtoReflect.Descriptor instead.
+func (*Quota) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *Quota) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *Quota) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Quota) GetLimits() *QuotaLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+type QuotaList struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Quotas         []*Quota               `protobuf:"bytes,1,rep,name=quotas,proto3" json:"quotas,omitempty"`
+	DefaultUser    *QuotaLimits           `protobuf:"bytes,2,opt,name=default_user,json=defaultUser,proto3" json:"default_user,omitempty"`
+	DefaultProject *QuotaLimits           `protobuf:"bytes,3,opt,name=default_project,json=defaultProject,proto3" json:"default_project,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *QuotaList) Reset() {
+	*x = QuotaList{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaList) ProtoMessage() {}
+
+func (x *QuotaList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaList.ProtoReflect.Descriptor instead.
+func (*QuotaList) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *QuotaList) GetQuotas() []*Quota {
+	if x != nil {
+		return x.Quotas
+	}
+	return nil
+}
+
+func (x *QuotaList) GetDefaultUser() *QuotaLimits {
+	if x != nil {
+		return x.DefaultUser
+	}
+	return nil
+}
+
+func (x *QuotaList) GetDefaultProject() *QuotaLimits {
+	if x != nil {
+		return x.DefaultProject
+	}
+	return nil
+}
+
+type QuotaUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Scope          string                 `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	Target         string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Limits         *QuotaLimits           `protobuf:"bytes,3,opt,name=limits,proto3" json:"limits,omitempty"`
+	JobsLastMinute int32                  `protobuf:"varint,4,opt,name=jobs_last_minute,json=jobsLastMinute,proto3" json:"jobs_last_minute,omitempty"`
+	UnfinishedJobs int32                  `protobuf:"varint,5,opt,name=unfinished_jobs,json=unfinishedJobs,proto3" json:"unfinished_jobs,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *QuotaUsage) Reset() {
+	*x = QuotaUsage{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaUsage) ProtoMessage() {}
+
+func (x *QuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaUsage.ProtoReflect.Descriptor instead.
+func (*QuotaUsage) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *QuotaUsage) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *QuotaUsage) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *QuotaUsage) GetLimits() *QuotaLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *QuotaUsage) GetJobsLastMinute() int32 {
+	if x != nil {
+		return x.JobsLastMinute
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetUnfinishedJobs() int32 {
+	if x != nil {
+		return x.UnfinishedJobs
+	}
+	return 0
+}
+
+type QuotaUsageResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *QuotaUsage            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Project       *QuotaUsage            `protobuf:"bytes,2,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaUsageResp) Reset() {
+	*x = QuotaUsageResp{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaUsageResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaUsageResp) ProtoMessage() {}
+
+func (x *QuotaUsageResp) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaUsageResp.ProtoReflect.Descriptor instead.
+func (*QuotaUsageResp) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *QuotaUsageResp) GetUser() *QuotaUsage {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *QuotaUsageResp) GetProject() *QuotaUsage {
+	if x != nil {
+		return x.Project
+	}
+	return nil
+}
+
+type AuditFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Actor         string                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Target        string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	Since         int64                  `protobuf:"varint,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until         int64                  `protobuf:"varint,5,opt,name=until,proto3" json:"until,omitempty"`
+	AfterSeq      uint64                 `protobuf:"varint,6,opt,name=after_seq,json=afterSeq,proto3" json:"after_seq,omitempty"`
+	Limit         int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"` // 默认 100, 最多 1000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditFilter) Reset() {
+	*x = AuditFilter{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditFilter) ProtoMessage() {}
+
+func (x *AuditFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditFilter.ProtoReflect.Descriptor instead.
+func (*AuditFilter) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *AuditFilter) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditFilter) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditFilter) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AuditFilter) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *AuditFilter) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *AuditFilter) GetAfterSeq() uint64 {
+	if x != nil {
+		return x.AfterSeq
+	}
+	return 0
+}
+
+func (x *AuditFilter) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AuditEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	TimeMs        int64                  `protobuf:"varint,2,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"` // unix 毫秒, 哈希按毫秒计算
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	SourceIp      string                 `protobuf:"bytes,4,opt,name=source_ip,json=sourceIp,proto3" json:"source_ip,omitempty"`
+	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`
+	Target        string                 `protobuf:"bytes,6,opt,name=target,proto3" json:"target,omitempty"`
+	PayloadHash   string                 `protobuf:"bytes,7,opt,name=payload_hash,json=payloadHash,proto3" json:"payload_hash,omitempty"`
+	Result        string                 `protobuf:"bytes,8,opt,name=result,proto3" json:"result,omitempty"`
+	PrevHash      string                 `protobuf:"bytes,9,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+	Hash          string                 `protobuf:"bytes,10,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *AuditEntry) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetTimeMs() int64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetSourceIp() string {
+	if x != nil {
+		return x.SourceIp
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetPayloadHash() string {
+	if x != nil {
+		return x.PayloadHash
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetPrevHash() string {
+	if x != nil {
+		return x.PrevHash
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type AuditPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*AuditEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	NextAfterSeq  uint64                 `protobuf:"varint,2,opt,name=next_after_seq,json=nextAfterSeq,proto3" json:"next_after_seq,omitempty"` // 为 0 表示没有下一页
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditPage) Reset() {
+	*x = AuditPage{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditPage) ProtoMessage() {}
+
+func (x *AuditPage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditPage.ProtoReflect.Descriptor instead.
+func (*AuditPage) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *AuditPage) GetEntries() []*AuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *AuditPage) GetNextAfterSeq() uint64 {
+	if x != nil {
+		return x.NextAfterSeq
+	}
+	return 0
+}
+
+type AuditVerifyResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Valid         bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Entries       uint64                 `protobuf:"varint,2,opt,name=entries,proto3" json:"entries,omitempty"`
+	HeadSeq       uint64                 `protobuf:"varint,3,opt,name=head_seq,json=headSeq,proto3" json:"head_seq,omitempty"`
+	HeadHash      string                 `protobuf:"bytes,4,opt,name=head_hash,json=headHash,proto3" json:"head_hash,omitempty"`
+	BrokenAt      uint64                 `protobuf:"varint,5,opt,name=broken_at,json=brokenAt,proto3" json:"broken_at,omitempty"`
+	Reason        string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditVerifyResult) Reset() {
+	*x = AuditVerifyResult{}
+	mi := &file_api_proto_sentinel_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditVerifyResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditVerifyResult) ProtoMessage() {}
+
+func (x *AuditVerifyResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_sentinel_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditVerifyResult.ProtoReflect.Descriptor instead.
+func (*AuditVerifyResult) Descriptor() ([]byte, []int) {
+	return file_api_proto_sentinel_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *AuditVerifyResult) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *AuditVerifyResult) GetEntries() uint64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *AuditVerifyResult) GetHeadSeq() uint64 {
+	if x != nil {
+		return x.HeadSeq
+	}
+	return 0
+}
+
+func (x *AuditVerifyResult) GetHeadHash() string {
+	if x != nil {
+		return x.HeadHash
+	}
+	return ""
+}
+
+func (x *AuditVerifyResult) GetBrokenAt() uint64 {
+	if x != nil {
+		return x.BrokenAt
+	}
+	return 0
+}
+
+func (x *AuditVerifyResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_api_proto_sentinel_proto protoreflect.FileDescriptor
+
+const file_api_proto_sentinel_proto_rawDesc = "" +
+	"\n" +
+	"\x18api/proto/sentinel.proto\x12\bsentinel\"\xd9\x01\n" +
+	"\vRegisterReq\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x0e\n" +
+	"\x02os\x18\x05 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x06 \x01(\tR\x04arch\x12!\n" +
+	"\fupdate_error\x18\a \x01(\tR\vupdateError\x12)\n" +
+	"\x10enrollment_token\x18\b \x01(\tR\x0fenrollmentToken\"n\n" +
+	"\fRegisterResp\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12)\n" +
+	"\x10agent_credential\x18\x03 \x01(\tR\x0fagentCredential\"\xa8\x01\n" +
+	"\fHeartbeatReq\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x1b\n" +
+	"\tcpu_usage\x18\x03 \x01(\x01R\bcpuUsage\x12\x1b\n" +
+	"\tmem_usage\x18\x04 \x01(\x01R\bmemUsage\x12%\n" +
+	"\x0econfig_version\x18\x05 \x01(\tR\rconfigVersion\"\xe4\x01\n" +
+	"\x03Job\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12%\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x11.sentinel.JobTypeR\x04type\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\tR\apayload\x12\x19\n" +
+	"\bagent_id\x18\x04 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\fR\tsignature\x12-\n" +
+	"\asecrets\x18\a \x03(\v2\x13.sentinel.SecretEnvR\asecrets\"G\n" +
+	"\tSecretEnv\x12\x10\n" +
+	"\x03env\x18\x01 \x01(\tR\x03env\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"p\n" +
+	"\fReportJobReq\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x16\n" +
+	"\x06result\x18\x04 \x01(\tR\x06result\"+\n" +
+	"\rReportJobResp\x12\x1a\n" +
+	"\breceived\x18\x01 \x01(\bR\breceived\"h\n" +
+	"\x0eJobOutputChunk\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\tR\x04data\"\xae\x01\n" +
+	"\rHeartbeatResp\x12'\n" +
+	"\x0fconfig_outdated\x18\x01 \x01(\bR\x0econfigOutdated\x12\x1f\n" +
+	"\x03job\x18\x02 \x01(\v2\r.sentinel.JobR\x03job\x12$\n" +
+	"\x0ecancel_job_ids\x18\x03 \x03(\tR\fcancelJobIds\x12-\n" +
+	"\x06update\x18\x04 \x01(\v2\x15.sentinel.AgentUpdateR\x06update\".\n" +
+	"\x11GetAgentConfigReq\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xeb\x01\n" +
+	"\vAgentConfig\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12<\n" +
+	"\x1aheartbeat_interval_seconds\x18\x02 \x01(\x05R\x18heartbeatIntervalSeconds\x12(\n" +
+	"\x10worker_pool_size\x18\x03 \x01(\x05R\x0eworkerPoolSize\x12=\n" +
+	"\x11allowed_job_types\x18\x04 \x03(\x0e2\x11.sentinel.JobTypeR\x0fallowedJobTypes\x12\x1b\n" +
+	"\tlog_level\x18\x05 \x01(\tR\blogLevel\"q\n" +
+	"\vAgentUpdate\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\fR\tsignature\"P\n" +
+	"\x10DownloadAgentReq\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x0e\n" +
+	"\x02os\x18\x02 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x03 \x01(\tR\x04arch\" \n" +
+	"\n" +
+	"AgentChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\a\n" +
+	"\x05Empty\"\x1d\n" +
+	"\aNameReq\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\";\n" +
+	"\vScopeTarget\x12\x14\n" +
+	"\x05scope\x18\x01 \x01(\tR\x05scope\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\"\xd8\x02\n" +
+	"\x05Agent\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\aproject\x18\x02 \x01(\tR\aproject\x12\x1a\n" +
+	"\bhostname\x18\x03 \x01(\tR\bhostname\x12\x0e\n" +
+	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12\x1b\n" +
+	"\tlast_seen\x18\a \x01(\x03R\blastSeen\x12\x18\n" +
+	"\aversion\x18\b \x01(\tR\aversion\x12\x0e\n" +
+	"\x02os\x18\t \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\n" +
+	" \x01(\tR\x04arch\x12%\n" +
+	"\x0etarget_version\x18\v \x01(\tR\rtargetVersion\x12!\n" +
+	"\fupdate_error\x18\f \x01(\tR\vupdateError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\r \x01(\x03R\tcreatedAt\"\x0f\n" +
+	"\rListAgentsReq\"4\n" +
+	"\tAgentList\x12'\n" +
+	"\x06agents\x18\x01 \x03(\v2\x0f.sentinel.AgentR\x06agents\"%\n" +
+	"\bAgentReq\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"e\n" +
+	"\bSelector\x12\x10\n" +
+	"\x03all\x18\x01 \x01(\bR\x03all\x12\x1b\n" +
+	"\tagent_ids\x18\x02 \x03(\tR\bagentIds\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\"8\n" +
+	"\x0fAgentMetricsReq\x12%\n" +
+	"\x0ewindow_seconds\x18\x01 \x01(\x03R\rwindowSeconds\"E\n" +
+	"\vMetricPoint\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x10\n" +
+	"\x03cpu\x18\x02 \x01(\x01R\x03cpu\x12\x10\n" +
+	"\x03mem\x18\x03 \x01(\x01R\x03mem\"=\n" +
+	"\fMetricSeries\x12-\n" +
+	"\x06points\x18\x01 \x03(\v2\x15.sentinel.MetricPointR\x06points\"\xa5\x01\n" +
+	"\x10AgentMetricsResp\x12>\n" +
+	"\x06agents\x18\x01 \x03(\v2&.sentinel.AgentMetricsResp.AgentsEntryR\x06agents\x1aQ\n" +
+	"\vAgentsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.sentinel.MetricSeriesR\x05value:\x028\x01\"\xf7\x01\n" +
+	"\fSubmitJobReq\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12.\n" +
+	"\bselector\x18\x02 \x01(\v2\x12.sentinel.SelectorR\bselector\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x10\n" +
+	"\x03cmd\x18\x04 \x01(\tR\x03cmd\x12=\n" +
+	"\asecrets\x18\x05 \x03(\v2#.sentinel.SubmitJobReq.SecretsEntryR\asecrets\x1a:\n" +
+	"\fSecretsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"6\n" +
+	"\rSubmitJobResp\x12%\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x11.sentinel.JobInfoR\x04jobs\"\x1f\n" +
+	"\x06JobReq\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"X\n" +
+	"\fJobOutputReq\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x1b\n" +
+	"\tafter_seq\x18\x02 \x01(\x03R\bafterSeq\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"u\n" +
+	"\rJobOutputResp\x120\n" +
+	"\x06chunks\x18\x01 \x03(\v2\x18.sentinel.JobOutputChunkR\x06chunks\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
+	"\bfinished\x18\x03 \x01(\bR\bfinished\"1\n" +
+	"\tSecretRef\x12\x10\n" +
+	"\x03env\x18\x01 \x01(\tR\x03env\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xcf\x04\n" +
+	"\aJobInfo\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x18\n" +
+	"\apayload\x18\x06 \x01(\tR\apayload\x12\x1b\n" +
+	"\x06result\x18\a \x01(\tH\x00R\x06result\x88\x01\x01\x12!\n" +
+	"\fsubmitted_by\x18\b \x01(\tR\vsubmittedBy\x12-\n" +
+	"\asecrets\x18\t \x03(\v2\x13.sentinel.SecretRefR\asecrets\x12#\n" +
+	"\rapproval_rule\x18\n" +
+	" \x01(\tR\fapprovalRule\x12.\n" +
+	"\x13approval_expires_at\x18\v \x01(\x03R\x11approvalExpiresAt\x12\x1f\n" +
+	"\vapproved_by\x18\f \x01(\tR\n" +
+	"approvedBy\x12\x1f\n" +
+	"\vapproved_at\x18\r \x01(\x03R\n" +
+	"approvedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x0f \x01(\x03R\tupdatedAt\x12#\n" +
+	"\rdispatched_at\x18\x10 \x01(\x03R\fdispatchedAt\x12\x1f\n" +
+	"\vexecuted_at\x18\x11 \x01(\x03R\n" +
+	"executedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x12 \x01(\x03R\texpiresAtB\t\n" +
+	"\a_result\"\xf9\x01\n" +
+	"\vListJobsReq\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06status\x18\x02 \x03(\tR\x06status\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12!\n" +
+	"\fsubmitted_by\x18\x04 \x01(\tR\vsubmittedBy\x12\x14\n" +
+	"\x05since\x18\x05 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x06 \x01(\x03R\x05until\x12\x12\n" +
+	"\x04text\x18\a \x01(\tR\x04text\x12\x12\n" +
+	"\x04sort\x18\b \x01(\tR\x04sort\x12\x16\n" +
+	"\x06cursor\x18\t \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\"V\n" +
+	"\fListJobsResp\x12%\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x11.sentinel.JobInfoR\x04jobs\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\xb2\x01\n" +
+	"\rSearchJobsReq\x12\x16\n" +
+	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x14\n" +
+	"\x05regex\x18\x02 \x01(\tR\x05regex\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05since\x18\x04 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x05 \x01(\x03R\x05until\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\"7\n" +
+	"\vSnippetPart\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x14\n" +
+	"\x05match\x18\x02 \x01(\bR\x05match\"J\n" +
+	"\aSnippet\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12+\n" +
+	"\x05parts\x18\x02 \x03(\v2\x15.sentinel.SnippetPartR\x05parts\"y\n" +
+	"\tSearchHit\x12#\n" +
+	"\x03job\x18\x01 \x01(\v2\x11.sentinel.JobInfoR\x03job\x12\x18\n" +
+	"\amatches\x18\x02 \x01(\x05R\amatches\x12-\n" +
+	"\bsnippets\x18\x03 \x03(\v2\x11.sentinel.SnippetR\bsnippets\"Z\n" +
+	"\x0eSearchJobsResp\x12'\n" +
+	"\x04hits\x18\x01 \x03(\v2\x13.sentinel.SearchHitR\x04hits\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\x8f\x02\n" +
+	"\x0fAgentConfigRule\x12\x14\n" +
+	"\x05scope\x18\x01 \x01(\tR\x05scope\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12<\n" +
+	"\x1aheartbeat_interval_seconds\x18\x03 \x01(\x05R\x18heartbeatIntervalSeconds\x12(\n" +
+	"\x10worker_pool_size\x18\x04 \x01(\x05R\x0eworkerPoolSize\x12*\n" +
+	"\x11allowed_job_types\x18\x05 \x01(\tR\x0fallowedJobTypes\x12\x1b\n" +
+	"\tlog_level\x18\x06 \x01(\tR\blogLevel\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\x03R\tupdatedAt\"F\n" +
+	"\x13AgentConfigRuleList\x12/\n" +
+	"\x05rules\x18\x01 \x03(\v2\x19.sentinel.AgentConfigRuleR\x05rules\"\x97\x01\n" +
+	"\fAgentRelease\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x0e\n" +
+	"\x02os\x18\x02 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x03 \x01(\tR\x04arch\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\"F\n" +
+	"\x10AgentReleaseList\x122\n" +
+	"\breleases\x18\x01 \x03(\v2\x16.sentinel.AgentReleaseR\breleases\"c\n" +
+	"\x15UploadAgentReleaseReq\x12,\n" +
+	"\x04meta\x18\x01 \x01(\v2\x16.sentinel.AgentReleaseH\x00R\x04meta\x12\x14\n" +
+	"\x04data\x18\x02 \x01(\fH\x00R\x04dataB\x06\n" +
+	"\x04part\"V\n" +
+	"\n" +
+	"RolloutReq\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12.\n" +
+	"\bselector\x18\x02 \x01(\v2\x12.sentinel.SelectorR\bselector\"@\n" +
+	"\vRolloutSkip\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"t\n" +
+	"\vRolloutResp\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x1a\n" +
+	"\btargeted\x18\x02 \x03(\tR\btargeted\x12/\n" +
+	"\askipped\x18\x03 \x03(\v2\x15.sentinel.RolloutSkipR\askipped\"\xb7\x01\n" +
+	"\fApprovalRule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
+	"\bjob_type\x18\x02 \x01(\tR\ajobType\x12.\n" +
+	"\bselector\x18\x03 \x01(\v2\x12.sentinel.SelectorR\bselector\x12'\n" +
+	"\x0fpayload_pattern\x18\x04 \x01(\tR\x0epayloadPattern\x12\x1f\n" +
+	"\vttl_seconds\x18\x05 \x01(\x05R\n" +
+	"ttlSeconds\"@\n" +
+	"\x10ApprovalRuleList\x12,\n" +
+	"\x05rules\x18\x01 \x03(\v2\x16.sentinel.ApprovalRuleR\x05rules\"\xeb\x01\n" +
+	"\n" +
+	"SecretInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\tR\aproject\x12*\n" +
+	"\x06agents\x18\x04 \x01(\v2\x12.sentinel.SelectorR\x06agents\x12#\n" +
+	"\rallowed_users\x18\x05 \x03(\tR\fallowedUsers\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x06 \x01(\tR\tupdatedBy\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\x03R\tupdatedAt\"<\n" +
+	"\n" +
+	"SecretList\x12.\n" +
+	"\asecrets\x18\x01 \x03(\v2\x14.sentinel.SecretInfoR\asecrets\"R\n" +
+	"\fPutSecretReq\x12,\n" +
+	"\x06secret\x18\x01 \x01(\v2\x14.sentinel.SecretInfoR\x06secret\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"M\n" +
+	"\x04User\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"0\n" +
+	"\bUserList\x12$\n" +
+	"\x05users\x18\x01 \x03(\v2\x0e.sentinel.UserR\x05users\"7\n" +
+	"\rCreateUserReq\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"J\n" +
+	"\x0eCreateUserResp\x12\"\n" +
+	"\x04user\x18\x01 \x01(\v2\x0e.sentinel.UserR\x04user\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"^\n" +
+	"\aProject\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"<\n" +
+	"\vProjectList\x12-\n" +
+	"\bprojects\x18\x01 \x03(\v2\x11.sentinel.ProjectR\bprojects\"&\n" +
+	"\n" +
+	"ProjectReq\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\"H\n" +
+	"\x10CreateProjectReq\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"k\n" +
+	"\x11CreateProjectResp\x12+\n" +
+	"\aproject\x18\x01 \x01(\v2\x11.sentinel.ProjectR\aproject\x12)\n" +
+	"\x10enrollment_token\x18\x02 \x01(\tR\x0fenrollmentToken\"<\n" +
+	"\x0fEnrollmentToken\x12)\n" +
+	"\x10enrollment_token\x18\x01 \x01(\tR\x0fenrollmentToken\"J\n" +
+	"\x06Member\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\tR\aproject\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\"8\n" +
+	"\n" +
+	"MemberList\x12*\n" +
+	"\amembers\x18\x01 \x03(\v2\x10.sentinel.MemberR\amembers\"\x85\x01\n" +
+	"\vQuotaLimits\x12&\n" +
+	"\x0fjobs_per_minute\x18\x01 \x01(\x05R\rjobsPerMinute\x12.\n" +
+	"\x13max_concurrent_jobs\x18\x02 \x01(\x05R\x11maxConcurrentJobs\x12\x1e\n" +
+	"\vmax_fan_out\x18\x03 \x01(\x05R\tmaxFanOut\"d\n" +
+	"\x05Quota\x12\x14\n" +
+	"\x05scope\x18\x01 \x01(\tR\x05scope\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12-\n" +
+	"\x06limits\x18\x03 \x01(\v2\x15.sentinel.QuotaLimitsR\x06limits\"\xae\x01\n" +
+	"\tQuotaList\x12'\n" +
+	"\x06quotas\x18\x01 \x03(\v2\x0f.sentinel.QuotaR\x06quotas\x128\n" +
+	"\fdefault_user\x18\x02 \x01(\v2\x15.sentinel.QuotaLimitsR\vdefaultUser\x12>\n" +
+	"\x0fdefault_project\x18\x03 \x01(\v2\x15.sentinel.QuotaLimitsR\x0edefaultProject\"\xbc\x01\n" +
+	"\n" +
+	"QuotaUsage\x12\x14\n" +
+	"\x05scope\x18\x01 \x01(\tR\x05scope\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12-\n" +
+	"\x06limits\x18\x03 \x01(\v2\x15.sentinel.QuotaLimitsR\x06limits\x12(\n" +
+	"\x10jobs_last_minute\x18\x04 \x01(\x05R\x0ejobsLastMinute\x12'\n" +
+	"\x0funfinished_jobs\x18\x05 \x01(\x05R\x0eunfinishedJobs\"j\n" +
+	"\x0eQuotaUsageResp\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.sentinel.QuotaUsageR\x04user\x12.\n" +
+	"\aproject\x18\x02 \x01(\v2\x14.sentinel.QuotaUsageR\aproject\"\xb2\x01\n" +
+	"\vAuditFilter\x12\x14\n" +
+	"\x05actor\x18\x01 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x14\n" +
+	"\x05since\x18\x04 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x05 \x01(\x03R\x05until\x12\x1b\n" +
+	"\tafter_seq\x18\x06 \x01(\x04R\bafterSeq\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\"\x86\x02\n" +
+	"\n" +
+	"AuditEntry\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x17\n" +
+	"\atime_ms\x18\x02 \x01(\x03R\x06timeMs\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x1b\n" +
+	"\tsource_ip\x18\x04 \x01(\tR\bsourceIp\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\x12\x16\n" +
+	"\x06target\x18\x06 \x01(\tR\x06target\x12!\n" +
+	"\fpayload_hash\x18\a \x01(\tR\vpayloadHash\x12\x16\n" +
+	"\x06result\x18\b \x01(\tR\x06result\x12\x1b\n" +
+	"\tprev_hash\x18\t \x01(\tR\bprevHash\x12\x12\n" +
+	"\x04hash\x18\n" +
+	" \x01(\tR\x04hash\"a\n" +
+	"\tAuditPage\x12.\n" +
+	"\aentries\x18\x01 \x03(\v2\x14.sentinel.AuditEntryR\aentries\x12$\n" +
+	"\x0enext_after_seq\x18\x02 \x01(\x04R\fnextAfterSeq\"\xb0\x01\n" +
+	"\x11AuditVerifyResult\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x18\n" +
+	"\aentries\x18\x02 \x01(\x04R\aentries\x12\x19\n" +
+	"\bhead_seq\x18\x03 \x01(\x04R\aheadSeq\x12\x1b\n" +
+	"\thead_hash\x18\x04 \x01(\tR\bheadHash\x12\x1b\n" +
+	"\tbroken_at\x18\x05 \x01(\x04R\bbrokenAt\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason*(\n" +
+	"\aJobType\x12\b\n" +
+	"\x04PING\x10\x00\x12\t\n" +
+	"\x05SHELL\x10\x01\x12\b\n" +
+	"\x04SCAN\x10\x022\xa5\x03\n" +
+	"\x0fSentinelService\x129\n" +
+	"\bRegister\x12\x15.sentinel.RegisterReq\x1a\x16.sentinel.RegisterResp\x12@\n" +
+	"\tHeartbeat\x12\x16.sentinel.HeartbeatReq\x1a\x17.sentinel.HeartbeatResp(\x010\x01\x12B\n" +
+	"\x0fReportJobStatus\x12\x16.sentinel.ReportJobReq\x1a\x17.sentinel.ReportJobResp\x12F\n" +
+	"\x0fReportJobOutput\x12\x18.sentinel.JobOutputChunk\x1a\x17.sentinel.ReportJobResp(\x01\x12D\n" +
+	"\x0eGetAgentConfig\x12\x1b.sentinel.GetAgentConfigReq\x1a\x15.sentinel.AgentConfig\x12C\n" +
+	"\rDownloadAgent\x12\x1a.sentinel.DownloadAgentReq\x1a\x14.sentinel.AgentChunk0\x012\xf3\x13\n" +
+	"\rSentinelAdmin\x12:\n" +
+	"\n" +
+	"ListAgents\x12\x17.sentinel.ListAgentsReq\x1a\x13.sentinel.AgentList\x12/\n" +
+	"\bGetAgent\x12\x12.sentinel.AgentReq\x1a\x0f.sentinel.Agent\x127\n" +
+	"\fSelectAgents\x12\x12.sentinel.Selector\x1a\x13.sentinel.AgentList\x12H\n" +
+	"\x0fGetAgentMetrics\x12\x19.sentinel.AgentMetricsReq\x1a\x1a.sentinel.AgentMetricsResp\x12D\n" +
+	"\x17GetEffectiveAgentConfig\x12\x12.sentinel.AgentReq\x1a\x15.sentinel.AgentConfig\x12;\n" +
+	"\x14ResetAgentCredential\x12\x12.sentinel.AgentReq\x1a\x0f.sentinel.Agent\x12<\n" +
+	"\tSubmitJob\x12\x16.sentinel.SubmitJobReq\x1a\x17.sentinel.SubmitJobResp\x120\n" +
+	"\tCancelJob\x12\x10.sentinel.JobReq\x1a\x11.sentinel.JobInfo\x121\n" +
+	"\n" +
+	"ApproveJob\x12\x10.sentinel.JobReq\x1a\x11.sentinel.JobInfo\x12-\n" +
+	"\x06GetJob\x12\x10.sentinel.JobReq\x1a\x11.sentinel.JobInfo\x12?\n" +
+	"\fGetJobOutput\x12\x16.sentinel.JobOutputReq\x1a\x17.sentinel.JobOutputResp\x129\n" +
+	"\bListJobs\x12\x15.sentinel.ListJobsReq\x1a\x16.sentinel.ListJobsResp\x12?\n" +
+	"\n" +
+	"SearchJobs\x12\x17.sentinel.SearchJobsReq\x1a\x18.sentinel.SearchJobsResp\x12F\n" +
+	"\x14ListAgentConfigRules\x12\x0f.sentinel.Empty\x1a\x1d.sentinel.AgentConfigRuleList\x12J\n" +
+	"\x12PutAgentConfigRule\x12\x19.sentinel.AgentConfigRule\x1a\x19.sentinel.AgentConfigRule\x12?\n" +
+	"\x15DeleteAgentConfigRule\x12\x15.sentinel.ScopeTarget\x1a\x0f.sentinel.Empty\x12O\n" +
+	"\x12UploadAgentRelease\x12\x1f.sentinel.UploadAgentReleaseReq\x1a\x16.sentinel.AgentRelease(\x01\x12@\n" +
+	"\x11ListAgentReleases\x12\x0f.sentinel.Empty\x1a\x1a.sentinel.AgentReleaseList\x12B\n" +
+	"\x13RolloutAgentRelease\x12\x14.sentinel.RolloutReq\x1a\x15.sentinel.RolloutResp\x12@\n" +
+	"\x11ListApprovalRules\x12\x0f.sentinel.Empty\x1a\x1a.sentinel.ApprovalRuleList\x12A\n" +
+	"\x0fPutApprovalRule\x12\x16.sentinel.ApprovalRule\x1a\x16.sentinel.ApprovalRule\x128\n" +
+	"\x12DeleteApprovalRule\x12\x11.sentinel.NameReq\x1a\x0f.sentinel.Empty\x124\n" +
+	"\vListSecrets\x12\x0f.sentinel.Empty\x1a\x14.sentinel.SecretList\x129\n" +
+	"\tPutSecret\x12\x16.sentinel.PutSecretReq\x1a\x14.sentinel.SecretInfo\x122\n" +
+	"\fDeleteSecret\x12\x11.sentinel.NameReq\x1a\x0f.sentinel.Empty\x120\n" +
+	"\tListUsers\x12\x0f.sentinel.Empty\x1a\x12.sentinel.UserList\x12?\n" +
+	"\n" +
+	"CreateUser\x12\x17.sentinel.CreateUserReq\x1a\x18.sentinel.CreateUserResp\x120\n" +
+	"\n" +
+	"DeleteUser\x12\x11.sentinel.NameReq\x1a\x0f.sentinel.Empty\x126\n" +
+	"\fListProjects\x12\x0f.sentinel.Empty\x1a\x15.sentinel.ProjectList\x12H\n" +
+	"\rCreateProject\x12\x1a.sentinel.CreateProjectReq\x1a\x1b.sentinel.CreateProjectResp\x126\n" +
+	"\rDeleteProject\x12\x14.sentinel.ProjectReq\x1a\x0f.sentinel.Empty\x12H\n" +
+	"\x15RotateEnrollmentToken\x12\x14.sentinel.ProjectReq\x1a\x19.sentinel.EnrollmentToken\x129\n" +
+	"\vListMembers\x12\x14.sentinel.ProjectReq\x1a\x14.sentinel.MemberList\x12/\n" +
+	"\tPutMember\x12\x10.sentinel.Member\x1a\x10.sentinel.Member\x121\n" +
+	"\fDeleteMember\x12\x10.sentinel.Member\x1a\x0f.sentinel.Empty\x12:\n" +
+	"\rGetQuotaUsage\x12\x0f.sentinel.Empty\x1a\x18.sentinel.QuotaUsageResp\x122\n" +
+	"\n" +
+	"ListQuotas\x12\x0f.sentinel.Empty\x1a\x13.sentinel.QuotaList\x12,\n" +
+	"\bPutQuota\x12\x0f.sentinel.Quota\x1a\x0f.sentinel.Quota\x125\n" +
+	"\vDeleteQuota\x12\x15.sentinel.ScopeTarget\x1a\x0f.sentinel.Empty\x128\n" +
+	"\n" +
+	"QueryAudit\x12\x15.sentinel.AuditFilter\x1a\x13.sentinel.AuditPage\x12<\n" +
+	"\vExportAudit\x12\x15.sentinel.AuditFilter\x1a\x14.sentinel.AuditEntry0\x01\x12;\n" +
+	"\vVerifyAudit\x12\x0f.sentinel.Empty\x1a\x1b.sentinel.AuditVerifyResultB\aZ\x05./;pbb\x06proto3"
+
+var (
+	file_api_proto_sentinel_proto_rawDescOnce sync.Once
+	file_api_proto_sentinel_proto_rawDescData []byte
+)
+
+func file_api_proto_sentinel_proto_rawDescGZIP() []byte {
+	file_api_proto_sentinel_proto_rawDescOnce.Do(func() {
+		file_api_proto_sentinel_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_sentinel_proto_rawDesc), len(file_api_proto_sentinel_proto_rawDesc)))
+	})
+	return file_api_proto_sentinel_proto_rawDescData
+}
+
+var file_api_proto_sentinel_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_sentinel_proto_msgTypes = make([]protoimpl.MessageInfo, 76)
+var file_api_proto_sentinel_proto_goTypes = []any{
+	(JobType)(0),                  // 0: sentinel.JobType
+	(*RegisterReq)(nil),           // 1: sentinel.RegisterReq
+	(*RegisterResp)(nil),          // 2: sentinel.RegisterResp
+	(*HeartbeatReq)(nil),          // 3: sentinel.HeartbeatReq
+	(*Job)(nil),                   // 4: sentinel.Job
+	(*SecretEnv)(nil),             // 5: sentinel.SecretEnv
+	(*ReportJobReq)(nil),          // 6: sentinel.ReportJobReq
+	(*ReportJobResp)(nil),         // 7: sentinel.ReportJobResp
+	(*JobOutputChunk)(nil),        // 8: sentinel.JobOutputChunk
+	(*HeartbeatResp)(nil),         // 9: sentinel.HeartbeatResp
+	(*GetAgentConfigReq)(nil),     // 10: sentinel.GetAgentConfigReq
+	(*AgentConfig)(nil),           // 11: sentinel.AgentConfig
+	(*AgentUpdate)(nil),           // 12: sentinel.AgentUpdate
+	(*DownloadAgentReq)(nil),      // 13: sentinel.DownloadAgentReq
+	(*AgentChunk)(nil),            // 14: sentinel.AgentChunk
+	(*Empty)(nil),                 // 15: sentinel.Empty
+	(*NameReq)(nil),               // 16: sentinel.NameReq
+	(*ScopeTarget)(nil),           // 17: sentinel.ScopeTarget
+	(*Agent)(nil),                 // 18: sentinel.Agent
+	(*ListAgentsReq)(nil),         // 19: sentinel.ListAgentsReq
+	(*AgentList)(nil),             // 20: sentinel.AgentList
+	(*AgentReq)(nil),              // 21: sentinel.AgentReq
+	(*Selector)(nil),              // 22: sentinel.Selector
+	(*AgentMetricsReq)(nil),       // 23: sentinel.AgentMetricsReq
+	(*MetricPoint)(nil),           // 24: sentinel.MetricPoint
+	(*MetricSeries)(nil),          // 25: sentinel.MetricSeries
+	(*AgentMetricsResp)(nil),      // 26: sentinel.AgentMetricsResp
+	(*SubmitJobReq)(nil),          // 27: sentinel.SubmitJobReq
+	(*SubmitJobResp)(nil),         // 28: sentinel.SubmitJobResp
+	(*JobReq)(nil),                // 29: sentinel.JobReq
+	(*JobOutputReq)(nil),          // 30: sentinel.JobOutputReq
+	(*JobOutputResp)(nil),         // 31: sentinel.JobOutputResp
+	(*SecretRef)(nil),             // 32: sentinel.SecretRef
+	(*JobInfo)(nil),               // 33: sentinel.JobInfo
+	(*ListJobsReq)(nil),           // 34: sentinel.ListJobsReq
+	(*ListJobsResp)(nil),          // 35: sentinel.ListJobsResp
+	(*SearchJobsReq)(nil),         // 36: sentinel.SearchJobsReq
+	(*SnippetPart)(nil),           // 37: sentinel.SnippetPart
+	(*Snippet)(nil),               // 38: sentinel.Snippet
+	(*SearchHit)(nil),             // 39: sentinel.SearchHit
+	(*SearchJobsResp)(nil),        // 40: sentinel.SearchJobsResp
+	(*AgentConfigRule)(nil),       // 41: sentinel.AgentConfigRule
+	(*AgentConfigRuleList)(nil),   // 42: sentinel.AgentConfigRuleList
+	(*AgentRelease)(nil),          // 43: sentinel.AgentRelease
+	(*AgentReleaseList)(nil),      // 44: sentinel.AgentReleaseList
+	(*UploadAgentReleaseReq)(nil), // 45: sentinel.UploadAgentReleaseReq
+	(*RolloutReq)(nil),            // 46: sentinel.RolloutReq
+	(*RolloutSkip)(nil),           // 47: sentinel.RolloutSkip
+	(*RolloutResp)(nil),           // 48: sentinel.RolloutResp
+	(*ApprovalRule)(nil),          // 49: sentinel.ApprovalRule
+	(*ApprovalRuleList)(nil),      // 50: sentinel.ApprovalRuleList
+	(*SecretInfo)(nil),            // 51: sentinel.SecretInfo
+	(*SecretList)(nil),            // 52: sentinel.SecretList
+	(*PutSecretReq)(nil),          // 53: sentinel.PutSecretReq
+	(*User)(nil),                  // 54: sentinel.User
+	(*UserList)(nil),              // 55: sentinel.UserList
+	(*CreateUserReq)(nil),         // 56: sentinel.CreateUserReq
+	(*CreateUserResp)(nil),        // 57: sentinel.CreateUserResp
+	(*Project)(nil),               // 58: sentinel.Project
+	(*ProjectList)(nil),           // 59: sentinel.ProjectList
+	(*ProjectReq)(nil),            // 60: sentinel.ProjectReq
+	(*CreateProjectReq)(nil),      // 61: sentinel.CreateProjectReq
+	(*CreateProjectResp)(nil),     // 62: sentinel.CreateProjectResp
+	(*EnrollmentToken)(nil),       // 63: sentinel.EnrollmentToken
+	(*Member)(nil),                // 64: sentinel.Member
+	(*MemberList)(nil),            // 65: sentinel.MemberList
+	(*QuotaLimits)(nil),           // 66: sentinel.QuotaLimits
+	(*Quota)(nil),                 // 67: sentinel.Quota
+	(*QuotaList)(nil),             // 68: sentinel.QuotaList
+	(*QuotaUsage)(nil),            // 69: sentinel.QuotaUsage
+	(*QuotaUsageResp)(nil),        // 70: sentinel.QuotaUsageResp
+	(*AuditFilter)(nil),           // 71: sentinel.AuditFilter
+	(*AuditEntry)(nil),            // 72: sentinel.AuditEntry
+	(*AuditPage)(nil),             // 73: sentinel.AuditPage
+	(*AuditVerifyResult)(nil),     // 74: sentinel.AuditVerifyResult
+	nil,                           // 75: sentinel.AgentMetricsResp.AgentsEntry
+	nil,                           // 76: sentinel.SubmitJobReq.SecretsEntry
+}
+var file_api_proto_sentinel_proto_depIdxs = []int32{
+	0,  // 0: sentinel.Job.type:type_name -> sentinel.JobType
+	5,  // 1: sentinel.Job.secrets:type_name -> sentinel.SecretEnv
+	4,  // 2: sentinel.HeartbeatResp.job:type_name -> sentinel.Job
+	12, // 3: sentinel.HeartbeatResp.update:type_name -> sentinel.AgentUpdate
+	0,  // 4: sentinel.AgentConfig.allowed_job_types:type_name -> sentinel.JobType
+	18, // 5: sentinel.AgentList.agents:type_name -> sentinel.Agent
+	24, // 6: sentinel.MetricSeries.points:type_name -> sentinel.MetricPoint
+	75, // 7: sentinel.AgentMetricsResp.agents:type_name -> sentinel.AgentMetricsResp.AgentsEntry
+	22, // 8: sentinel.SubmitJobReq.selector:type_name -> sentinel.Selector
+	76, // 9: sentinel.SubmitJobReq.secrets:type_name -> sentinel.SubmitJobReq.SecretsEntry
+	33, // 10: sentinel.SubmitJobResp.jobs:type_name -> sentinel.JobInfo
+	8,  // 11: sentinel.JobOutputResp.chunks:type_name -> sentinel.JobOutputChunk
+	32, // 12: sentinel.JobInfo.secrets:type_name -> sentinel.SecretRef
+	33, // 13: sentinel.ListJobsResp.jobs:type_name -> sentinel.JobInfo
+	37, // 14: sentinel.Snippet.parts:type_name -> sentinel.SnippetPart
+	33, // 15: sentinel.SearchHit.job:type_name -> sentinel.JobInfo
+	38, // 16: sentinel.SearchHit.snippets:type_name -> sentinel.Snippet
+	39, // 17: sentinel.SearchJobsResp.hits:type_name -> sentinel.SearchHit
+	41, // 18: sentinel.AgentConfigRuleList.rules:type_name -> sentinel.AgentConfigRule
+	43, // 19: sentinel.AgentReleaseList.releases:type_name -> sentinel.AgentRelease
+	43, // 20: sentinel.UploadAgentReleaseReq.meta:type_name -> sentinel.AgentRelease
+	22, // 21: sentinel.RolloutReq.selector:type_name -> sentinel.Selector
+	47, // 22: sentinel.RolloutResp.skipped:type_name -> sentinel.RolloutSkip
+	22, // 23: sentinel.ApprovalRule.selector:type_name -> sentinel.Selector
+	49, // 24: sentinel.ApprovalRuleList.rules:type_name -> sentinel.ApprovalRule
+	22, // 25: sentinel.SecretInfo.agents:type_name -> sentinel.Selector
+	51, // 26: sentinel.SecretList.secrets:type_name -> sentinel.SecretInfo
+	51, // 27: sentinel.PutSecretReq.secret:type_name -> sentinel.SecretInfo
+	54, // 28: sentinel.UserList.users:type_name -> sentinel.User
+	54, // 29: sentinel.CreateUserResp.user:type_name -> sentinel.User
+	58, // 30: sentinel.ProjectList.projects:type_name -> sentinel.Project
+	58, // 31: sentinel.CreateProjectResp.project:type_name -> sentinel.Project
+	64, // 32: sentinel.MemberList.members:type_name -> sentinel.Member
+	66, // 33: sentinel.Quota.limits:type_name -> sentinel.QuotaLimits
+	67, // 34: sentinel.QuotaList.quotas:type_name -> sentinel.Quota
+	66, // 35: sentinel.QuotaList.default_user:type_name -> sentinel.QuotaLimits
+	66, // 36: sentinel.QuotaList.default_project:type_name -> sentinel.QuotaLimits
+	66, // 37: sentinel.QuotaUsage.limits:type_name -> sentinel.QuotaLimits
+	69, // 38: sentinel.QuotaUsageResp.user:type_name -> sentinel.QuotaUsage
+	69, // 39: sentinel.QuotaUsageResp.project:type_name -> sentinel.QuotaUsage
+	72, // 40: sentinel.AuditPage.entries:type_name -> sentinel.AuditEntry
+	25, // 41: sentinel.AgentMetricsResp.AgentsEntry.value:type_name -> sentinel.MetricSeries
+	1,  // 42: sentinel.SentinelService.Register:input_type -> sentinel.RegisterReq
+	3,  // 43: sentinel.SentinelService.Heartbeat:input_type -> sentinel.HeartbeatReq
+	6,  // 44: sentinel.SentinelService.ReportJobStatus:input_type -> sentinel.ReportJobReq
+	8,  // 45: sentinel.SentinelService.ReportJobOutput:input_type -> sentinel.JobOutputChunk
+	10, // 46: sentinel.SentinelService.GetAgentConfig:input_type -> sentinel.GetAgentConfigReq
+	13, // 47: sentinel.SentinelService.DownloadAgent:input_type -> sentinel.DownloadAgentReq
+	19, // 48: sentinel.SentinelAdmin.ListAgents:input_type -> sentinel.ListAgentsReq
+	21, // 49: sentinel.SentinelAdmin.GetAgent:input_type -> sentinel.AgentReq
+	22, // 50: sentinel.SentinelAdmin.SelectAgents:input_type -> sentinel.Selector
+	23, // 51: sentinel.SentinelAdmin.GetAgentMetrics:input_type -> sentinel.AgentMetricsReq
+	21, // 52: sentinel.SentinelAdmin.GetEffectiveAgentConfig:input_type -> sentinel.AgentReq
+	21, // 53: sentinel.SentinelAdmin.ResetAgentCredential:input_type -> sentinel.AgentReq
+	27, // 54: sentinel.SentinelAdmin.SubmitJob:input_type -> sentinel.SubmitJobReq
+	29, // 55: sentinel.SentinelAdmin.CancelJob:input_type -> sentinel.JobReq
+	29, // 56: sentinel.SentinelAdmin.ApproveJob:input_type -> sentinel.JobReq
+	29, // 57: sentinel.SentinelAdmin.GetJob:input_type -> sentinel.JobReq
+	30, // 58: sentinel.SentinelAdmin.GetJobOutput:input_type -> sentinel.JobOutputReq
+	34, // 59: sentinel.SentinelAdmin.ListJobs:input_type -> sentinel.ListJobsReq
+	36, // 60: sentinel.SentinelAdmin.SearchJobs:input_type -> sentinel.SearchJobsReq
+	15, // 61: sentinel.SentinelAdmin.ListAgentConfigRules:input_type -> sentinel.Empty
+	41, // 62: sentinel.SentinelAdmin.PutAgentConfigRule:input_type -> sentinel.AgentConfigRule
+	17, // 63: sentinel.SentinelAdmin.DeleteAgentConfigRule:input_type -> sentinel.ScopeTarget
+	45, // 64: sentinel.SentinelAdmin.UploadAgentRelease:input_type -> sentinel.UploadAgentReleaseReq
+	15, // 65: sentinel.SentinelAdmin.ListAgentReleases:input_type -> sentinel.Empty
+	46, // 66: sentinel.SentinelAdmin.RolloutAgentRelease:input_type -> sentinel.RolloutReq
+	15, // 67: sentinel.SentinelAdmin.ListApprovalRules:input_type -> sentinel.Empty
+	49, // 68: sentinel.SentinelAdmin.PutApprovalRule:input_type -> sentinel.ApprovalRule
+	16, // 69: sentinel.SentinelAdmin.DeleteApprovalRule:input_type -> sentinel.NameReq
+	15, // 70: sentinel.SentinelAdmin.ListSecrets:input_type -> sentinel.Empty
+	53, // 71: sentinel.SentinelAdmin.PutSecret:input_type -> sentinel.PutSecretReq
+	16, // 72: sentinel.SentinelAdmin.DeleteSecret:input_type -> sentinel.NameReq
+	15, // 73: sentinel.SentinelAdmin.ListUsers:input_type -> sentinel.Empty
+	56, // 74: sentinel.SentinelAdmin.CreateUser:input_type -> sentinel.CreateUserReq
+	16, // 75: sentinel.SentinelAdmin.DeleteUser:input_type -> sentinel.NameReq
+	15, // 76: sentinel.SentinelAdmin.ListProjects:input_type -> sentinel.Empty
+	61, // 77: sentinel.SentinelAdmin.CreateProject:input_type -> sentinel.CreateProjectReq
+	60, // 78: sentinel.SentinelAdmin.DeleteProject:input_type -> sentinel.ProjectReq
+	60, // 79: sentinel.SentinelAdmin.RotateEnrollmentToken:input_type -> sentinel.ProjectReq
+	60, // 80: sentinel.SentinelAdmin.ListMembers:input_type -> sentinel.ProjectReq
+	64, // 81: sentinel.SentinelAdmin.PutMember:input_type -> sentinel.Member
+	64, // 82: sentinel.SentinelAdmin.DeleteMember:input_type -> sentinel.Member
+	15, // 83: sentinel.SentinelAdmin.GetQuotaUsage:input_type -> sentinel.Empty
+	15, // 84: sentinel.SentinelAdmin.ListQuotas:input_type -> sentinel.Empty
+	67, // 85: sentinel.SentinelAdmin.PutQuota:input_type -> sentinel.Quota
+	17, // 86: sentinel.SentinelAdmin.DeleteQuota:input_type -> sentinel.ScopeTarget
+	71, // 87: sentinel.SentinelAdmin.QueryAudit:input_type -> sentinel.AuditFilter
+	71, // 88: sentinel.SentinelAdmin.ExportAudit:input_type -> sentinel.AuditFilter
+	15, // 89: sentinel.SentinelAdmin.VerifyAudit:input_type -> sentinel.Empty
+	2,  // 90: sentinel.SentinelService.Register:output_type -> sentinel.RegisterResp
+	9,  // 91: sentinel.SentinelService.Heartbeat:output_type -> sentinel.HeartbeatResp
+	7,  // 92: sentinel.SentinelService.ReportJobStatus:output_type -> sentinel.ReportJobResp
+	7,  // 93: sentinel.SentinelService.ReportJobOutput:output_type -> sentinel.ReportJobResp
+	11, // 94: sentinel.SentinelService.GetAgentConfig:output_type -> sentinel.AgentConfig
+	14, // 95: sentinel.SentinelService.DownloadAgent:output_type -> sentinel.AgentChunk
+	20, // 96: sentinel.SentinelAdmin.ListAgents:output_type -> sentinel.AgentList
+	18, // 97: sentinel.SentinelAdmin.GetAgent:output_type -> sentinel.Agent
+	20, // 98: sentinel.SentinelAdmin.SelectAgents:output_type -> sentinel.AgentList
+	26, // 99: sentinel.SentinelAdmin.GetAgentMetrics:output_type -> sentinel.AgentMetricsResp
+	11, // 100: sentinel.SentinelAdmin.GetEffectiveAgentConfig:output_type -> sentinel.AgentConfig
+	18, // 101: sentinel.SentinelAdmin.ResetAgentCredential:output_type -> sentinel.Agent
+	28, // 102: sentinel.SentinelAdmin.SubmitJob:output_type -> sentinel.SubmitJobResp
+	33, // 103: sentinel.SentinelAdmin.CancelJob:output_type -> sentinel.JobInfo
+	33, // 104: sentinel.SentinelAdmin.ApproveJob:output_type -> sentinel.JobInfo
+	33, // 105: sentinel.SentinelAdmin.GetJob:output_type -> sentinel.JobInfo
+	31, // 106: sentinel.SentinelAdmin.GetJobOutput:output_type -> sentinel.JobOutputResp
+	35, // 107: sentinel.SentinelAdmin.ListJobs:output_type -> sentinel.ListJobsResp
+	40, // 108: sentinel.SentinelAdmin.SearchJobs:output_type -> sentinel.SearchJobsResp
+	42, // 109: sentinel.SentinelAdmin.ListAgentConfigRules:output_type -> sentinel.AgentConfigRuleList
+	41, // 110: sentinel.SentinelAdmin.PutAgentConfigRule:output_type -> sentinel.AgentConfigRule
+	15, // 111: sentinel.SentinelAdmin.DeleteAgentConfigRule:output_type -> sentinel.Empty
+	43, // 112: sentinel.SentinelAdmin.UploadAgentRelease:output_type -> sentinel.AgentRelease
+	44, // 113: sentinel.SentinelAdmin.ListAgentReleases:output_type -> sentinel.AgentReleaseList
+	48, // 114: sentinel.SentinelAdmin.RolloutAgentRelease:output_type -> sentinel.RolloutResp
+	50, // 115: sentinel.SentinelAdmin.ListApprovalRules:output_type -> sentinel.ApprovalRuleList
+	49, // 116: sentinel.SentinelAdmin.PutApprovalRule:output_type -> sentinel.ApprovalRule
+	15, // 117: sentinel.SentinelAdmin.DeleteApprovalRule:output_type -> sentinel.Empty
+	52, // 118: sentinel.SentinelAdmin.ListSecrets:output_type -> sentinel.SecretList
+	51, // 119: sentinel.SentinelAdmin.PutSecret:output_type -> sentinel.SecretInfo
+	15, // 120: sentinel.SentinelAdmin.DeleteSecret:output_type -> sentinel.Empty
+	55, // 121: sentinel.SentinelAdmin.ListUsers:output_type -> sentinel.UserList
+	57, // 122: sentinel.SentinelAdmin.CreateUser:output_type -> sentinel.CreateUserResp
+	15, // 123: sentinel.SentinelAdmin.DeleteUser:output_type -> sentinel.Empty
+	59, // 124: sentinel.SentinelAdmin.ListProjects:output_type -> sentinel.ProjectList
+	62, // 125: sentinel.SentinelAdmin.CreateProject:output_type -> sentinel.CreateProjectResp
+	15, // 126: sentinel.SentinelAdmin.DeleteProject:output_type -> sentinel.Empty
+	63, // 127: sentinel.SentinelAdmin.RotateEnrollmentToken:output_type -> sentinel.EnrollmentToken
+	65, // 128: sentinel.SentinelAdmin.ListMembers:output_type -> sentinel.MemberList
+	64, // 129: sentinel.SentinelAdmin.PutMember:output_type -> sentinel.Member
+	15, // 130: sentinel.SentinelAdmin.DeleteMember:output_type -> sentinel.Empty
+	70, // 131: sentinel.SentinelAdmin.GetQuotaUsage:output_type -> sentinel.QuotaUsageResp
+	68, // 132: sentinel.SentinelAdmin.ListQuotas:output_type -> sentinel.QuotaList
+	67, // 133: sentinel.SentinelAdmin.PutQuota:output_type -> sentinel.Quota
+	15, // 134: sentinel.SentinelAdmin.DeleteQuota:output_type -> sentinel.Empty
+	73, // 135: sentinel.SentinelAdmin.QueryAudit:output_type -> sentinel.AuditPage
+	72, // 136: sentinel.SentinelAdmin.ExportAudit:output_type -> sentinel.AuditEntry
+	74, // 137: sentinel.SentinelAdmin.VerifyAudit:output_type -> sentinel.AuditVerifyResult
+	90, // [90:138] is the sub-list for method output_type
+	42, // [42:90] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_sentinel_proto_init() }
+func file_api_proto_sentinel_proto_init() {
+	if File_api_proto_sentinel_proto != nil {
+		return
+	}
+	file_api_proto_sentinel_proto_msgTypes[32].OneofWrappers = []any{}
+	file_api_proto_sentinel_proto_msgTypes[44].OneofWrappers = []any{
+		(*UploadAgentReleaseReq_Meta)(nil),
+		(*UploadAgentReleaseReq_Data)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -565,9 +5407,9 @@ func file_api_proto_sentinel_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_sentinel_proto_rawDesc), len(file_api_proto_sentinel_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   7,
+			NumMessages:   76,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_api_proto_sentinel_proto_goTypes,
 		DependencyIndexes: file_api_proto_sentinel_proto_depIdxs,
@@ -22,15 +22,24 @@ const (
 	SentinelService_Register_FullMethodName        = "/sentinel.SentinelService/Register"
 	SentinelService_Heartbeat_FullMethodName       = "/sentinel.SentinelService/Heartbeat"
 	SentinelService_ReportJobStatus_FullMethodName = "/sentinel.SentinelService/ReportJobStatus"
+	SentinelService_ReportJobOutput_FullMethodName = "/sentinel.SentinelService/ReportJobOutput"
+	SentinelService_GetAgentConfig_FullMethodName  = "/sentinel.SentinelService/GetAgentConfig"
+	SentinelService_DownloadAgent_FullMethodName   = "/sentinel.SentinelService/DownloadAgent"
 )
 
 // SentinelServiceClient is the client API for SentinelService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SentinelService Agent 使用的接口. 除 Register 以外的调用都要在 metadata 里带 x-sentinel-agent-id 和
+// x-sentinel-agent-credential; credential 在首次注册时签发, 有 credential 的 Agent 重新注册时也要带上.
 type SentinelServiceClient interface {
 	Register(ctx context.Context, in *RegisterReq, opts ...grpc.CallOption) (*RegisterResp, error)
 	Heartbeat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HeartbeatReq, HeartbeatResp], error)
 	ReportJobStatus(ctx context.Context, in *ReportJobReq, opts ...grpc.CallOption) (*ReportJobResp, error)
+	ReportJobOutput(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[JobOutputChunk, ReportJobResp], error)
+	GetAgentConfig(ctx context.Context, in *GetAgentConfigReq, opts ...grpc.CallOption) (*AgentConfig, error)
+	DownloadAgent(ctx context.Context, in *DownloadAgentReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentChunk], error)
 }
 
 type sentinelServiceClient struct {
@@ -74,13 +83,61 @@ func (c *sentinelServiceClient) ReportJobStatus(ctx context.Context, in *ReportJ
 	return out, nil
 }
 
+func (c *sentinelServiceClient) ReportJobOutput(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[JobOutputChunk, ReportJobResp], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SentinelService_ServiceDesc.Streams[1], SentinelService_ReportJobOutput_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[JobOutputChunk, ReportJobResp]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelService_ReportJobOutputClient = grpc.ClientStreamingClient[JobOutputChunk, ReportJobResp]
+
+func (c *sentinelServiceClient) GetAgentConfig(ctx context.Context, in *GetAgentConfigReq, opts ...grpc.CallOption) (*AgentConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentConfig)
+	err := c.cc.Invoke(ctx, SentinelService_GetAgentConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) DownloadAgent(ctx context.Context, in *DownloadAgentReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AgentChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SentinelService_ServiceDesc.Streams[2], SentinelService_DownloadAgent_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadAgentReq, AgentChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelService_DownloadAgentClient = grpc.ServerStreamingClient[AgentChunk]
+
 // SentinelServiceServer is the server API for SentinelService service.
 // All implementations must embed UnimplementedSentinelServiceServer
 // for forward compatibility.
+//
+// SentinelService Agent 使用的接口. 除 Register 以外的调用都要在 metadata 里带 x-sentinel-agent-id 和
+// x-sentinel-agent-credential; credential 在首次注册时签发, 有 credential 的 Agent 重新注册时也要带上.
 type SentinelServiceServer interface {
 	Register(context.Context, *RegisterReq) (*RegisterResp, error)
 	Heartbeat(grpc.BidiStreamingServer[HeartbeatReq, HeartbeatResp]) error
 	ReportJobStatus(context.Context, *ReportJobReq) (*ReportJobResp, error)
+	ReportJobOutput(grpc.ClientStreamingServer[JobOutputChunk, ReportJobResp]) error
+	GetAgentConfig(context.Context, *GetAgentConfigReq) (*AgentConfig, error)
+	DownloadAgent(*DownloadAgentReq, grpc.ServerStreamingServer[AgentChunk]) error
 	mustEmbedUnimplementedSentinelServiceServer()
 }
 
@@ -100,6 +157,15 @@ func (UnimplementedSentinelServiceServer) Heartbeat(grpc.BidiStreamingServer[Hea
 func (UnimplementedSentinelServiceServer) ReportJobStatus(context.Context, *ReportJobReq) (*ReportJobResp, error) {
 	return nil, status.Error(codes.Unimplemented, "method ReportJobStatus not implemented")
 }
+func (UnimplementedSentinelServiceServer) ReportJobOutput(grpc.ClientStreamingServer[JobOutputChunk, ReportJobResp]) error {
+	return status.Error(codes.Unimplemented, "method ReportJobOutput not implemented")
+}
+func (UnimplementedSentinelServiceServer) GetAgentConfig(context.Context, *GetAgentConfigReq) (*AgentConfig, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAgentConfig not implemented")
+}
+func (UnimplementedSentinelServiceServer) DownloadAgent(*DownloadAgentReq, grpc.ServerStreamingServer[AgentChunk]) error {
+	return status.Error(codes.Unimplemented, "method DownloadAgent not implemented")
+}
 func (UnimplementedSentinelServiceServer) mustEmbedUnimplementedSentinelServiceServer() {}
 func (UnimplementedSentinelServiceServer) testEmbeddedByValue()                         {}
 
@@ -164,6 +230,42 @@ func _SentinelService_ReportJobStatus_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SentinelService_ReportJobOutput_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SentinelServiceServer).ReportJobOutput(&grpc.GenericServerStream[JobOutputChunk, ReportJobResp]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelService_ReportJobOutputServer = grpc.ClientStreamingServer[JobOutputChunk, ReportJobResp]
+
+func _SentinelService_GetAgentConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAgentConfigReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).GetAgentConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_GetAgentConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).GetAgentConfig(ctx, req.(*GetAgentConfigReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_DownloadAgent_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadAgentReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SentinelServiceServer).DownloadAgent(m, &grpc.GenericServerStream[DownloadAgentReq, AgentChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelService_DownloadAgentServer = grpc.ServerStreamingServer[AgentChunk]
+
 // SentinelService_ServiceDesc is the grpc.ServiceDesc for SentinelService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -179,6 +281,10 @@ var SentinelService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReportJobStatus",
 			Handler:    _SentinelService_ReportJobStatus_Handler,
 		},
+		{
+			MethodName: "GetAgentConfig",
+			Handler:    _SentinelService_GetAgentConfig_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -187,6 +293,1685 @@ var SentinelService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "ReportJobOutput",
+			Handler:       _SentinelService_ReportJobOutput_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAgent",
+			Handler:       _SentinelService_DownloadAgent_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/proto/sentinel.proto",
+}
+
+const (
+	SentinelAdmin_ListAgents_FullMethodName              = "/sentinel.SentinelAdmin/ListAgents"
+	SentinelAdmin_GetAgent_FullMethodName                = "/sentinel.SentinelAdmin/GetAgent"
+	SentinelAdmin_SelectAgents_FullMethodName            = "/sentinel.SentinelAdmin/SelectAgents"
+	SentinelAdmin_GetAgentMetrics_FullMethodName         = "/sentinel.SentinelAdmin/GetAgentMetrics"
+	SentinelAdmin_GetEffectiveAgentConfig_FullMethodName = "/sentinel.SentinelAdmin/GetEffectiveAgentConfig"
+	SentinelAdmin_ResetAgentCredential_FullMethodName    = "/sentinel.SentinelAdmin/ResetAgentCredential"
+	SentinelAdmin_SubmitJob_FullMethodName               = "/sentinel.SentinelAdmin/SubmitJob"
+	SentinelAdmin_CancelJob_FullMethodName               = "/sentinel.SentinelAdmin/CancelJob"
+	SentinelAdmin_ApproveJob_FullMethodName              = "/sentinel.SentinelAdmin/ApproveJob"
+	SentinelAdmin_GetJob_FullMethodName                  = "/sentinel.SentinelAdmin/GetJob"
+	SentinelAdmin_GetJobOutput_FullMethodName            = "/sentinel.SentinelAdmin/GetJobOutput"
+	SentinelAdmin_ListJobs_FullMethodName                = "/sentinel.SentinelAdmin/ListJobs"
+	SentinelAdmin_SearchJobs_FullMethodName              = "/sentinel.SentinelAdmin/SearchJobs"
+	SentinelAdmin_ListAgentConfigRules_FullMethodName    = "/sentinel.SentinelAdmin/ListAgentConfigRules"
+	SentinelAdmin_PutAgentConfigRule_FullMethodName      = "/sentinel.SentinelAdmin/PutAgentConfigRule"
+	SentinelAdmin_DeleteAgentConfigRule_FullMethodName   = "/sentinel.SentinelAdmin/DeleteAgentConfigRule"
+	SentinelAdmin_UploadAgentRelease_FullMethodName      = "/sentinel.SentinelAdmin/UploadAgentRelease"
+	SentinelAdmin_ListAgentReleases_FullMethodName       = "/sentinel.SentinelAdmin/ListAgentReleases"
+	SentinelAdmin_RolloutAgentRelease_FullMethodName     = "/sentinel.SentinelAdmin/RolloutAgentRelease"
+	SentinelAdmin_ListApprovalRules_FullMethodName       = "/sentinel.SentinelAdmin/ListApprovalRules"
+	SentinelAdmin_PutApprovalRule_FullMethodName         = "/sentinel.SentinelAdmin/PutApprovalRule"
+	SentinelAdmin_DeleteApprovalRule_FullMethodName      = "/sentinel.SentinelAdmin/DeleteApprovalRule"
+	SentinelAdmin_ListSecrets_FullMethodName             = "/sentinel.SentinelAdmin/ListSecrets"
+	SentinelAdmin_PutSecret_FullMethodName               = "/sentinel.SentinelAdmin/PutSecret"
+	SentinelAdmin_DeleteSecret_FullMethodName            = "/sentinel.SentinelAdmin/DeleteSecret"
+	SentinelAdmin_ListUsers_FullMethodName               = "/sentinel.SentinelAdmin/ListUsers"
+	SentinelAdmin_CreateUser_FullMethodName              = "/sentinel.SentinelAdmin/CreateUser"
+	SentinelAdmin_DeleteUser_FullMethodName              = "/sentinel.SentinelAdmin/DeleteUser"
+	SentinelAdmin_ListProjects_FullMethodName            = "/sentinel.SentinelAdmin/ListProjects"
+	SentinelAdmin_CreateProject_FullMethodName           = "/sentinel.SentinelAdmin/CreateProject"
+	SentinelAdmin_DeleteProject_FullMethodName           = "/sentinel.SentinelAdmin/DeleteProject"
+	SentinelAdmin_RotateEnrollmentToken_FullMethodName   = "/sentinel.SentinelAdmin/RotateEnrollmentToken"
+	SentinelAdmin_ListMembers_FullMethodName             = "/sentinel.SentinelAdmin/ListMembers"
+	SentinelAdmin_PutMember_FullMethodName               = "/sentinel.SentinelAdmin/PutMember"
+	SentinelAdmin_DeleteMember_FullMethodName            = "/sentinel.SentinelAdmin/DeleteMember"
+	SentinelAdmin_GetQuotaUsage_FullMethodName           = "/sentinel.SentinelAdmin/GetQuotaUsage"
+	SentinelAdmin_ListQuotas_FullMethodName              = "/sentinel.SentinelAdmin/ListQuotas"
+	SentinelAdmin_PutQuota_FullMethodName                = "/sentinel.SentinelAdmin/PutQuota"
+	SentinelAdmin_DeleteQuota_FullMethodName             = "/sentinel.SentinelAdmin/DeleteQuota"
+	SentinelAdmin_QueryAudit_FullMethodName              = "/sentinel.SentinelAdmin/QueryAudit"
+	SentinelAdmin_ExportAudit_FullMethodName             = "/sentinel.SentinelAdmin/ExportAudit"
+	SentinelAdmin_VerifyAudit_FullMethodName             = "/sentinel.SentinelAdmin/VerifyAudit"
+)
+
+// SentinelAdminClient is the client API for SentinelAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SentinelAdmin 管理接口, 能力和 HTTP API 一致, 两边调用同一套服务层.
+// metadata 里带 authorization: Bearer <token>, 所需角色和对应的 HTTP 路由相同.
+// Agent / 任务 / 配额用量作用于 x-sentinel-project 指定的项目 (缺省 default); 带 project 字段的请求以字段为准.
+// 时间都是 unix 秒, 0 表示没有.
+type SentinelAdminClient interface {
+	ListAgents(ctx context.Context, in *ListAgentsReq, opts ...grpc.CallOption) (*AgentList, error)
+	GetAgent(ctx context.Context, in *AgentReq, opts ...grpc.CallOption) (*Agent, error)
+	SelectAgents(ctx context.Context, in *Selector, opts ...grpc.CallOption) (*AgentList, error)
+	GetAgentMetrics(ctx context.Context, in *AgentMetricsReq, opts ...grpc.CallOption) (*AgentMetricsResp, error)
+	GetEffectiveAgentConfig(ctx context.Context, in *AgentReq, opts ...grpc.CallOption) (*AgentConfig, error)
+	ResetAgentCredential(ctx context.Context, in *AgentReq, opts ...grpc.CallOption) (*Agent, error)
+	SubmitJob(ctx context.Context, in *SubmitJobReq, opts ...grpc.CallOption) (*SubmitJobResp, error)
+	CancelJob(ctx context.Context, in *JobReq, opts ...grpc.CallOption) (*JobInfo, error)
+	ApproveJob(ctx context.Context, in *JobReq, opts ...grpc.CallOption) (*JobInfo, error)
+	GetJob(ctx context.Context, in *JobReq, opts ...grpc.CallOption) (*JobInfo, error)
+	GetJobOutput(ctx context.Context, in *JobOutputReq, opts ...grpc.CallOption) (*JobOutputResp, error)
+	ListJobs(ctx context.Context, in *ListJobsReq, opts ...grpc.CallOption) (*ListJobsResp, error)
+	SearchJobs(ctx context.Context, in *SearchJobsReq, opts ...grpc.CallOption) (*SearchJobsResp, error)
+	ListAgentConfigRules(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AgentConfigRuleList, error)
+	PutAgentConfigRule(ctx context.Context, in *AgentConfigRule, opts ...grpc.CallOption) (*AgentConfigRule, error)
+	DeleteAgentConfigRule(ctx context.Context, in *ScopeTarget, opts ...grpc.CallOption) (*Empty, error)
+	// 第一条消息只带 meta, 之后每条带一段二进制
+	UploadAgentRelease(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAgentReleaseReq, AgentRelease], error)
+	ListAgentReleases(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AgentReleaseList, error)
+	RolloutAgentRelease(ctx context.Context, in *RolloutReq, opts ...grpc.CallOption) (*RolloutResp, error)
+	ListApprovalRules(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ApprovalRuleList, error)
+	PutApprovalRule(ctx context.Context, in *ApprovalRule, opts ...grpc.CallOption) (*ApprovalRule, error)
+	DeleteApprovalRule(ctx context.Context, in *NameReq, opts ...grpc.CallOption) (*Empty, error)
+	ListSecrets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SecretList, error)
+	PutSecret(ctx context.Context, in *PutSecretReq, opts ...grpc.CallOption) (*SecretInfo, error)
+	DeleteSecret(ctx context.Context, in *NameReq, opts ...grpc.CallOption) (*Empty, error)
+	ListUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserList, error)
+	CreateUser(ctx context.Context, in *CreateUserReq, opts ...grpc.CallOption) (*CreateUserResp, error)
+	DeleteUser(ctx context.Context, in *NameReq, opts ...grpc.CallOption) (*Empty, error)
+	ListProjects(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ProjectList, error)
+	CreateProject(ctx context.Context, in *CreateProjectReq, opts ...grpc.CallOption) (*CreateProjectResp, error)
+	DeleteProject(ctx context.Context, in *ProjectReq, opts ...grpc.CallOption) (*Empty, error)
+	RotateEnrollmentToken(ctx context.Context, in *ProjectReq, opts ...grpc.CallOption) (*EnrollmentToken, error)
+	ListMembers(ctx context.Context, in *ProjectReq, opts ...grpc.CallOption) (*MemberList, error)
+	PutMember(ctx context.Context, in *Member, opts ...grpc.CallOption) (*Member, error)
+	DeleteMember(ctx context.Context, in *Member, opts ...grpc.CallOption) (*Empty, error)
+	GetQuotaUsage(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*QuotaUsageResp, error)
+	ListQuotas(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*QuotaList, error)
+	PutQuota(ctx context.Context, in *Quota, opts ...grpc.CallOption) (*Quota, error)
+	DeleteQuota(ctx context.Context, in *ScopeTarget, opts ...grpc.CallOption) (*Empty, error)
+	QueryAudit(ctx context.Context, in *AuditFilter, opts ...grpc.CallOption) (*AuditPage, error)
+	ExportAudit(ctx context.Context, in *AuditFilter, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AuditEntry], error)
+	VerifyAudit(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AuditVerifyResult, error)
+}
+
+type sentinelAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSentinelAdminClient(cc grpc.ClientConnInterface) SentinelAdminClient {
+	return &sentinelAdminClient{cc}
+}
+
+func (c *sentinelAdminClient) ListAgents(ctx context.Context, in *ListAgentsReq, opts ...grpc.CallOption) (*AgentList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListAgents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) GetAgent(ctx context.Context, in *AgentReq, opts ...grpc.CallOption) (*Agent, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Agent)
+	err := c.cc.Invoke(ctx, SentinelAdmin_GetAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) SelectAgents(ctx context.Context, in *Selector, opts ...grpc.CallOption) (*AgentList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_SelectAgents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) GetAgentMetrics(ctx context.Context, in *AgentMetricsReq, opts ...grpc.CallOption) (*AgentMetricsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentMetricsResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_GetAgentMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) GetEffectiveAgentConfig(ctx context.Context, in *AgentReq, opts ...grpc.CallOption) (*AgentConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentConfig)
+	err := c.cc.Invoke(ctx, SentinelAdmin_GetEffectiveAgentConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ResetAgentCredential(ctx context.Context, in *AgentReq, opts ...grpc.CallOption) (*Agent, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Agent)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ResetAgentCredential_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) SubmitJob(ctx context.Context, in *SubmitJobReq, opts ...grpc.CallOption) (*SubmitJobResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitJobResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_SubmitJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) CancelJob(ctx context.Context, in *JobReq, opts ...grpc.CallOption) (*JobInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobInfo)
+	err := c.cc.Invoke(ctx, SentinelAdmin_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ApproveJob(ctx context.Context, in *JobReq, opts ...grpc.CallOption) (*JobInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobInfo)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ApproveJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) GetJob(ctx context.Context, in *JobReq, opts ...grpc.CallOption) (*JobInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobInfo)
+	err := c.cc.Invoke(ctx, SentinelAdmin_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) GetJobOutput(ctx context.Context, in *JobOutputReq, opts ...grpc.CallOption) (*JobOutputResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobOutputResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_GetJobOutput_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListJobs(ctx context.Context, in *ListJobsReq, opts ...grpc.CallOption) (*ListJobsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) SearchJobs(ctx context.Context, in *SearchJobsReq, opts ...grpc.CallOption) (*SearchJobsResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchJobsResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_SearchJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListAgentConfigRules(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AgentConfigRuleList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentConfigRuleList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListAgentConfigRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) PutAgentConfigRule(ctx context.Context, in *AgentConfigRule, opts ...grpc.CallOption) (*AgentConfigRule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentConfigRule)
+	err := c.cc.Invoke(ctx, SentinelAdmin_PutAgentConfigRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) DeleteAgentConfigRule(ctx context.Context, in *ScopeTarget, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SentinelAdmin_DeleteAgentConfigRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) UploadAgentRelease(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAgentReleaseReq, AgentRelease], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SentinelAdmin_ServiceDesc.Streams[0], SentinelAdmin_UploadAgentRelease_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadAgentReleaseReq, AgentRelease]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelAdmin_UploadAgentReleaseClient = grpc.ClientStreamingClient[UploadAgentReleaseReq, AgentRelease]
+
+func (c *sentinelAdminClient) ListAgentReleases(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AgentReleaseList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AgentReleaseList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListAgentReleases_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) RolloutAgentRelease(ctx context.Context, in *RolloutReq, opts ...grpc.CallOption) (*RolloutResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RolloutResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_RolloutAgentRelease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListApprovalRules(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ApprovalRuleList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApprovalRuleList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListApprovalRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) PutApprovalRule(ctx context.Context, in *ApprovalRule, opts ...grpc.CallOption) (*ApprovalRule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApprovalRule)
+	err := c.cc.Invoke(ctx, SentinelAdmin_PutApprovalRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) DeleteApprovalRule(ctx context.Context, in *NameReq, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SentinelAdmin_DeleteApprovalRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListSecrets(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SecretList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecretList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListSecrets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) PutSecret(ctx context.Context, in *PutSecretReq, opts ...grpc.CallOption) (*SecretInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SecretInfo)
+	err := c.cc.Invoke(ctx, SentinelAdmin_PutSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) DeleteSecret(ctx context.Context, in *NameReq, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SentinelAdmin_DeleteSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListUsers(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*UserList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) CreateUser(ctx context.Context, in *CreateUserReq, opts ...grpc.CallOption) (*CreateUserResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateUserResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_CreateUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) DeleteUser(ctx context.Context, in *NameReq, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SentinelAdmin_DeleteUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListProjects(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ProjectList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProjectList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListProjects_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) CreateProject(ctx context.Context, in *CreateProjectReq, opts ...grpc.CallOption) (*CreateProjectResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateProjectResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_CreateProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) DeleteProject(ctx context.Context, in *ProjectReq, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SentinelAdmin_DeleteProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) RotateEnrollmentToken(ctx context.Context, in *ProjectReq, opts ...grpc.CallOption) (*EnrollmentToken, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollmentToken)
+	err := c.cc.Invoke(ctx, SentinelAdmin_RotateEnrollmentToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListMembers(ctx context.Context, in *ProjectReq, opts ...grpc.CallOption) (*MemberList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MemberList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) PutMember(ctx context.Context, in *Member, opts ...grpc.CallOption) (*Member, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Member)
+	err := c.cc.Invoke(ctx, SentinelAdmin_PutMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) DeleteMember(ctx context.Context, in *Member, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SentinelAdmin_DeleteMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) GetQuotaUsage(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*QuotaUsageResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuotaUsageResp)
+	err := c.cc.Invoke(ctx, SentinelAdmin_GetQuotaUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ListQuotas(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*QuotaList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuotaList)
+	err := c.cc.Invoke(ctx, SentinelAdmin_ListQuotas_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) PutQuota(ctx context.Context, in *Quota, opts ...grpc.CallOption) (*Quota, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quota)
+	err := c.cc.Invoke(ctx, SentinelAdmin_PutQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) DeleteQuota(ctx context.Context, in *ScopeTarget, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SentinelAdmin_DeleteQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) QueryAudit(ctx context.Context, in *AuditFilter, opts ...grpc.CallOption) (*AuditPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditPage)
+	err := c.cc.Invoke(ctx, SentinelAdmin_QueryAudit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelAdminClient) ExportAudit(ctx context.Context, in *AuditFilter, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AuditEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SentinelAdmin_ServiceDesc.Streams[1], SentinelAdmin_ExportAudit_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AuditFilter, AuditEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelAdmin_ExportAuditClient = grpc.ServerStreamingClient[AuditEntry]
+
+func (c *sentinelAdminClient) VerifyAudit(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AuditVerifyResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditVerifyResult)
+	err := c.cc.Invoke(ctx, SentinelAdmin_VerifyAudit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SentinelAdminServer is the server API for SentinelAdmin service.
+// All implementations must embed UnimplementedSentinelAdminServer
+// for forward compatibility.
+//
+// SentinelAdmin 管理接口, 能力和 HTTP API 一致, 两边调用同一套服务层.
+// metadata 里带 authorization: Bearer <token>, 所需角色和对应的 HTTP 路由相同.
+// Agent / 任务 / 配额用量作用于 x-sentinel-project 指定的项目 (缺省 default); 带 project 字段的请求以字段为准.
+// 时间都是 unix 秒, 0 表示没有.
+type SentinelAdminServer interface {
+	ListAgents(context.Context, *ListAgentsReq) (*AgentList, error)
+	GetAgent(context.Context, *AgentReq) (*Agent, error)
+	SelectAgents(context.Context, *Selector) (*AgentList, error)
+	GetAgentMetrics(context.Context, *AgentMetricsReq) (*AgentMetricsResp, error)
+	GetEffectiveAgentConfig(context.Context, *AgentReq) (*AgentConfig, error)
+	ResetAgentCredential(context.Context, *AgentReq) (*Agent, error)
+	SubmitJob(context.Context, *SubmitJobReq) (*SubmitJobResp, error)
+	CancelJob(context.Context, *JobReq) (*JobInfo, error)
+	ApproveJob(context.Context, *JobReq) (*JobInfo, error)
+	GetJob(context.Context, *JobReq) (*JobInfo, error)
+	GetJobOutput(context.Context, *JobOutputReq) (*JobOutputResp, error)
+	ListJobs(context.Context, *ListJobsReq) (*ListJobsResp, error)
+	SearchJobs(context.Context, *SearchJobsReq) (*SearchJobsResp, error)
+	ListAgentConfigRules(context.Context, *Empty) (*AgentConfigRuleList, error)
+	PutAgentConfigRule(context.Context, *AgentConfigRule) (*AgentConfigRule, error)
+	DeleteAgentConfigRule(context.Context, *ScopeTarget) (*Empty, error)
+	// 第一条消息只带 meta, 之后每条带一段二进制
+	UploadAgentRelease(grpc.ClientStreamingServer[UploadAgentReleaseReq, AgentRelease]) error
+	ListAgentReleases(context.Context, *Empty) (*AgentReleaseList, error)
+	RolloutAgentRelease(context.Context, *RolloutReq) (*RolloutResp, error)
+	ListApprovalRules(context.Context, *Empty) (*ApprovalRuleList, error)
+	PutApprovalRule(context.Context, *ApprovalRule) (*ApprovalRule, error)
+	DeleteApprovalRule(context.Context, *NameReq) (*Empty, error)
+	ListSecrets(context.Context, *Empty) (*SecretList, error)
+	PutSecret(context.Context, *PutSecretReq) (*SecretInfo, error)
+	DeleteSecret(context.Context, *NameReq) (*Empty, error)
+	ListUsers(context.Context, *Empty) (*UserList, error)
+	CreateUser(context.Context, *CreateUserReq) (*CreateUserResp, error)
+	DeleteUser(context.Context, *NameReq) (*Empty, error)
+	ListProjects(context.Context, *Empty) (*ProjectList, error)
+	CreateProject(context.Context, *CreateProjectReq) (*CreateProjectResp, error)
+	DeleteProject(context.Context, *ProjectReq) (*Empty, error)
+	RotateEnrollmentToken(context.Context, *ProjectReq) (*EnrollmentToken, error)
+	ListMembers(context.Context, *ProjectReq) (*MemberList, error)
+	PutMember(context.Context, *Member) (*Member, error)
+	DeleteMember(context.Context, *Member) (*Empty, error)
+	GetQuotaUsage(context.Context, *Empty) (*QuotaUsageResp, error)
+	ListQuotas(context.Context, *Empty) (*QuotaList, error)
+	PutQuota(context.Context, *Quota) (*Quota, error)
+	DeleteQuota(context.Context, *ScopeTarget) (*Empty, error)
+	QueryAudit(context.Context, *AuditFilter) (*AuditPage, error)
+	ExportAudit(*AuditFilter, grpc.ServerStreamingServer[AuditEntry]) error
+	VerifyAudit(context.Context, *Empty) (*AuditVerifyResult, error)
+	mustEmbedUnimplementedSentinelAdminServer()
+}
+
+// UnimplementedSentinelAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSentinelAdminServer struct{}
+
+func (UnimplementedSentinelAdminServer) ListAgents(context.Context, *ListAgentsReq) (*AgentList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgents not implemented")
+}
+func (UnimplementedSentinelAdminServer) GetAgent(context.Context, *AgentReq) (*Agent, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAgent not implemented")
+}
+func (UnimplementedSentinelAdminServer) SelectAgents(context.Context, *Selector) (*AgentList, error) {
+	return nil, status.Error(codes.Unimplemented, "method SelectAgents not implemented")
+}
+func (UnimplementedSentinelAdminServer) GetAgentMetrics(context.Context, *AgentMetricsReq) (*AgentMetricsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAgentMetrics not implemented")
+}
+func (UnimplementedSentinelAdminServer) GetEffectiveAgentConfig(context.Context, *AgentReq) (*AgentConfig, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetEffectiveAgentConfig not implemented")
+}
+func (UnimplementedSentinelAdminServer) ResetAgentCredential(context.Context, *AgentReq) (*Agent, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResetAgentCredential not implemented")
+}
+func (UnimplementedSentinelAdminServer) SubmitJob(context.Context, *SubmitJobReq) (*SubmitJobResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitJob not implemented")
+}
+func (UnimplementedSentinelAdminServer) CancelJob(context.Context, *JobReq) (*JobInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedSentinelAdminServer) ApproveJob(context.Context, *JobReq) (*JobInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApproveJob not implemented")
+}
+func (UnimplementedSentinelAdminServer) GetJob(context.Context, *JobReq) (*JobInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedSentinelAdminServer) GetJobOutput(context.Context, *JobOutputReq) (*JobOutputResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetJobOutput not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListJobs(context.Context, *ListJobsReq) (*ListJobsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListJobs not implemented")
+}
+func (UnimplementedSentinelAdminServer) SearchJobs(context.Context, *SearchJobsReq) (*SearchJobsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method SearchJobs not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListAgentConfigRules(context.Context, *Empty) (*AgentConfigRuleList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgentConfigRules not implemented")
+}
+func (UnimplementedSentinelAdminServer) PutAgentConfigRule(context.Context, *AgentConfigRule) (*AgentConfigRule, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutAgentConfigRule not implemented")
+}
+func (UnimplementedSentinelAdminServer) DeleteAgentConfigRule(context.Context, *ScopeTarget) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteAgentConfigRule not implemented")
+}
+func (UnimplementedSentinelAdminServer) UploadAgentRelease(grpc.ClientStreamingServer[UploadAgentReleaseReq, AgentRelease]) error {
+	return status.Error(codes.Unimplemented, "method UploadAgentRelease not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListAgentReleases(context.Context, *Empty) (*AgentReleaseList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAgentReleases not implemented")
+}
+func (UnimplementedSentinelAdminServer) RolloutAgentRelease(context.Context, *RolloutReq) (*RolloutResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method RolloutAgentRelease not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListApprovalRules(context.Context, *Empty) (*ApprovalRuleList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListApprovalRules not implemented")
+}
+func (UnimplementedSentinelAdminServer) PutApprovalRule(context.Context, *ApprovalRule) (*ApprovalRule, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutApprovalRule not implemented")
+}
+func (UnimplementedSentinelAdminServer) DeleteApprovalRule(context.Context, *NameReq) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteApprovalRule not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListSecrets(context.Context, *Empty) (*SecretList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSecrets not implemented")
+}
+func (UnimplementedSentinelAdminServer) PutSecret(context.Context, *PutSecretReq) (*SecretInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutSecret not implemented")
+}
+func (UnimplementedSentinelAdminServer) DeleteSecret(context.Context, *NameReq) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteSecret not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListUsers(context.Context, *Empty) (*UserList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedSentinelAdminServer) CreateUser(context.Context, *CreateUserReq) (*CreateUserResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedSentinelAdminServer) DeleteUser(context.Context, *NameReq) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteUser not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListProjects(context.Context, *Empty) (*ProjectList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListProjects not implemented")
+}
+func (UnimplementedSentinelAdminServer) CreateProject(context.Context, *CreateProjectReq) (*CreateProjectResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateProject not implemented")
+}
+func (UnimplementedSentinelAdminServer) DeleteProject(context.Context, *ProjectReq) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteProject not implemented")
+}
+func (UnimplementedSentinelAdminServer) RotateEnrollmentToken(context.Context, *ProjectReq) (*EnrollmentToken, error) {
+	return nil, status.Error(codes.Unimplemented, "method RotateEnrollmentToken not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListMembers(context.Context, *ProjectReq) (*MemberList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMembers not implemented")
+}
+func (UnimplementedSentinelAdminServer) PutMember(context.Context, *Member) (*Member, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutMember not implemented")
+}
+func (UnimplementedSentinelAdminServer) DeleteMember(context.Context, *Member) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteMember not implemented")
+}
+func (UnimplementedSentinelAdminServer) GetQuotaUsage(context.Context, *Empty) (*QuotaUsageResp, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetQuotaUsage not implemented")
+}
+func (UnimplementedSentinelAdminServer) ListQuotas(context.Context, *Empty) (*QuotaList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListQuotas not implemented")
+}
+func (UnimplementedSentinelAdminServer) PutQuota(context.Context, *Quota) (*Quota, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutQuota not implemented")
+}
+func (UnimplementedSentinelAdminServer) DeleteQuota(context.Context, *ScopeTarget) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteQuota not implemented")
+}
+func (UnimplementedSentinelAdminServer) QueryAudit(context.Context, *AuditFilter) (*AuditPage, error) {
+	return nil, status.Error(codes.Unimplemented, "method QueryAudit not implemented")
+}
+func (UnimplementedSentinelAdminServer) ExportAudit(*AuditFilter, grpc.ServerStreamingServer[AuditEntry]) error {
+	return status.Error(codes.Unimplemented, "method ExportAudit not implemented")
+}
+func (UnimplementedSentinelAdminServer) VerifyAudit(context.Context, *Empty) (*AuditVerifyResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method VerifyAudit not implemented")
+}
+func (UnimplementedSentinelAdminServer) mustEmbedUnimplementedSentinelAdminServer() {}
+func (UnimplementedSentinelAdminServer) testEmbeddedByValue()                       {}
+
+// UnsafeSentinelAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SentinelAdminServer will
+// result in compilation errors.
+type UnsafeSentinelAdminServer interface {
+	mustEmbedUnimplementedSentinelAdminServer()
+}
+
+func RegisterSentinelAdminServer(s grpc.ServiceRegistrar, srv SentinelAdminServer) {
+	// If the following call panics, it indicates UnimplementedSentinelAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SentinelAdmin_ServiceDesc, srv)
+}
+
+func _SentinelAdmin_ListAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAgentsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListAgents(ctx, req.(*ListAgentsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_GetAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgentReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).GetAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_GetAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).GetAgent(ctx, req.(*AgentReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_SelectAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Selector)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).SelectAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_SelectAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).SelectAgents(ctx, req.(*Selector))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_GetAgentMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgentMetricsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).GetAgentMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_GetAgentMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).GetAgentMetrics(ctx, req.(*AgentMetricsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_GetEffectiveAgentConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgentReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).GetEffectiveAgentConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_GetEffectiveAgentConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).GetEffectiveAgentConfig(ctx, req.(*AgentReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ResetAgentCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgentReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ResetAgentCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ResetAgentCredential_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ResetAgentCredential(ctx, req.(*AgentReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_SubmitJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitJobReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).SubmitJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_SubmitJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).SubmitJob(ctx, req.(*SubmitJobReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).CancelJob(ctx, req.(*JobReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ApproveJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ApproveJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ApproveJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ApproveJob(ctx, req.(*JobReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).GetJob(ctx, req.(*JobReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_GetJobOutput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobOutputReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).GetJobOutput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_GetJobOutput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).GetJobOutput(ctx, req.(*JobOutputReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListJobs(ctx, req.(*ListJobsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_SearchJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchJobsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).SearchJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_SearchJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).SearchJobs(ctx, req.(*SearchJobsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListAgentConfigRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListAgentConfigRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListAgentConfigRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListAgentConfigRules(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_PutAgentConfigRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AgentConfigRule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).PutAgentConfigRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_PutAgentConfigRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).PutAgentConfigRule(ctx, req.(*AgentConfigRule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_DeleteAgentConfigRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScopeTarget)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).DeleteAgentConfigRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_DeleteAgentConfigRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).DeleteAgentConfigRule(ctx, req.(*ScopeTarget))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_UploadAgentRelease_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SentinelAdminServer).UploadAgentRelease(&grpc.GenericServerStream[UploadAgentReleaseReq, AgentRelease]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelAdmin_UploadAgentReleaseServer = grpc.ClientStreamingServer[UploadAgentReleaseReq, AgentRelease]
+
+func _SentinelAdmin_ListAgentReleases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListAgentReleases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListAgentReleases_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListAgentReleases(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_RolloutAgentRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RolloutReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).RolloutAgentRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_RolloutAgentRelease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).RolloutAgentRelease(ctx, req.(*RolloutReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListApprovalRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListApprovalRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListApprovalRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListApprovalRules(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_PutApprovalRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApprovalRule)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).PutApprovalRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_PutApprovalRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).PutApprovalRule(ctx, req.(*ApprovalRule))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_DeleteApprovalRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NameReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).DeleteApprovalRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_DeleteApprovalRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).DeleteApprovalRule(ctx, req.(*NameReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListSecrets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListSecrets(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_PutSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutSecretReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).PutSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_PutSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).PutSecret(ctx, req.(*PutSecretReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_DeleteSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NameReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).DeleteSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_DeleteSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).DeleteSecret(ctx, req.(*NameReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListUsers(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).CreateUser(ctx, req.(*CreateUserReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NameReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).DeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_DeleteUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).DeleteUser(ctx, req.(*NameReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListProjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListProjects_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListProjects(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_CreateProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProjectReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).CreateProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_CreateProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).CreateProject(ctx, req.(*CreateProjectReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_DeleteProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProjectReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).DeleteProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_DeleteProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).DeleteProject(ctx, req.(*ProjectReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_RotateEnrollmentToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProjectReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).RotateEnrollmentToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_RotateEnrollmentToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).RotateEnrollmentToken(ctx, req.(*ProjectReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProjectReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListMembers(ctx, req.(*ProjectReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_PutMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Member)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).PutMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_PutMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).PutMember(ctx, req.(*Member))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_DeleteMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Member)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).DeleteMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_DeleteMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).DeleteMember(ctx, req.(*Member))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_GetQuotaUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).GetQuotaUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_GetQuotaUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).GetQuotaUsage(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ListQuotas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).ListQuotas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_ListQuotas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).ListQuotas(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_PutQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Quota)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).PutQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_PutQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).PutQuota(ctx, req.(*Quota))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_DeleteQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScopeTarget)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).DeleteQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_DeleteQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).DeleteQuota(ctx, req.(*ScopeTarget))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_QueryAudit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).QueryAudit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_QueryAudit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).QueryAudit(ctx, req.(*AuditFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelAdmin_ExportAudit_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AuditFilter)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SentinelAdminServer).ExportAudit(m, &grpc.GenericServerStream[AuditFilter, AuditEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SentinelAdmin_ExportAuditServer = grpc.ServerStreamingServer[AuditEntry]
+
+func _SentinelAdmin_VerifyAudit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelAdminServer).VerifyAudit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelAdmin_VerifyAudit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelAdminServer).VerifyAudit(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SentinelAdmin_ServiceDesc is the grpc.ServiceDesc for SentinelAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SentinelAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sentinel.SentinelAdmin",
+	HandlerType: (*SentinelAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAgents",
+			Handler:    _SentinelAdmin_ListAgents_Handler,
+		},
+		{
+			MethodName: "GetAgent",
+			Handler:    _SentinelAdmin_GetAgent_Handler,
+		},
+		{
+			MethodName: "SelectAgents",
+			Handler:    _SentinelAdmin_SelectAgents_Handler,
+		},
+		{
+			MethodName: "GetAgentMetrics",
+			Handler:    _SentinelAdmin_GetAgentMetrics_Handler,
+		},
+		{
+			MethodName: "GetEffectiveAgentConfig",
+			Handler:    _SentinelAdmin_GetEffectiveAgentConfig_Handler,
+		},
+		{
+			MethodName: "ResetAgentCredential",
+			Handler:    _SentinelAdmin_ResetAgentCredential_Handler,
+		},
+		{
+			MethodName: "SubmitJob",
+			Handler:    _SentinelAdmin_SubmitJob_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _SentinelAdmin_CancelJob_Handler,
+		},
+		{
+			MethodName: "ApproveJob",
+			Handler:    _SentinelAdmin_ApproveJob_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _SentinelAdmin_GetJob_Handler,
+		},
+		{
+			MethodName: "GetJobOutput",
+			Handler:    _SentinelAdmin_GetJobOutput_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _SentinelAdmin_ListJobs_Handler,
+		},
+		{
+			MethodName: "SearchJobs",
+			Handler:    _SentinelAdmin_SearchJobs_Handler,
+		},
+		{
+			MethodName: "ListAgentConfigRules",
+			Handler:    _SentinelAdmin_ListAgentConfigRules_Handler,
+		},
+		{
+			MethodName: "PutAgentConfigRule",
+			Handler:    _SentinelAdmin_PutAgentConfigRule_Handler,
+		},
+		{
+			MethodName: "DeleteAgentConfigRule",
+			Handler:    _SentinelAdmin_DeleteAgentConfigRule_Handler,
+		},
+		{
+			MethodName: "ListAgentReleases",
+			Handler:    _SentinelAdmin_ListAgentReleases_Handler,
+		},
+		{
+			MethodName: "RolloutAgentRelease",
+			Handler:    _SentinelAdmin_RolloutAgentRelease_Handler,
+		},
+		{
+			MethodName: "ListApprovalRules",
+			Handler:    _SentinelAdmin_ListApprovalRules_Handler,
+		},
+		{
+			MethodName: "PutApprovalRule",
+			Handler:    _SentinelAdmin_PutApprovalRule_Handler,
+		},
+		{
+			MethodName: "DeleteApprovalRule",
+			Handler:    _SentinelAdmin_DeleteApprovalRule_Handler,
+		},
+		{
+			MethodName: "ListSecrets",
+			Handler:    _SentinelAdmin_ListSecrets_Handler,
+		},
+		{
+			MethodName: "PutSecret",
+			Handler:    _SentinelAdmin_PutSecret_Handler,
+		},
+		{
+			MethodName: "DeleteSecret",
+			Handler:    _SentinelAdmin_DeleteSecret_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _SentinelAdmin_ListUsers_Handler,
+		},
+		{
+			MethodName: "CreateUser",
+			Handler:    _SentinelAdmin_CreateUser_Handler,
+		},
+		{
+			MethodName: "DeleteUser",
+			Handler:    _SentinelAdmin_DeleteUser_Handler,
+		},
+		{
+			MethodName: "ListProjects",
+			Handler:    _SentinelAdmin_ListProjects_Handler,
+		},
+		{
+			MethodName: "CreateProject",
+			Handler:    _SentinelAdmin_CreateProject_Handler,
+		},
+		{
+			MethodName: "DeleteProject",
+			Handler:    _SentinelAdmin_DeleteProject_Handler,
+		},
+		{
+			MethodName: "RotateEnrollmentToken",
+			Handler:    _SentinelAdmin_RotateEnrollmentToken_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _SentinelAdmin_ListMembers_Handler,
+		},
+		{
+			MethodName: "PutMember",
+			Handler:    _SentinelAdmin_PutMember_Handler,
+		},
+		{
+			MethodName: "DeleteMember",
+			Handler:    _SentinelAdmin_DeleteMember_Handler,
+		},
+		{
+			MethodName: "GetQuotaUsage",
+			Handler:    _SentinelAdmin_GetQuotaUsage_Handler,
+		},
+		{
+			MethodName: "ListQuotas",
+			Handler:    _SentinelAdmin_ListQuotas_Handler,
+		},
+		{
+			MethodName: "PutQuota",
+			Handler:    _SentinelAdmin_PutQuota_Handler,
+		},
+		{
+			MethodName: "DeleteQuota",
+			Handler:    _SentinelAdmin_DeleteQuota_Handler,
+		},
+		{
+			MethodName: "QueryAudit",
+			Handler:    _SentinelAdmin_QueryAudit_Handler,
+		},
+		{
+			MethodName: "VerifyAudit",
+			Handler:    _SentinelAdmin_VerifyAudit_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadAgentRelease",
+			Handler:       _SentinelAdmin_UploadAgentRelease_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportAudit",
+			Handler:       _SentinelAdmin_ExportAudit_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/proto/sentinel.proto",
 }
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/scan"
+	"github.com/stywzn/Go-Cloud-Compute/internal/server"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/db"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
+)
+
+func main() {
+	cfg := config.MustLoad(config.ComponentAPIServer)
+	db.Init()
+
+	broker, err := scan.BrokerFromConfig(cfg.RabbitMQ)
+	if err != nil {
+		log.Fatalf("Failed to connect to broker: %v", err)
+	}
+	defer broker.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 没有 RabbitMQ 时消息只在本进程内流转, 必须在同一进程里跑 Worker
+	if _, ok := broker.(*mq.MemoryBroker); ok {
+		log.Println("未配置 rabbitmq.url / rabbitmq.host, 使用进程内队列并内嵌 Worker Pool")
+		worker := scan.WorkerFromConfig(cfg, &scan.Worker{DB: db.DB, Broker: broker})
+		go worker.Run(ctx)
+	}
+
+	api := &scan.API{DB: db.DB, Broker: broker, Queue: cfg.RabbitMQ.QueueName}
+	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("server.trusted_proxies 配置错误: %v", err)
+	}
+	// 和管理 API 共用用户: 提交扫描要 operator, 查询结果要 viewer
+	api.Register(r, server.RequireRole(db.DB, server.RoleOperator), server.RequireRole(db.DB, server.RoleViewer))
+
+	log.Printf("API Server 已启动 | 监听端口 %s", cfg.Server.HTTPAddr)
+	go func() {
+		if err := r.Run(cfg.Server.HTTPAddr); err != nil {
+			log.Fatalf("HTTP 服务启动失败: %v", err)
+		}
+	}()
+	<-ctx.Done()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/scan"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/db"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
+)
+
+func main() {
+	cfg := config.MustLoad(config.ComponentScanWorker)
+	db.Init()
+
+	broker, err := scan.BrokerFromConfig(cfg.RabbitMQ)
+	if err != nil {
+		log.Fatalf("Failed to connect to broker: %v", err)
+	}
+	defer broker.Close()
+	if _, ok := broker.(*mq.MemoryBroker); ok {
+		log.Fatal("scan-worker 需要配置 rabbitmq.url 或 rabbitmq.host, 进程内队列请直接使用 api-server")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	worker := scan.WorkerFromConfig(cfg, &scan.Worker{DB: db.DB, Broker: broker})
+	if err := worker.Run(ctx); err != nil {
+		log.Fatalf("Worker 退出: %v", err)
+	}
+	log.Println("Worker 已停止")
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/ctl"
+)
+
+func main() {
+	os.Exit(ctl.Main(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -1,55 +1,237 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
+	"google.golang.org/grpc/credentials"
 
 	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/audit"
+	"github.com/stywzn/Go-Cloud-Compute/internal/cluster"
+	"github.com/stywzn/Go-Cloud-Compute/internal/envelope"
+	"github.com/stywzn/Go-Cloud-Compute/internal/events"
+	"github.com/stywzn/Go-Cloud-Compute/internal/redact"
 	"github.com/stywzn/Go-Cloud-Compute/internal/server"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+	dbpkg "github.com/stywzn/Go-Cloud-Compute/pkg/db"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/jobsign"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
 )
 
 func main() {
+	cfg := config.MustLoad(config.ComponentServer)
 
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "127.0.0.1"
-	}
-	dsn := fmt.Sprintf("root:root@tcp(%s:3306)/cloud_compute?charset=utf8mb4&parseTime=True&loc=Local", dbHost)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := dbpkg.Open(cfg.Database)
 	if err != nil {
 		log.Fatalf(" 无法连接数据库: %v", err)
 	}
 	log.Println(" 数据库连接成功!")
 
-	err = db.AutoMigrate(&server.AgentModel{}, &server.JobRecord{})
+	err = db.AutoMigrate(&server.AgentModel{}, &server.JobRecord{}, &server.AgentConfigModel{}, &server.AgentRelease{},
+		&server.User{}, &server.Project{}, &server.ProjectMember{}, &server.ApprovalRule{}, &server.Secret{}, &server.QuotaModel{}, &server.QuotaLock{}, &server.JobTerm{}, &server.JobOutputChunk{}, &server.AgentMetric{},
+		&audit.Entry{}, &audit.Head{}, &envelope.DataKey{}, &cluster.LeaderLease{}, &cluster.LeaderFence{})
 	if err != nil {
 		log.Fatalf(" 自动建表失败: %v", err)
 	}
-	log.Println("表结构同步完成 (AgentModel + JobRecord)")
+	log.Println("表结构同步完成 (AgentModel + JobRecord + AgentConfig + AgentRelease + LeaderLease)")
+
+	// 请求 context 里带了项目时, Agent / 任务表的读写自动限定在该项目内
+	if err := server.RegisterProjectScope(db); err != nil {
+		log.Fatalf(" 注册项目隔离回调失败: %v", err)
+	}
+
+	keyring, err := envelope.FromConfig(db, cfg.Server.Encryption)
+	if err != nil {
+		log.Fatalf(" 加载 KEK 失败: %v", err)
+	}
+	envelope.Use(keyring)
+	if keyring != nil {
+		log.Printf("任务 payload / result 加密已启用 | KEK %s", keyring.PrimaryID())
+	}
+
+	// server rotate-keys: 轮换 KEK 后执行一次, 做完就退出
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		rotateKeys(ctx, db, keyring)
+		return
+	}
+	// server reindex-search: 重建任务输出的搜索索引, 升级后或启用加密之后执行一次
+	if len(os.Args) > 1 && os.Args[1] == "reindex-search" {
+		n, err := server.ReindexJobs(ctx, db)
+		if err != nil {
+			log.Fatalf(" 重建搜索索引失败 (已完成 %d 条): %v", n, err)
+		}
+		log.Printf("已重建 %d 条任务的搜索索引", n)
+		return
+	}
 
-	s := grpc.NewServer()
-	srv := &server.SentinelServer{DB: db}
+	instanceID := cfg.Cluster.InstanceID
+	if instanceID == "" {
+		instanceID = cluster.DefaultInstanceID()
+	}
+
+	// 配了 redis.addr 就进入多实例模式, 否则单实例; 选主在单实例下也走 MySQL 租约
+	var node *cluster.Node
+	var leases cluster.LeaseStore = cluster.NewMySQLLeaseStore(db)
+	var rdb *redis.Client
+	if cfg.Redis.Addr != "" {
+		rdb, err = dbpkg.NewRedis(cfg.Redis)
+		if err != nil {
+			log.Fatalf(" 无法连接 Redis: %v", err)
+		}
+		node = cluster.NewRedisNode(rdb, instanceID, cfg.Cluster.OwnerTTL)
+		leases = cluster.NewRedisLeaseStore(rdb)
+		log.Printf("集群模式 | 实例 ID: %s | Redis: %s", instanceID, cfg.Redis.Addr)
+	} else {
+		node = cluster.NewLocalNode(instanceID)
+		log.Printf("单实例模式 | 实例 ID: %s", instanceID)
+	}
+
+	var grpcOpts []grpc.ServerOption
+	tlsCfg, err := cfg.Server.TLS.Load()
+	if err != nil {
+		log.Fatalf(" 加载 TLS 证书失败: %v", err)
+	}
+	if tlsCfg != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+		log.Println("gRPC / HTTP 已启用 TLS")
+	}
+
+	srv := server.NewSentinelServer(db, node)
+	srv.ReleaseDir = cfg.Server.AgentReleaseDir
+	srv.JobTTL = cfg.Server.JobTTL
+	srv.ApprovalTTL = cfg.Server.ApprovalTTL
+	srv.RequireEnrollment = cfg.Server.RequireEnroll
+	srv.UserQuota = quotaLimits(cfg.Server.Quotas.User)
+	srv.ProjectQuota = quotaLimits(cfg.Server.Quotas.Project)
+	if rdb != nil {
+		// 多实例共享每分钟任务数的计数, 否则每个实例各算各的
+		srv.RateLimiter = dbpkg.NewRateLimiter(rdb, "sentinel:quota:")
+		srv.OwnerTTL = cfg.Cluster.OwnerTTL
+	}
+	if err := srv.BootstrapAdmin(ctx, cfg.Server.BootstrapToken); err != nil {
+		log.Fatalf(" 创建初始管理员失败: %v", err)
+	}
+	if err := srv.EnsureDefaultProject(ctx); err != nil {
+		log.Fatalf(" 初始化 default 项目失败: %v", err)
+	}
+	if cfg.Server.JobSigningKey != "" {
+		key, err := jobsign.LoadPrivateKey(cfg.Server.JobSigningKey)
+		if err != nil {
+			log.Fatalf(" 加载任务签名私钥失败: %v", err)
+		}
+		srv.SigningKey = key
+		log.Printf("任务签名已启用 | 有效期 %s", cfg.Server.JobTTL)
+	} else {
+		log.Println("⚠️ 未配置 server.job_signing_key, 任务不签名; 配置了公钥的 Agent 会拒绝执行")
+	}
+	srv.Redactor, err = redact.NewPipeline(cfg.Server.Redaction.DisabledDetectors, cfg.Server.Redaction.Patterns)
+	if err != nil {
+		log.Fatalf(" 脱敏规则配置有误: %v", err)
+	}
+	if cfg.Server.SecretsKeyFile != "" {
+		key, err := server.LoadSecretsKey(cfg.Server.SecretsKeyFile)
+		if err != nil {
+			log.Fatalf(" 加载密钥库密钥失败: %v", err)
+		}
+		srv.SecretsKey = key
+		log.Println("密钥库已启用")
+	}
+	// SentinelAdmin 和 Agent 的 SentinelService 共用端口: 前者按用户 token 鉴权和记审计, 后者按 Agent credential 鉴权
+	admin := server.NewAdminServer(srv)
+	grpcOpts = append(grpcOpts,
+		grpc.ChainUnaryInterceptor(admin.UnaryInterceptor(), srv.AgentUnaryInterceptor()),
+		grpc.ChainStreamInterceptor(admin.StreamInterceptor(), srv.AgentStreamInterceptor()))
+	s := grpc.NewServer(grpcOpts...)
 	pb.RegisterSentinelServiceServer(s, srv)
+	pb.RegisterSentinelAdminServer(s, admin)
+
+	// 配了 rabbitmq.url / rabbitmq.host 就把生命周期事件发到 RabbitMQ, 否则使用进程内 broker, 不依赖任何外部服务
+	broker, err := mq.New(cfg.RabbitMQ.BrokerURL())
+	if err != nil {
+		log.Fatalf(" 无法连接消息队列: %v", err)
+	}
+	defer broker.Close()
+	pub, err := events.NewBrokerPublisher(ctx, broker, cfg.RabbitMQ.EventsExchange)
+	if err != nil {
+		log.Fatalf(" 声明事件 exchange 失败: %v", err)
+	}
+	srv.Events = events.NewEmitter(pub, instanceID)
+	log.Printf("事件总线已启用 | exchange: %s", cfg.RabbitMQ.EventsExchange)
+
+	elector := cluster.NewElector(leases, cluster.NewFence(db), "maintenance", instanceID, cfg.Cluster.LeaderTTL)
+	elector.Register("offline-sweeper", srv.OfflineSweeper(elector))
+	elector.Register("retention-purger", srv.RetentionPurger(elector, cfg.Server.JobRetention))
+	elector.Register("approval-expirer", srv.ApprovalExpirer(elector))
+	electorDone := elector.Run(ctx)
+
+	go func() {
+		if err := srv.ListenCluster(ctx); err != nil {
+			log.Fatalf("集群消息订阅失败: %v", err)
+		}
+	}()
 
+	httpSrv := server.NewHttpServer(db, srv)
 	go func() {
-		httpSrv := server.NewHttpServer(db, srv)
-		log.Println("HTTP Management API 已启动 | 监听端口 :8080")
-		httpSrv.Start()
+		log.Printf("HTTP Management API 已启动 | 监听端口 %s", cfg.Server.HTTPAddr)
+		if err := httpSrv.Start(cfg.Server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP 服务启动失败: %v", err)
+		}
 	}()
 
-	lis, err := net.Listen("tcp", ":9090")
+	lis, err := net.Listen("tcp", cfg.Server.GRPCAddr)
 	if err != nil {
 		log.Fatalf("端口监听失败: %v", err)
 	}
-	log.Println("Sentinel Control Plane 已启动 | 监听端口 :9090")
+	log.Printf("Sentinel Control Plane 已启动 | 监听端口 %s", cfg.Server.GRPCAddr)
+
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			log.Fatalf("gRPC 服务启动失败: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("收到退出信号, 最多等待 %s 完成收尾...", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	httpSrv.Shutdown(shutdownCtx)
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		// 心跳是长连接流, 等不完就强制断开
+		s.Stop()
+	}
+	// 等选举循环停掉 leader 任务并释放租约
+	select {
+	case <-electorDone:
+	case <-shutdownCtx.Done():
+		log.Println("等待 leader 任务退出超时")
+	}
+	log.Println("Sentinel Control Plane 已退出")
+}
 
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("gRPC 服务启动失败: %v", err)
+func quotaLimits(c config.QuotaConfig) server.QuotaLimits {
+	return server.QuotaLimits{
+		JobsPerMinute:     c.JobsPerMinute,
+		MaxConcurrentJobs: c.MaxConcurrentJobs,
+		MaxFanOut:         c.MaxFanOut,
 	}
 }
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/envelope"
+	"github.com/stywzn/Go-Cloud-Compute/internal/server"
+	"gorm.io/gorm"
+)
+
+// rotateKeys 用当前 KEK 重新包装所有旧 KEK 包装的数据密钥, 再把启用加密之前的明文任务记录加密.
+// 两步都可以重复执行; 完成后即可从配置里删掉 previous_keks / previous_kek_files.
+func rotateKeys(ctx context.Context, db *gorm.DB, keyring *envelope.Keyring) {
+	if keyring == nil {
+		log.Fatal(" 未配置 server.encryption.kek / kek_file, 无法轮换")
+	}
+	n, err := keyring.Rewrap(ctx)
+	if err != nil {
+		log.Fatalf(" 重新包装数据密钥失败 (已完成 %d 个): %v", n, err)
+	}
+	log.Printf("已用 KEK %s 重新包装 %d 个数据密钥", keyring.PrimaryID(), n)
+
+	n, err = server.EncryptLegacyJobs(ctx, db)
+	if err != nil {
+		log.Fatalf(" 加密历史任务记录失败 (已完成 %d 条): %v", n, err)
+	}
+	log.Printf("已加密 %d 条明文任务记录", n)
+}
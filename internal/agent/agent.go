@@ -0,0 +1,431 @@
+package agent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/redact"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/jobsign"
+)
+
+// Agent 维持到控制面的心跳流, 执行下发的任务, 并按服务端配置调整自己的运行参数
+type Agent struct {
+	cfg      config.AgentConfig
+	conn     *grpc.ClientConn
+	client   pb.SentinelServiceClient
+	hostname string
+
+	mu       sync.RWMutex
+	local    settings
+	current  settings
+	pool     *workerPool
+	policy   *Policy
+	sandbox  *Sandbox
+	pubKey   ed25519.PublicKey // 为空表示不校验签名
+	replay   *replayGuard
+	cred     *agentCredential
+	running  sync.Map // jobID -> context.CancelFunc
+	jobs     sync.WaitGroup
+	fetching atomic.Bool
+
+	// 自更新状态, 见 update.go
+	exe           string
+	trial         *updateState
+	updateFailure *updateFailure
+	updating      atomic.Bool
+	staged        *stagedUpdate
+	cancelSession context.CancelFunc
+}
+
+func New(cfg config.AgentConfig) (*Agent, error) {
+	local, err := localSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	setLogLevel(local.LogLevel)
+	policy, err := LoadPolicy(cfg.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PolicyFile != "" {
+		infof("🛡️ 已加载执行策略: %s", cfg.PolicyFile)
+	}
+	sandbox, err := NewSandbox(cfg.Sandbox)
+	if err != nil {
+		return nil, err
+	}
+	infof("📦 任务沙箱: %s", sandbox.Describe())
+
+	var pubKey ed25519.PublicKey
+	if cfg.JobPublicKey != "" {
+		if pubKey, err = jobsign.LoadPublicKey(cfg.JobPublicKey); err != nil {
+			return nil, err
+		}
+		infof("🔏 只执行经过签名的任务, 公钥: %s", cfg.JobPublicKey)
+	} else {
+		warnf("⚠️ 未配置 agent.job_public_key, 将执行任何下发的任务")
+	}
+	replay, err := loadReplayGuard(cfg.StateDir)
+	if err != nil {
+		return nil, fmt.Errorf("load replay state from %s: %w", cfg.StateDir, err)
+	}
+
+	hostname, _ := os.Hostname()
+	cred, err := loadCredential(cfg.StateDir, hostname)
+	if err != nil {
+		return nil, fmt.Errorf("load agent credential from %s: %w", cfg.StateDir, err)
+	}
+
+	// 自定义拨号器：强制使用 "tcp4" (IPv4)，彻底屏蔽 IPv6 问题
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		d := net.Dialer{}
+		return d.DialContext(ctx, "tcp4", addr)
+	}
+
+	creds := insecure.NewCredentials()
+	tlsCfg, err := cfg.TLS.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load tls config: %w", err)
+	}
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+		infof("🔒 已启用 TLS")
+	}
+
+	conn, err := grpc.NewClient(cfg.ServerAddr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(dialer),
+		grpc.WithPerRPCCredentials(cred),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Agent{
+		cfg:      cfg,
+		conn:     conn,
+		client:   pb.NewSentinelServiceClient(conn),
+		hostname: hostname,
+		local:    local,
+		current:  local,
+		pool:     newWorkerPool(local.WorkerPoolSize),
+		policy:   policy,
+		sandbox:  sandbox,
+		pubKey:   pubKey,
+		replay:   replay,
+		cred:     cred,
+	}, nil
+}
+
+// ReloadPolicy 重新读取策略文件, 失败时保留旧策略
+func (a *Agent) ReloadPolicy() error {
+	policy, err := LoadPolicy(a.cfg.PolicyFile)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.policy = policy
+	a.mu.Unlock()
+	infof("🛡️ 执行策略已重新加载")
+	return nil
+}
+
+func (a *Agent) Close() error {
+	return a.conn.Close()
+}
+
+func (a *Agent) settings() settings {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.current
+}
+
+func (a *Agent) currentPolicy() *Policy {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.policy
+}
+
+// Run 注册 + 心跳, 断线后按 reconnect_delay 重连, 直到 ctx 结束
+func (a *Agent) Run(ctx context.Context) error {
+	infof("🔌 准备连接 Server 地址: %s | 版本: %s", a.cfg.ServerAddr, Version)
+	a.resumeUpdate()
+	for {
+		infof("Agent [%s] 正在向控制面注册...", a.hostname)
+		req := &pb.RegisterReq{
+			Hostname: a.hostname,
+			Ip:       "Unknown",
+			Tags:     a.cfg.Tags,
+			Version:  Version,
+			Os:       runtime.GOOS,
+			Arch:     runtime.GOARCH,
+			// 首次加入项目、credential 被重置以及换项目时需要
+			EnrollmentToken: a.cfg.EnrollmentToken,
+		}
+		if a.updateFailure != nil {
+			req.UpdateError = a.updateFailure.Reason
+		}
+		regResp, err := a.client.Register(ctx, req)
+		if err == nil && regResp.AgentCredential != "" {
+			if err := a.cred.Update(regResp.AgentCredential); err != nil {
+				errorf("❌ credential 保存到 %s 失败, 重启后需要管理员重置: %v", a.cfg.StateDir, err)
+			} else {
+				infof("🔑 已保存控制面签发的 credential")
+			}
+		}
+		if err != nil {
+			a.registerFailed(err)
+		} else {
+			infof("✅ 注册成功! ID: %s", regResp.AgentId)
+			a.registered()
+			err = a.session(ctx, regResp.AgentId)
+		}
+		if ctx.Err() != nil {
+			a.jobs.Wait()
+			return nil
+		}
+
+		a.mu.Lock()
+		staged := a.staged
+		a.staged = nil
+		a.mu.Unlock()
+		if staged != nil {
+			infof("⏳ [升级] 等待正在执行的任务结束...")
+			a.jobs.Wait()
+			if err := a.install(staged); err != nil {
+				errorf("❌ [升级] 安装 %s 失败, 继续运行 %s: %v", staged.version, Version, err)
+				os.Remove(staged.path)
+				a.updating.Store(false)
+			}
+			continue
+		}
+		warnf("🔌 连接断开 (%v)，%s 后重连...", err, a.cfg.ReconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(a.cfg.ReconnectDelay):
+		}
+	}
+}
+
+// session 一条心跳流的生命周期, 任意一端出错即返回
+func (a *Agent) session(parent context.Context, agentID string) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	a.mu.Lock()
+	a.cancelSession = cancel
+	a.mu.Unlock()
+
+	stream, err := a.client.Heartbeat(ctx)
+	if err != nil {
+		return fmt.Errorf("建立心跳流失败: %w", err)
+	}
+
+	errc := make(chan error, 2)
+
+	// 发送协程, 每轮重新读取间隔, 远程配置改了下一轮就生效
+	go func() {
+		metrics := newHostMetrics()
+		for {
+			cpu, mem, _ := metrics.read()
+			err := stream.Send(&pb.HeartbeatReq{
+				AgentId:       agentID,
+				Timestamp:     time.Now().Unix(),
+				CpuUsage:      cpu,
+				MemUsage:      mem,
+				ConfigVersion: a.settings().Version,
+			})
+			if err != nil {
+				errc <- fmt.Errorf("心跳发送失败: %w", err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(a.settings().HeartbeatInterval):
+			}
+		}
+	}()
+
+	// 接收协程 (接收任务 / 取消指令 / 配置变更)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				errc <- fmt.Errorf("心跳接收断开: %w", err)
+				return
+			}
+
+			if resp.Update != nil {
+				go a.prepareUpdate(parent, resp.Update)
+			}
+
+			if resp.ConfigOutdated {
+				go a.refreshConfig(parent, agentID)
+			}
+
+			for _, jobID := range resp.CancelJobIds {
+				if cancel, ok := a.running.Load(jobID); ok {
+					infof("🛑 [取消] 收到取消指令: %s", jobID)
+					cancel.(context.CancelFunc)()
+				}
+			}
+
+			if resp.Job != nil {
+				a.jobs.Add(1)
+				go func(j *pb.Job) {
+					defer a.jobs.Done()
+					a.handleJob(parent, agentID, j)
+				}(resp.Job)
+			}
+		}
+	}()
+
+	return <-errc
+}
+
+// refreshConfig 拉取并应用最新的远程配置, 同一时间只有一个在跑
+func (a *Agent) refreshConfig(ctx context.Context, agentID string) {
+	if !a.fetching.CompareAndSwap(false, true) {
+		return
+	}
+	defer a.fetching.Store(false)
+
+	fetchCtx, cancel := context.WithTimeout(ctx, a.cfg.ReportTimeout)
+	defer cancel()
+	remote, err := a.client.GetAgentConfig(fetchCtx, &pb.GetAgentConfigReq{AgentId: agentID})
+	if err != nil {
+		warnf("⚠️ 拉取远程配置失败: %v", err)
+		return
+	}
+
+	a.mu.Lock()
+	prev := a.current
+	a.current = a.local.merge(remote)
+	next := a.current
+	a.mu.Unlock()
+
+	setLogLevel(next.LogLevel)
+	if next.WorkerPoolSize != prev.WorkerPoolSize {
+		a.pool.Resize(next.WorkerPoolSize)
+	}
+	infof("⚙️ [配置] 已应用远程配置 %q: 心跳 %s | 并发 %d | 日志级别 %s | 任务类型 %s",
+		next.Version, next.HeartbeatInterval, next.WorkerPoolSize, next.LogLevel, describeJobTypes(next.AllowedJobTypes))
+}
+
+func describeJobTypes(types map[pb.JobType]bool) string {
+	if types == nil {
+		return "不限"
+	}
+	var names []string
+	for t := pb.JobType(0); int(t) < len(pb.JobType_name); t++ {
+		if types[t] {
+			names = append(names, t.String())
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// verifyJob 校验签名、目标 Agent、过期时间, 通过后登记防重放
+func (a *Agent) verifyJob(agentID string, j *pb.Job) error {
+	if a.pubKey == nil {
+		return nil
+	}
+	if err := jobsign.Verify(a.pubKey, j, agentID, time.Now()); err != nil {
+		return err
+	}
+	return a.replay.Claim(j.JobId, j.ExpiresAt)
+}
+
+// handleJob 先验签、过本地策略, 再排队等待工作池空位, 执行并汇报结果
+func (a *Agent) handleJob(ctx context.Context, agentID string, j *pb.Job) {
+	if err := a.verifyJob(agentID, j); err != nil {
+		warnf("⛔ [拒绝] 任务 %s 未通过签名校验: %v", j.JobId, err)
+		a.report(ctx, agentID, j.JobId, "Rejected", "⛔ 任务未通过签名校验: "+err.Error())
+		return
+	}
+
+	policy := a.currentPolicy()
+	reason := policy.Check(j)
+	if reason == "" && !a.settings().allows(j.Type) {
+		reason = fmt.Sprintf("job type %s is not allowed by agent config", j.Type)
+	}
+	if reason != "" {
+		warnf("⛔ [拒绝] 任务 %s: %s", j.JobId, reason)
+		a.report(ctx, agentID, j.JobId, "Rejected", "⛔ 任务被 Agent 执行策略拒绝: "+reason)
+		return
+	}
+
+	jobCtx, jobCancel := context.WithCancel(ctx)
+	defer jobCancel()
+	a.running.Store(j.JobId, jobCancel)
+	defer a.running.Delete(j.JobId)
+
+	if err := a.pool.Acquire(jobCtx); err != nil {
+		a.report(ctx, agentID, j.JobId, "Cancelled", "🛑 任务在排队时被取消")
+		return
+	}
+	defer a.pool.Release()
+
+	// 密钥只进子进程的环境变量, 输出在打日志和上报之前抹掉
+	var env, secrets []string
+	for _, sec := range j.Secrets {
+		env = append(env, sec.Env+"="+sec.Value)
+		secrets = append(secrets, sec.Value)
+	}
+
+	infof("⚙️ [执行中] 正在执行任务: %s", j.Payload)
+	var w io.Writer
+	live := a.startLiveOutput(ctx, agentID, j.JobId, secrets)
+	if live != nil {
+		w = live
+	}
+	output, success := RunLocalCommand(jobCtx, a.sandbox, j.JobId, j.Payload, env, policy.Timeout(a.cfg.JobTimeout), w)
+	if live != nil {
+		live.Close()
+	}
+	output = redact.Values(output, secrets...)
+	cancelled := jobCtx.Err() == context.Canceled
+	debugf("📄 [执行结果] \n%s", output)
+
+	status := "Success"
+	if cancelled {
+		status = "Cancelled"
+	} else if !success {
+		status = "Failed"
+	}
+	a.report(ctx, agentID, j.JobId, status, output)
+}
+
+func (a *Agent) report(ctx context.Context, agentID, jobID, status, output string) {
+	reportCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.cfg.ReportTimeout)
+	defer cancel()
+
+	_, err := a.client.ReportJobStatus(reportCtx, &pb.ReportJobReq{
+		AgentId: agentID,
+		JobId:   jobID,
+		Status:  status,
+		Result:  output,
+	})
+	if err != nil {
+		errorf("❌ 汇报失败: %v", err)
+	} else {
+		infof("✅ [汇报成功] 任务 %s 结果已上传 (%s)", jobID, status)
+	}
+}
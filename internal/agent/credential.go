@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// agentCredential 控制面在首次注册时签发的 per-agent credential, 每个调用都放在 metadata 里.
+// 它只返回一次, 落盘在 state_dir 里; 丢了以后需要管理员重置, 再带 enrollment token 重新注册.
+type agentCredential struct {
+	agentID string
+	path    string
+	value   atomic.Value // string
+}
+
+func loadCredential(dir, agentID string) (*agentCredential, error) {
+	c := &agentCredential{agentID: agentID, path: filepath.Join(dir, "credential")}
+	c.value.Store("")
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.value.Store(strings.TrimSpace(string(data)))
+	return c, nil
+}
+
+// Update 启用新签发的 credential 并落盘. 服务端已经只认新值, 落盘失败也要先用起来, 只是重启后需要管理员重置
+func (c *agentCredential) Update(credential string) error {
+	c.value.Store(credential)
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(credential+"\n"), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// GetRequestMetadata 实现 credentials.PerRPCCredentials
+func (c *agentCredential) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	md := map[string]string{"x-sentinel-agent-id": c.agentID}
+	if v := c.value.Load().(string); v != "" {
+		md["x-sentinel-agent-credential"] = v
+	}
+	return md, nil
+}
+
+// RequireTransportSecurity 和 server_addr 的 TLS 配置保持一致, 不强制
+func (c *agentCredential) RequireTransportSecurity() bool {
+	return false
+}
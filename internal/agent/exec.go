@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RunLocalCommand 在沙箱里执行本地 Shell 命令, 超过 timeout 或 parent 被取消时连带杀掉整个子进程组.
+// env 为额外的环境变量 (KEY=VALUE). live 不为 nil 时执行过程中的输出同时写给它.
+func RunLocalCommand(parent context.Context, sb *Sandbox, jobID, cmdStr string, env []string, timeout time.Duration, live io.Writer) (string, bool) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	var w io.Writer = &output
+	if live != nil {
+		w = io.MultiWriter(&output, live)
+	}
+	cmd := sb.command(ctx, cmdStr, env)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	cleanup, err := sb.start(cmd, jobID)
+	if err != nil {
+		return fmt.Sprintf("❌ 启动失败: %v", err), false
+	}
+	err = cmd.Wait()
+	cleanup()
+
+	if err != nil {
+		if parent.Err() == context.Canceled {
+			return fmt.Sprintf("🛑 任务已被取消\n输出: %s", output.String()), false
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Sprintf("❌ 任务超时! (%s limit)\n输出: %s", timeout, output.String()), false
+		}
+		return fmt.Sprintf("❌ 执行出错: %v\n输出: %s", err, output.String()), false
+	}
+	return output.String(), true
+}
@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// 日志级别, 可以由服务端下发的配置随时调整
+const (
+	levelDebug int32 = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var logLevels = map[string]int32{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+var currentLevel atomic.Int32
+
+func init() { currentLevel.Store(levelInfo) }
+
+// setLogLevel 未知级别忽略, 返回是否生效
+func setLogLevel(name string) bool {
+	lv, ok := logLevels[name]
+	if ok {
+		currentLevel.Store(lv)
+	}
+	return ok
+}
+
+func logf(level int32, format string, args ...any) {
+	if level >= currentLevel.Load() {
+		log.Printf(format, args...)
+	}
+}
+
+func debugf(format string, args ...any) { logf(levelDebug, format, args...) }
+func infof(format string, args ...any)  { logf(levelInfo, format, args...) }
+func warnf(format string, args ...any)  { logf(levelWarn, format, args...) }
+func errorf(format string, args ...any) { logf(levelError, format, args...) }
@@ -0,0 +1,93 @@
+//go:build linux
+
+package agent
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// hostMetrics 心跳里带的 CPU / 内存使用率. CPU 按两次读 /proc/stat 的差值计算, 第一次读只做基准.
+type hostMetrics struct {
+	idle, total uint64
+}
+
+func newHostMetrics() *hostMetrics {
+	m := &hostMetrics{}
+	m.idle, m.total, _ = readCPUTimes()
+	return m
+}
+
+// read 返回百分比 (0-100), 读不到时 ok 为 false
+func (m *hostMetrics) read() (cpu, mem float64, ok bool) {
+	idle, total, err := readCPUTimes()
+	if err != nil {
+		return 0, 0, false
+	}
+	if d := total - m.total; total > m.total {
+		cpu = 100 * (1 - float64(idle-m.idle)/float64(d))
+	}
+	m.idle, m.total = idle, total
+
+	mem, err = readMemUsage()
+	if err != nil {
+		return 0, 0, false
+	}
+	return cpu, mem, true
+}
+
+// readCPUTimes /proc/stat 第一行: cpu user nice system idle iowait irq softirq steal ...
+func readCPUTimes() (idle, total uint64, err error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, errors.New("unexpected /proc/stat format")
+	}
+	for i, f := range fields[1:] {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		total += v
+		// idle + iowait
+		if i == 3 || i == 4 {
+			idle += v
+		}
+	}
+	return idle, total, nil
+}
+
+// readMemUsage (MemTotal - MemAvailable) / MemTotal
+func readMemUsage() (float64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var total, avail float64
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, _ := strconv.ParseFloat(fields[1], 64)
+		switch fields[0] {
+		case "MemTotal:":
+			total = v
+		case "MemAvailable:":
+			avail = v
+		}
+	}
+	if total == 0 {
+		return 0, os.ErrNotExist
+	}
+	return 100 * (total - avail) / total, sc.Err()
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package agent
+
+// hostMetrics 目前只实现了 Linux, 其他平台心跳里不带使用率
+type hostMetrics struct{}
+
+func newHostMetrics() *hostMetrics { return &hostMetrics{} }
+
+func (m *hostMetrics) read() (cpu, mem float64, ok bool) { return 0, 0, false }
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/redact"
+)
+
+const (
+	// outputFlushInterval 攒多久的输出发一次
+	outputFlushInterval = time.Second
+	// outputChunkSize 没有换行的输出攒到这么多也发出去
+	outputChunkSize = 32 << 10
+	// outputPendingLimit 上报跟不上时最多攒这么多, 超过就放弃实时输出; 和控制面每个任务保留的实时输出上限一致
+	outputPendingLimit = 1 << 20
+)
+
+// liveOutput 把执行中的输出按整行攒批, 通过 ReportJobOutput 流式上报, 控制面据此提供 logs -f 和实时查看.
+// 上报失败只影响实时查看, 任务结束时完整输出仍由 ReportJobStatus 上报.
+type liveOutput struct {
+	mu      sync.Mutex
+	pending []byte
+	stream  pb.SentinelService_ReportJobOutputClient // 出错之后置空, 不再上报
+	agentID string
+	jobID   string
+	secrets []string
+	seq     int64
+	dropped bool // 上报跟不上, 放弃了实时输出
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startLiveOutput 打开上报流, 打不开时返回 nil, 任务照常执行
+func (a *Agent) startLiveOutput(ctx context.Context, agentID, jobID string, secrets []string) *liveOutput {
+	stream, err := a.client.ReportJobOutput(ctx)
+	if err != nil {
+		debugf("实时输出上报不可用 (%s): %v", jobID, err)
+		return nil
+	}
+	o := &liveOutput{
+		stream:  stream,
+		agentID: agentID,
+		jobID:   jobID,
+		secrets: secrets,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go o.loop()
+	return o
+}
+
+func (o *liveOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stream == nil || o.dropped {
+		return len(p), nil
+	}
+	if len(o.pending)+len(p) > outputPendingLimit {
+		warnf("⚠️ 任务 %s 的实时输出上报跟不上, 已放弃, 结束后统一上报", o.jobID)
+		o.dropped = true
+		o.pending = nil
+		return len(p), nil
+	}
+	o.pending = append(o.pending, p...)
+	return len(p), nil
+}
+
+func (o *liveOutput) loop() {
+	defer close(o.done)
+	ticker := time.NewTicker(outputFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+			o.flush(false)
+		}
+	}
+}
+
+// flush 只发完整的行, 密钥按行抹掉不会被切开; 一行太长或者任务结束 (all) 时全部发出.
+// Send 可能因为流控阻塞, 在锁外调用, 不能卡住任务写输出. 只有 loop 和 Close 调用, 不会并发 Send.
+func (o *liveOutput) flush(all bool) {
+	o.mu.Lock()
+	if o.stream == nil || o.dropped || len(o.pending) == 0 {
+		o.mu.Unlock()
+		return
+	}
+	n := len(o.pending)
+	if !all && n < outputChunkSize {
+		if n = bytes.LastIndexByte(o.pending, '\n') + 1; n == 0 {
+			o.mu.Unlock()
+			return
+		}
+	}
+	data := string(o.pending[:n])
+	o.pending = o.pending[n:]
+	o.seq++
+	chunk := &pb.JobOutputChunk{AgentId: o.agentID, JobId: o.jobID, Seq: o.seq}
+	stream := o.stream
+	o.mu.Unlock()
+
+	chunk.Data = redact.Values(data, o.secrets...)
+	if err := stream.Send(chunk); err != nil {
+		warnf("⚠️ 任务 %s 的实时输出上报中断, 结束后统一上报: %v", o.jobID, err)
+		o.mu.Lock()
+		o.stream = nil
+		o.pending = nil
+		o.mu.Unlock()
+	}
+}
+
+// Close 发出剩下的输出并等服务端确认. 要在汇报任务结束之前调用, 控制面看到终态时分片已经全部落库.
+func (o *liveOutput) Close() {
+	close(o.stop)
+	<-o.done
+	o.flush(true)
+	o.mu.Lock()
+	stream := o.stream
+	o.stream = nil
+	o.mu.Unlock()
+	if stream == nil {
+		return
+	}
+	if _, err := stream.CloseAndRecv(); err != nil {
+		warnf("⚠️ 任务 %s 的实时输出上报失败: %v", o.jobID, err)
+	}
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+)
+
+// blockedStream Send 一直阻塞到 release 关闭, 模拟服务端流控卡住
+type blockedStream struct {
+	pb.SentinelService_ReportJobOutputClient
+	sent    chan *pb.JobOutputChunk
+	release chan struct{}
+}
+
+func (s *blockedStream) Send(c *pb.JobOutputChunk) error {
+	s.sent <- c
+	<-s.release
+	return nil
+}
+
+func TestLiveOutputWriteDoesNotWaitForSend(t *testing.T) {
+	stream := &blockedStream{sent: make(chan *pb.JobOutputChunk, 1), release: make(chan struct{})}
+	o := &liveOutput{stream: stream, jobID: "job-1", secrets: []string{"hunter2"}}
+	o.Write([]byte("password hunter2\n"))
+
+	flushed := make(chan struct{})
+	go func() {
+		o.flush(false)
+		close(flushed)
+	}()
+	chunk := <-stream.sent
+	if chunk.Data != "password ******\n" || chunk.Seq != 1 {
+		t.Fatalf("chunk = %d %q", chunk.Seq, chunk.Data)
+	}
+
+	// Send 阻塞期间任务照常写输出, 积压超过上限就放弃实时输出
+	wrote := make(chan struct{})
+	go func() {
+		o.Write([]byte("next line\n"))
+		o.Write([]byte(strings.Repeat("x", outputPendingLimit)))
+		close(wrote)
+	}()
+	select {
+	case <-wrote:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Write blocked while Send was in progress")
+	}
+	o.mu.Lock()
+	dropped, pending := o.dropped, len(o.pending)
+	o.mu.Unlock()
+	if !dropped || pending != 0 {
+		t.Errorf("after falling behind: dropped = %v, pending = %d bytes", dropped, pending)
+	}
+
+	close(stream.release)
+	<-flushed
+	o.flush(true)
+	select {
+	case c := <-stream.sent:
+		t.Errorf("chunk %d sent after live output was dropped", c.Seq)
+	default:
+	}
+}
@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+)
+
+// Policy Agent 本地的执行策略, 服务端下发的配置只能在它的基础上进一步收紧.
+// 所有 payload 最终都交给 sh -c 执行, 所以命令规则对所有任务类型都生效.
+//
+// 路径检查只能识别命令里字面出现的路径 (变量展开、相对路径、通配符都看不到),
+// 它挡的是手滑和明显越界的指令, 不能当作安全隔离.
+type Policy struct {
+	AllowedJobTypes []string      `mapstructure:"allowed_job_types"` // 为空表示不限制
+	Allow           []string      `mapstructure:"allow"`             // 正则, 非空时命令必须命中至少一条
+	Deny            []string      `mapstructure:"deny"`              // 正则, 命中任意一条即拒绝
+	ForbiddenPaths  []string      `mapstructure:"forbidden_paths"`   // 命令里出现这些路径或其子路径即拒绝
+	MaxRuntime      time.Duration `mapstructure:"max_runtime"`       // 0 表示沿用 agent.job_timeout
+
+	allowedTypes map[pb.JobType]bool
+	allow        []*regexp.Regexp
+	deny         []*regexp.Regexp
+	forbidden    []string
+}
+
+// LoadPolicy 读取策略文件 (yaml / json / toml, 按扩展名识别). path 为空返回不做任何限制的策略.
+func LoadPolicy(path string) (*Policy, error) {
+	p := &Policy{}
+	if path != "" {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read policy %s: %w", path, err)
+		}
+		if err := v.Unmarshal(p); err != nil {
+			return nil, fmt.Errorf("decode policy %s: %w", path, err)
+		}
+	}
+	if err := p.compile(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func (p *Policy) compile() error {
+	if len(p.AllowedJobTypes) > 0 {
+		p.allowedTypes = make(map[pb.JobType]bool)
+		for _, name := range p.AllowedJobTypes {
+			v, ok := pb.JobType_value[strings.ToUpper(name)]
+			if !ok {
+				return fmt.Errorf("unknown job type %q", name)
+			}
+			p.allowedTypes[pb.JobType(v)] = true
+		}
+	}
+	for _, expr := range p.Allow {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("allow pattern %q: %w", expr, err)
+		}
+		p.allow = append(p.allow, re)
+	}
+	for _, expr := range p.Deny {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("deny pattern %q: %w", expr, err)
+		}
+		p.deny = append(p.deny, re)
+	}
+	for _, fp := range p.ForbiddenPaths {
+		if !filepath.IsAbs(fp) {
+			return fmt.Errorf("forbidden path %q must be absolute", fp)
+		}
+		p.forbidden = append(p.forbidden, filepath.Clean(fp))
+	}
+	if p.MaxRuntime < 0 {
+		return fmt.Errorf("max_runtime must not be negative")
+	}
+	return nil
+}
+
+// Check 返回拒绝原因, 允许执行时返回 ""
+func (p *Policy) Check(j *pb.Job) string {
+	if p.allowedTypes != nil && !p.allowedTypes[j.Type] {
+		return fmt.Sprintf("job type %s is not allowed by local policy", j.Type)
+	}
+	cmd := j.Payload
+	for _, re := range p.deny {
+		if re.MatchString(cmd) {
+			return fmt.Sprintf("command matches deny pattern %q", re.String())
+		}
+	}
+	if len(p.allow) > 0 {
+		matched := false
+		for _, re := range p.allow {
+			if re.MatchString(cmd) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "command does not match any allow pattern"
+		}
+	}
+	for _, tok := range pathTokens(cmd) {
+		for _, fp := range p.forbidden {
+			if tok == fp || fp == "/" || strings.HasPrefix(tok, fp+"/") {
+				return fmt.Sprintf("command touches forbidden path %s", fp)
+			}
+		}
+	}
+	return ""
+}
+
+// Timeout 策略里的 max_runtime 只能缩短 agent.job_timeout
+func (p *Policy) Timeout(configured time.Duration) time.Duration {
+	if p.MaxRuntime > 0 && p.MaxRuntime < configured {
+		return p.MaxRuntime
+	}
+	return configured
+}
+
+// pathTokens 按空白和 shell 元字符切分命令, 取出看起来像绝对路径的片段
+func pathTokens(cmd string) []string {
+	fields := strings.FieldsFunc(cmd, func(r rune) bool {
+		return strings.ContainsRune(" \t\r\n;|&<>()'\"`=$,{}", r)
+	})
+	var paths []string
+	for _, f := range fields {
+		if strings.HasPrefix(f, "/") {
+			paths = append(paths, filepath.Clean(f))
+		}
+	}
+	return paths
+}
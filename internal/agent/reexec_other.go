@@ -0,0 +1,7 @@
+//go:build !unix
+
+package agent
+
+func reexec(exe string) error {
+	return errReexecUnsupported
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package agent
+
+import (
+	"os"
+	"syscall"
+)
+
+// reexec 用新的二进制替换当前进程, 参数和环境变量保持不变; 成功时不会返回
+func reexec(exe string) error {
+	return syscall.Exec(exe, os.Args, os.Environ())
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/jobsign"
+)
+
+// replayGuard 记住执行过的签名任务, 防止同一个任务被再次下发执行.
+// 签名自带过期时间, 所以记录只需保留到过期为止, 落盘保证 Agent 重启后依然有效.
+type replayGuard struct {
+	mu   sync.Mutex
+	path string
+	seen map[string]int64 // jobID -> expires_at (unix 秒)
+}
+
+func loadReplayGuard(dir string) (*replayGuard, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	g := &replayGuard{path: filepath.Join(dir, "executed-jobs.json"), seen: make(map[string]int64)}
+	data, err := os.ReadFile(g.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &g.seen); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Claim 第一次见到该任务时记录并返回 nil, 否则返回 jobsign.ErrReplayed.
+// 落盘失败时撤销记录, 任务不执行, 重发时还能再认领.
+func (g *replayGuard) Claim(jobID string, expiresAt int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.seen[jobID]; ok {
+		return jobsign.ErrReplayed
+	}
+	cutoff := time.Now().Add(-jobsign.ClockSkew).Unix()
+	for id, exp := range g.seen {
+		if exp < cutoff {
+			delete(g.seen, id)
+		}
+	}
+	g.seen[jobID] = expiresAt
+	if err := g.save(); err != nil {
+		delete(g.seen, jobID)
+		return err
+	}
+	return nil
+}
+
+func (g *replayGuard) save() error {
+	data, err := json.Marshal(g.seen)
+	if err != nil {
+		return err
+	}
+	tmp := g.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, g.path)
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/jobsign"
+)
+
+func TestReplayGuard(t *testing.T) {
+	dir := t.TempDir()
+	g, err := loadReplayGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	if err := g.Claim("job-1", exp); err != nil {
+		t.Fatalf("first Claim: %v", err)
+	}
+
+	// 重启之后从磁盘恢复, 已执行过的任务依然被拒绝
+	reloaded, err := loadReplayGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		guard *replayGuard
+		job   string
+		want  error
+	}{
+		{"replayed", g, "job-1", jobsign.ErrReplayed},
+		{"replayed after restart", reloaded, "job-1", jobsign.ErrReplayed},
+		{"new job", reloaded, "job-2", nil},
+	}
+	for _, tt := range tests {
+		if err := tt.guard.Claim(tt.job, exp); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Claim(%s) = %v, want %v", tt.name, tt.job, err, tt.want)
+		}
+	}
+}
+
+func TestReplayGuardSaveFailure(t *testing.T) {
+	dir := t.TempDir()
+	g, err := loadReplayGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 临时文件的位置被目录占住, 落盘必然失败
+	if err := os.Mkdir(g.path+".tmp", 0o700); err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	if err := g.Claim("job-1", exp); err == nil {
+		t.Fatal("Claim succeeded although the record could not be saved")
+	}
+	if err := os.Remove(g.path + ".tmp"); err != nil {
+		t.Fatal(err)
+	}
+	// 没落盘的记录不算数, 重发的任务还能认领
+	if err := g.Claim("job-1", exp); err != nil {
+		t.Fatalf("Claim after failed save: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+)
+
+// 超时或取消后, 等子进程组退出、输出管道关闭的最长时间
+const killWaitDelay = 2 * time.Second
+
+// Sandbox 决定任务子进程以什么身份、在什么资源限制下运行.
+// 平台相关的部分 (切换用户、进程组、cgroup) 在 sandbox_linux.go / sandbox_other.go.
+type Sandbox struct {
+	cfg      config.SandboxConfig
+	platform platformSandbox
+}
+
+func NewSandbox(cfg config.SandboxConfig) (*Sandbox, error) {
+	sb := &Sandbox{cfg: cfg}
+	if err := sb.platform.init(cfg); err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
+
+// Describe 启动日志用
+func (sb *Sandbox) Describe() string {
+	c := sb.cfg
+	var parts []string
+	if c.User != "" {
+		parts = append(parts, "user="+c.User)
+	}
+	if c.CPUTime > 0 {
+		parts = append(parts, "cpu="+c.CPUTime.String())
+	}
+	if c.MemoryMB > 0 {
+		parts = append(parts, fmt.Sprintf("mem=%dMiB", c.MemoryMB))
+	}
+	if c.OpenFiles > 0 {
+		parts = append(parts, fmt.Sprintf("nofile=%d", c.OpenFiles))
+	}
+	if c.Processes > 0 {
+		parts = append(parts, fmt.Sprintf("nproc=%d", c.Processes))
+	}
+	if sb.platform.cgroupEnabled() {
+		parts = append(parts, "cgroup="+c.CgroupRoot)
+	}
+	if len(parts) == 0 {
+		return "无限制"
+	}
+	return strings.Join(parts, " ")
+}
+
+// os/exec 不支持给子进程单独设置 rlimit, 所以 Agent 以
+// "<exe> __sandbox_exec <limit=value...> -- <argv...>" 的形式重新执行自己, 设置好 rlimit 后再 exec 真正的命令.
+// 限制从任务的第一条指令起就生效, 软硬限制同时设置, 非 root 身份无法再调高.
+const sandboxShimArg = "__sandbox_exec"
+
+// MaybeRunSandboxShim 必须在 main 的最开始调用. 当前进程是沙箱垫片时 exec 成任务命令, 不会返回.
+func MaybeRunSandboxShim() {
+	if len(os.Args) < 2 || os.Args[1] != sandboxShimArg {
+		return
+	}
+	err := runShim(os.Args[2:])
+	fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+	os.Exit(126)
+}
+
+// command 组装子进程, 不启动. env 是额外注入的 KEY=VALUE (任务引用的密钥).
+func (sb *Sandbox) command(ctx context.Context, cmdStr string, env []string) *exec.Cmd {
+	argv := sb.platform.argv(sb.cfg, []string{"sh", "-c", cmdStr})
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.WaitDelay = killWaitDelay
+	if sb.cfg.User != "" {
+		// 换了身份就不要把 Agent 自己的环境变量 (可能含有凭据) 带给任务
+		dir := sb.cfg.WorkDir
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		cmd.Dir = dir
+		cmd.Env = []string{
+			"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+			"HOME=" + dir,
+			"LANG=C.UTF-8",
+		}
+	} else if sb.cfg.WorkDir != "" {
+		cmd.Dir = sb.cfg.WorkDir
+	}
+	if len(env) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, env...)
+	}
+	sb.platform.prepare(cmd)
+	return cmd
+}
+
+// start 启动子进程并放进 cgroup (如果启用). 返回的 cleanup 在 Wait 之后调用.
+func (sb *Sandbox) start(cmd *exec.Cmd, jobID string) (func(), error) {
+	return sb.platform.start(cmd, sb.cfg, jobID)
+}
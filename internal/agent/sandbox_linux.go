@@ -0,0 +1,276 @@
+//go:build linux
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+)
+
+const cgroupV2Marker = "/sys/fs/cgroup/cgroup.controllers"
+
+// RLIMIT_NPROC 在 syscall 包里没有导出
+const rlimitNproc = 6
+
+type platformSandbox struct {
+	self   string // Agent 自己的可执行文件, 用作 rlimit 垫片
+	cred   *syscall.Credential
+	cgroup bool
+}
+
+func (p *platformSandbox) init(cfg config.SandboxConfig) error {
+	if cfg.CPUTime > 0 || cfg.MemoryMB > 0 || cfg.OpenFiles > 0 || cfg.Processes > 0 {
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("agent.sandbox: locate agent binary for rlimit shim: %w", err)
+		}
+		p.self = self
+	}
+	if cfg.User != "" {
+		uid, gid, err := lookupIDs(cfg.User, cfg.Group)
+		if err != nil {
+			return err
+		}
+		if euid := os.Geteuid(); euid != 0 && uint32(euid) != uid {
+			return fmt.Errorf("agent.sandbox.user: switching to uid %d requires the agent to run as root", uid)
+		}
+		p.cred = &syscall.Credential{Uid: uid, Gid: gid, Groups: []uint32{}}
+	}
+	if cfg.Cgroup {
+		if err := setupCgroupRoot(cfg.CgroupRoot); err != nil {
+			warnf("⚠️ [沙箱] cgroup v2 不可用, 只使用 rlimit: %v", err)
+		} else {
+			p.cgroup = true
+		}
+	}
+	return nil
+}
+
+func (p *platformSandbox) cgroupEnabled() bool { return p.cgroup }
+
+// argv 需要 rlimit 时把命令包进垫片
+func (p *platformSandbox) argv(cfg config.SandboxConfig, cmd []string) []string {
+	if p.self == "" {
+		return cmd
+	}
+	args := []string{p.self, sandboxShimArg}
+	add := func(name string, v uint64) {
+		if v > 0 {
+			args = append(args, fmt.Sprintf("%s=%d", name, v))
+		}
+	}
+	add("cpu", uint64(cfg.CPUTime/time.Second))
+	add("as", uint64(cfg.MemoryMB)<<20)
+	add("nofile", uint64(cfg.OpenFiles))
+	add("nproc", uint64(cfg.Processes))
+	if p.cred != nil {
+		args = append(args, fmt.Sprintf("gid=%d", p.cred.Gid), fmt.Sprintf("uid=%d", p.cred.Uid))
+	}
+	return append(append(args, "--"), cmd...)
+}
+
+// runShim 垫片进程: 设置 rlimit, 按需降权, 然后 exec 真正的命令; 成功时不返回
+func runShim(args []string) error {
+	uid, gid := -1, -1
+	resources := map[string]int{
+		"cpu":    syscall.RLIMIT_CPU,
+		"as":     syscall.RLIMIT_AS,
+		"nofile": syscall.RLIMIT_NOFILE,
+		"nproc":  rlimitNproc,
+	}
+	for len(args) > 0 && args[0] != "--" {
+		name, value, ok := strings.Cut(args[0], "=")
+		v, err := strconv.ParseUint(value, 10, 64)
+		if !ok || err != nil {
+			return fmt.Errorf("bad limit %q", args[0])
+		}
+		args = args[1:]
+		switch name {
+		case "uid":
+			uid = int(v)
+			continue
+		case "gid":
+			gid = int(v)
+			continue
+		}
+		res, known := resources[name]
+		if !known {
+			return fmt.Errorf("unknown limit %q", name)
+		}
+		if err := syscall.Setrlimit(res, &syscall.Rlimit{Cur: v, Max: v}); err != nil {
+			return fmt.Errorf("setrlimit %s=%d: %w", name, v, err)
+		}
+	}
+	// 顺序不能反: 先清附加组和 gid, 最后才放弃 root
+	if gid >= 0 {
+		if err := syscall.Setgroups(nil); err != nil {
+			return fmt.Errorf("setgroups: %w", err)
+		}
+		if err := syscall.Setgid(gid); err != nil {
+			return fmt.Errorf("setgid %d: %w", gid, err)
+		}
+	}
+	if uid >= 0 {
+		if err := syscall.Setuid(uid); err != nil {
+			return fmt.Errorf("setuid %d: %w", uid, err)
+		}
+	}
+	if len(args) < 2 {
+		return errors.New("missing command")
+	}
+	path, err := exec.LookPath(args[1])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, args[1:], os.Environ())
+}
+
+// prepare 子进程单独成组, 超时 / 取消时连同它派生的进程一起杀掉.
+// 经过垫片时由垫片降权 (Agent 的可执行文件不一定对目标用户可读), 否则直接以目标身份启动.
+func (p *platformSandbox) prepare(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if p.self == "" {
+		cmd.SysProcAttr.Credential = p.cred
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// start 开启 cgroup 时用 clone3 的 CLONE_INTO_CGROUP 直接在目标 cgroup 里创建子进程, 不存在逃逸窗口
+func (p *platformSandbox) start(cmd *exec.Cmd, cfg config.SandboxConfig, jobID string) (func(), error) {
+	if !p.cgroup {
+		return func() {}, cmd.Start()
+	}
+	dir := filepath.Join(cfg.CgroupRoot, "job-"+cgroupName(jobID))
+	if err := createJobCgroup(dir, cfg); err != nil {
+		return nil, err
+	}
+	fd, err := syscall.Open(dir, syscall.O_RDONLY|syscall.O_DIRECTORY, 0)
+	if err != nil {
+		removeCgroup(dir)
+		return nil, err
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = fd
+	err = cmd.Start()
+	syscall.Close(fd)
+	if err != nil {
+		removeCgroup(dir)
+		return nil, err
+	}
+	return func() { removeCgroup(dir) }, nil
+}
+
+func lookupIDs(userName, groupName string) (uint32, uint32, error) {
+	u, err := user.Lookup(userName)
+	if err != nil {
+		if u, err = user.LookupId(userName); err != nil {
+			return 0, 0, fmt.Errorf("agent.sandbox.user %q: %w", userName, err)
+		}
+	}
+	uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+	gidStr := u.Gid
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return 0, 0, fmt.Errorf("agent.sandbox.group %q: %w", groupName, err)
+			}
+		}
+		gidStr = g.Gid
+	}
+	gid, _ := strconv.ParseUint(gidStr, 10, 32)
+	return uint32(uid), uint32(gid), nil
+}
+
+// setupCgroupRoot 创建 Agent 专用的父 cgroup 并打开需要的控制器, 最后用一个空进程验证内核支持 CLONE_INTO_CGROUP
+func setupCgroupRoot(root string) error {
+	if _, err := os.Stat(cgroupV2Marker); err != nil {
+		return errors.New("cgroup v2 (unified hierarchy) is not mounted")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return err
+	}
+	controllers := "+cpu +memory +pids"
+	if err := os.WriteFile(filepath.Join(filepath.Dir(root), "cgroup.subtree_control"), []byte(controllers), 0); err != nil {
+		return fmt.Errorf("enable controllers on %s: %w", filepath.Dir(root), err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), []byte(controllers), 0); err != nil {
+		return fmt.Errorf("enable controllers on %s: %w", root, err)
+	}
+
+	probe := filepath.Join(root, "probe")
+	if err := os.Mkdir(probe, 0o755); err != nil && !os.IsExist(err) {
+		return err
+	}
+	defer removeCgroup(probe)
+	fd, err := syscall.Open(probe, syscall.O_RDONLY|syscall.O_DIRECTORY, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+	cmd := exec.Command("/bin/true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{UseCgroupFD: true, CgroupFD: fd}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("start process in cgroup: %w", err)
+	}
+	return nil
+}
+
+func createJobCgroup(dir string, cfg config.SandboxConfig) error {
+	if err := os.Mkdir(dir, 0o755); err != nil && !os.IsExist(err) {
+		return err
+	}
+	limits := map[string]string{}
+	if cfg.MemoryMB > 0 {
+		limits["memory.max"] = strconv.FormatInt(int64(cfg.MemoryMB)<<20, 10)
+		limits["memory.swap.max"] = "0"
+	}
+	if cfg.Processes > 0 {
+		limits["pids.max"] = strconv.Itoa(cfg.Processes)
+	}
+	if cfg.CPUPercent > 0 {
+		limits["cpu.max"] = fmt.Sprintf("%d 100000", cfg.CPUPercent*1000)
+	}
+	for file, v := range limits {
+		err := os.WriteFile(filepath.Join(dir, file), []byte(v), 0)
+		// 没开 swap 记账的内核没有 memory.swap.max, 不算错误
+		if err != nil && !(file == "memory.swap.max" && os.IsNotExist(err)) {
+			removeCgroup(dir)
+			return fmt.Errorf("set %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// removeCgroup 先杀掉残留进程 (比如后台化的孙进程), 再删除目录
+func removeCgroup(dir string) {
+	os.WriteFile(filepath.Join(dir, "cgroup.kill"), []byte("1"), 0)
+	for i := 0; i < 20; i++ {
+		if err := syscall.Rmdir(dir); err == nil || errors.Is(err, syscall.ENOENT) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	warnf("⚠️ [沙箱] 删除 cgroup %s 失败", dir)
+}
+
+func cgroupName(jobID string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '.' {
+			return '_'
+		}
+		return r
+	}, jobID)
+}
@@ -0,0 +1,37 @@
+//go:build !linux
+
+package agent
+
+import (
+	"errors"
+	"os/exec"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+)
+
+// 非 Linux 平台不支持切换用户; rlimit 和 cgroup 配置会被忽略
+type platformSandbox struct{}
+
+func (p *platformSandbox) init(cfg config.SandboxConfig) error {
+	if cfg.User != "" {
+		return errors.New("agent.sandbox.user is only supported on linux")
+	}
+	if cfg.CPUTime > 0 || cfg.MemoryMB > 0 || cfg.OpenFiles > 0 || cfg.Processes > 0 || cfg.Cgroup {
+		warnf("⚠️ [沙箱] 资源限制只在 Linux 上可用, 已忽略")
+	}
+	return nil
+}
+
+func (p *platformSandbox) argv(cfg config.SandboxConfig, cmd []string) []string { return cmd }
+
+func runShim(args []string) error {
+	return errors.New("sandbox shim is only supported on linux")
+}
+
+func (p *platformSandbox) cgroupEnabled() bool { return false }
+
+func (p *platformSandbox) prepare(cmd *exec.Cmd) {}
+
+func (p *platformSandbox) start(cmd *exec.Cmd, cfg config.SandboxConfig, jobID string) (func(), error) {
+	return func() {}, cmd.Start()
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+)
+
+// settings 当前生效的运行参数 = 本地配置 + 服务端下发配置里的非零字段
+type settings struct {
+	Version           string
+	HeartbeatInterval time.Duration
+	WorkerPoolSize    int
+	AllowedJobTypes   map[pb.JobType]bool // nil 表示不限制
+	LogLevel          string
+}
+
+func localSettings(cfg config.AgentConfig) (settings, error) {
+	s := settings{
+		HeartbeatInterval: cfg.HeartbeatInterval,
+		WorkerPoolSize:    cfg.WorkerPoolSize,
+		LogLevel:          cfg.LogLevel,
+	}
+	if len(cfg.AllowedJobTypes) > 0 {
+		s.AllowedJobTypes = make(map[pb.JobType]bool)
+		for _, name := range cfg.AllowedJobTypes {
+			v, ok := pb.JobType_value[strings.ToUpper(name)]
+			if !ok {
+				return s, fmt.Errorf("agent.allowed_job_types: unknown job type %q", name)
+			}
+			s.AllowedJobTypes[pb.JobType(v)] = true
+		}
+	}
+	return s, nil
+}
+
+// merge 用远程配置覆盖本地配置, 远程配置删掉某个字段后自动回落到本地值
+func (s settings) merge(remote *pb.AgentConfig) settings {
+	out := s
+	out.Version = remote.Version
+	if remote.HeartbeatIntervalSeconds > 0 {
+		out.HeartbeatInterval = time.Duration(remote.HeartbeatIntervalSeconds) * time.Second
+	}
+	if remote.WorkerPoolSize > 0 {
+		out.WorkerPoolSize = int(remote.WorkerPoolSize)
+	}
+	if len(remote.AllowedJobTypes) > 0 {
+		out.AllowedJobTypes = make(map[pb.JobType]bool)
+		for _, t := range remote.AllowedJobTypes {
+			out.AllowedJobTypes[t] = true
+		}
+	}
+	if _, ok := logLevels[remote.LogLevel]; ok {
+		out.LogLevel = remote.LogLevel
+	}
+	return out
+}
+
+func (s settings) allows(t pb.JobType) bool {
+	return s.AllowedJobTypes == nil || s.AllowedJobTypes[t]
+}
+
+// workerPool 容量可以在运行中调整的信号量. 调小时正在执行的任务不受影响, 只是新任务要等到空位.
+type workerPool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	size int
+	busy int
+}
+
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{size: size}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Acquire 阻塞直到拿到空位或 ctx 结束
+func (p *workerPool) Acquire(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		p.cond.Broadcast()
+		p.mu.Unlock()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.busy >= p.size {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.cond.Wait()
+	}
+	p.busy++
+	return nil
+}
+
+func (p *workerPool) Release() {
+	p.mu.Lock()
+	p.busy--
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+func (p *workerPool) Resize(size int) {
+	p.mu.Lock()
+	p.size = size
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
@@ -0,0 +1,295 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/jobsign"
+)
+
+// Version 构建时通过 -ldflags "-X github.com/stywzn/Go-Cloud-Compute/internal/agent.Version=..." 注入
+var Version = "dev"
+
+const (
+	// 新版本连续这么多次注册不上 (或者启动这么多次都没注册上) 就回滚
+	maxUpdateAttempts = 5
+	downloadTimeout   = 5 * time.Minute
+)
+
+// 自更新用到的文件都放在可执行文件旁边:
+//
+//	<exe>.prev          升级前的二进制, 回滚用
+//	<exe>.update        升级进行中的标记, 新版本注册成功后删除
+//	<exe>.update-failed 回滚原因, 旧版本下次注册时上报给服务端
+type updateState struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Attempts int    `json:"attempts"`
+}
+
+type updateFailure struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// stagedUpdate 已下载并校验过、等待安装的新版本
+type stagedUpdate struct {
+	version string
+	path    string
+}
+
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+func readJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// resumeUpdate 启动时调用: 如果上一次升级还没确认, 记一次尝试, 超过上限直接回滚.
+// 如果上一次升级失败回滚过, 读出原因留给注册时上报.
+func (a *Agent) resumeUpdate() {
+	exe, err := executablePath()
+	if err != nil {
+		warnf("⚠️ [升级] 无法定位可执行文件, 自更新不可用: %v", err)
+		return
+	}
+	a.exe = exe
+
+	var failed updateFailure
+	if err := readJSON(exe+".update-failed", &failed); err == nil {
+		a.updateFailure = &failed
+	}
+
+	var st updateState
+	if err := readJSON(exe+".update", &st); err != nil {
+		return
+	}
+	if st.To != Version {
+		// 标记和当前二进制对不上 (比如被人手动替换过), 不再跟踪
+		os.Remove(exe + ".update")
+		return
+	}
+	st.Attempts++
+	if st.Attempts > maxUpdateAttempts {
+		a.rollback(&st, fmt.Sprintf("version %s was started %d times without registering", st.To, maxUpdateAttempts))
+		return
+	}
+	if err := writeJSON(exe+".update", &st); err != nil {
+		warnf("⚠️ [升级] 更新升级标记失败: %v", err)
+	}
+	a.trial = &st
+	infof("🆕 [升级] 正在试运行新版本 %s (从 %s 升级, 第 %d 次启动)", st.To, st.From, st.Attempts)
+}
+
+// registerFailed 试运行期间注册失败计数, 超过上限回滚
+func (a *Agent) registerFailed(err error) {
+	if a.trial == nil {
+		return
+	}
+	a.trial.Attempts++
+	if a.trial.Attempts > maxUpdateAttempts {
+		a.rollback(a.trial, fmt.Sprintf("version %s failed to register: %v", a.trial.To, err))
+		return
+	}
+	writeJSON(a.exe+".update", a.trial)
+}
+
+// registered 注册成功: 确认新版本, 清掉已经上报过的失败记录
+func (a *Agent) registered() {
+	if a.trial != nil {
+		os.Remove(a.exe + ".update")
+		infof("✅ [升级] 新版本 %s 已确认", a.trial.To)
+		a.trial = nil
+	}
+	if a.updateFailure != nil {
+		os.Remove(a.exe + ".update-failed")
+		a.updateFailure = nil
+	}
+}
+
+// rollback 换回 <exe>.prev 并重新执行; 成功时不会返回
+func (a *Agent) rollback(st *updateState, reason string) {
+	errorf("⏪ [升级] %s, 回滚到 %s", reason, st.From)
+	if err := os.Rename(a.exe+".prev", a.exe); err != nil {
+		errorf("❌ [升级] 回滚失败, 继续运行当前版本: %v", err)
+		os.Remove(a.exe + ".update")
+		a.trial = nil
+		return
+	}
+	writeJSON(a.exe+".update-failed", &updateFailure{Version: st.To, Reason: reason})
+	os.Remove(a.exe + ".update")
+	if err := reexec(a.exe); err != nil {
+		errorf("❌ [升级] 重新执行 %s 失败: %v", a.exe, err)
+	}
+}
+
+// prepareUpdate 下载并校验新版本, 成功后结束当前心跳流, 由 Run 等任务跑完再安装
+func (a *Agent) prepareUpdate(ctx context.Context, upd *pb.AgentUpdate) {
+	if a.exe == "" || upd.Version == Version {
+		return
+	}
+	if a.updateFailure != nil && a.updateFailure.Version == upd.Version {
+		warnf("⚠️ [升级] 版本 %s 之前已经回滚过, 忽略", upd.Version)
+		return
+	}
+	// 配置了公钥时升级指令必须带有效签名, 否则能改数据库的人就能给所有 Agent 换二进制
+	if a.pubKey != nil {
+		if err := jobsign.VerifyRelease(a.pubKey, upd, runtime.GOOS, runtime.GOARCH); err != nil {
+			errorf("⛔ [升级] 拒绝升级到 %s: %v", upd.Version, err)
+			return
+		}
+	}
+	if !a.updating.CompareAndSwap(false, true) {
+		return
+	}
+
+	path, err := a.download(ctx, upd)
+	if err != nil {
+		errorf("❌ [升级] 下载 %s 失败: %v", upd.Version, err)
+		a.updating.Store(false)
+		return
+	}
+	infof("📦 [升级] 版本 %s 已下载并校验, 等待正在执行的任务结束后安装", upd.Version)
+
+	a.mu.Lock()
+	a.staged = &stagedUpdate{version: upd.Version, path: path}
+	cancel := a.cancelSession
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (a *Agent) download(ctx context.Context, upd *pb.AgentUpdate) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	stream, err := a.client.DownloadAgent(ctx, &pb.DownloadAgentReq{
+		Version: upd.Version,
+		Os:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(a.exe), ".agent-update-*")
+	if err != nil {
+		return "", err
+	}
+	ok := false
+	defer func() {
+		if !ok {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	h := sha256.New()
+	var size int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		size += int64(len(chunk.Data))
+		if size > upd.Size {
+			return "", fmt.Errorf("binary larger than announced %d bytes", upd.Size)
+		}
+		h.Write(chunk.Data)
+		if _, err := tmp.Write(chunk.Data); err != nil {
+			return "", err
+		}
+	}
+	if size != upd.Size {
+		return "", fmt.Errorf("size mismatch: got %d, want %d", size, upd.Size)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != upd.Sha256 {
+		return "", fmt.Errorf("checksum mismatch: got %s, want %s", sum, upd.Sha256)
+	}
+	if err := tmp.Chmod(0o755); err != nil {
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	ok = true
+	return tmp.Name(), nil
+}
+
+// install 保留旧版本为 <exe>.prev, 原子替换可执行文件, 写升级标记后重新执行; 成功时不会返回
+func (a *Agent) install(st *stagedUpdate) error {
+	prev := a.exe + ".prev"
+	os.Remove(prev)
+	if err := os.Link(a.exe, prev); err != nil {
+		if err := copyFile(a.exe, prev); err != nil {
+			return fmt.Errorf("back up current binary: %w", err)
+		}
+	}
+	state := &updateState{From: Version, To: st.version}
+	if err := writeJSON(a.exe+".update", state); err != nil {
+		return err
+	}
+	if err := os.Rename(st.path, a.exe); err != nil {
+		os.Remove(a.exe + ".update")
+		return fmt.Errorf("replace binary: %w", err)
+	}
+	infof("🔄 [升级] 已安装 %s, 重新启动...", st.version)
+	err := reexec(a.exe)
+	// 走到这里说明 exec 失败了, 立即换回旧版本
+	a.rollback(state, fmt.Sprintf("exec %s: %v", st.version, err))
+	return err
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+var errReexecUnsupported = errors.New("re-exec is not supported on this platform")
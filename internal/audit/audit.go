@@ -0,0 +1,221 @@
+// Package audit 只追加的操作审计日志. 每条记录带上一条的哈希, 组成一条哈希链:
+// 改动、删除或插入任何一条历史记录都会让之后的链校验失败.
+// 能直接写库的人可以重算整条链, 所以要定期把 Export 的结果 (至少是最新的 seq + hash) 存到库外做锚点.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 哈希内容的域分隔前缀, 格式变了就换版本号
+const hashPrefix = "sentinel-audit-v1"
+
+// 链的起点, 第一条记录的 PrevHash
+var genesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// Entry 一条审计记录. Seq 从 1 开始连续递增, 断号即说明有记录被删.
+type Entry struct {
+	Seq         uint64    `gorm:"primaryKey;autoIncrement:false" json:"seq"`
+	Time        time.Time `gorm:"type:datetime(3);index" json:"time"`
+	Actor       string    `gorm:"size:191;index" json:"actor"` // 用户名或 agent:<id>, 认证失败时为空
+	SourceIP    string    `gorm:"size:64" json:"source_ip"`
+	Action      string    `gorm:"size:191;index" json:"action"` // 例如 "POST /job/:id/approve", "agent.register"
+	Target      string    `json:"target"`
+	PayloadHash string    `gorm:"size:64" json:"payload_hash"` // 请求体的 sha256, 没有请求体时为空
+	Result      string    `json:"result"`
+	PrevHash    string    `gorm:"size:64" json:"prev_hash"`
+	Hash        string    `gorm:"size:64" json:"hash"`
+}
+
+func (Entry) TableName() string { return "audit_log" }
+
+// Head 链头, 只有一行. 追加时对它加行锁, 保证多个实例写入时链不会分叉; 它也让尾部被截断的情况可以被发现.
+type Head struct {
+	ID   uint   `gorm:"primaryKey"`
+	Seq  uint64 `gorm:"not null;default:0"`
+	Hash string `gorm:"size:64"`
+}
+
+func (Head) TableName() string { return "audit_head" }
+
+const headID = 1
+
+// computeHash 覆盖除 Hash 以外的全部字段, 各字段带长度前缀避免拼接歧义
+func computeHash(e *Entry) string {
+	var b []byte
+	b = append(b, hashPrefix...)
+	b = binary.BigEndian.AppendUint64(b, e.Seq)
+	b = binary.BigEndian.AppendUint64(b, uint64(e.Time.UnixMilli()))
+	for _, f := range []string{e.Actor, e.SourceIP, e.Action, e.Target, e.PayloadHash, e.Result, e.PrevHash} {
+		b = binary.BigEndian.AppendUint32(b, uint32(len(f)))
+		b = append(b, f...)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashPayload 请求体摘要, 空内容返回 ""
+func HashPayload(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type Log struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Log {
+	return &Log{db: db}
+}
+
+// Append 填好 Seq / Time / PrevHash / Hash 后写入
+func (l *Log) Append(ctx context.Context, e *Entry) error {
+	db := l.db.WithContext(ctx)
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&Head{ID: headID, Hash: genesisHash}).Error
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var head Head
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&head, headID).Error; err != nil {
+			return err
+		}
+		e.Seq = head.Seq + 1
+		e.Time = time.Now().Truncate(time.Millisecond) // 和 datetime(3) 的精度一致, 读回来哈希不变
+		e.PrevHash = head.Hash
+		e.Hash = computeHash(e)
+		if err := tx.Create(e).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Head{}).Where("id = ?", headID).
+			Updates(map[string]any{"seq": e.Seq, "hash": e.Hash}).Error
+	})
+}
+
+// Filter 查询条件, 零值字段不参与过滤
+type Filter struct {
+	Actor    string
+	Action   string
+	Target   string
+	Since    time.Time
+	Until    time.Time
+	AfterSeq uint64 // 翻页游标, 返回 seq 大于它的记录
+	Limit    int
+}
+
+func (f Filter) apply(q *gorm.DB) *gorm.DB {
+	if f.Actor != "" {
+		q = q.Where("actor = ?", f.Actor)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.Target != "" {
+		q = q.Where("target = ?", f.Target)
+	}
+	if !f.Since.IsZero() {
+		q = q.Where("time >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		q = q.Where("time < ?", f.Until)
+	}
+	if f.AfterSeq > 0 {
+		q = q.Where("seq > ?", f.AfterSeq)
+	}
+	return q.Order("seq")
+}
+
+// Query 按 seq 升序返回一页
+func (l *Log) Query(ctx context.Context, f Filter) ([]Entry, error) {
+	var entries []Entry
+	err := f.apply(l.db.WithContext(ctx).Model(&Entry{})).Limit(f.Limit).Find(&entries).Error
+	return entries, err
+}
+
+const exportBatchSize = 500
+
+// Export 按 seq 升序分批读出全部命中的记录交给 fn, Limit 被忽略
+func (l *Log) Export(ctx context.Context, f Filter, fn func(*Entry) error) error {
+	f.Limit = exportBatchSize
+	for {
+		entries, err := l.Query(ctx, f)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			if err := fn(&entries[i]); err != nil {
+				return err
+			}
+		}
+		if len(entries) < exportBatchSize {
+			return nil
+		}
+		f.AfterSeq = entries[len(entries)-1].Seq
+	}
+}
+
+// VerifyResult Valid 为 false 时 BrokenAt 是第一条校验不通过的 seq
+type VerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Entries  uint64 `json:"entries"`
+	HeadSeq  uint64 `json:"head_seq"`
+	HeadHash string `json:"head_hash"`
+	BrokenAt uint64 `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify 从头重算整条链, 并和链头比对
+func (l *Log) Verify(ctx context.Context) (*VerifyResult, error) {
+	var head Head
+	err := l.db.WithContext(ctx).First(&head, headID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		head = Head{Hash: genesisHash}
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := &VerifyResult{Valid: true, HeadSeq: head.Seq, HeadHash: head.Hash}
+	prev := genesisHash
+	broken := func(seq uint64, format string, args ...any) {
+		if res.Valid {
+			res.Valid, res.BrokenAt, res.Reason = false, seq, fmt.Sprintf(format, args...)
+		}
+	}
+	err = l.Export(ctx, Filter{}, func(e *Entry) error {
+		res.Entries++
+		switch {
+		case e.Seq != res.Entries:
+			broken(res.Entries, "entry %d is missing", res.Entries)
+		case e.PrevHash != prev:
+			broken(e.Seq, "prev_hash does not match entry %d", e.Seq-1)
+		case computeHash(e) != e.Hash:
+			broken(e.Seq, "content does not match its hash")
+		}
+		prev = e.Hash
+		if !res.Valid {
+			return errStop
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStop) {
+		return nil, err
+	}
+	if res.Valid && (res.Entries != head.Seq || prev != head.Hash) {
+		broken(res.Entries+1, "chain ends at entry %d but head records entry %d", res.Entries, head.Seq)
+	}
+	return res, nil
+}
+
+var errStop = errors.New("stop")
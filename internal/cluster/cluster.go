@@ -0,0 +1,120 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Message 实例之间转发的指令. 只携带 ID, 任务内容以 MySQL 里的 JobRecord 为准.
+type Message struct {
+	Kind    string `json:"kind"`
+	AgentID string `json:"agent_id"`
+	JobID   string `json:"job_id"`
+	From    string `json:"from"`
+}
+
+const (
+	KindPush    = "push"    // 有新任务, 请从库里取出来塞进信箱
+	KindCancel  = "cancel"  // 通知 Agent 取消正在执行的任务
+	KindRefresh = "refresh" // Agent 的远程配置或目标版本变了, 请重新计算
+)
+
+// Registry 记录每个 Agent 的心跳流挂在哪个实例上
+type Registry interface {
+	// Claim 声明本实例持有该 Agent 的心跳流, 每次心跳都会调用以续期
+	Claim(ctx context.Context, agentID string) error
+	// Release 心跳流断开时调用, 只会删除属于本实例的记录
+	Release(ctx context.Context, agentID string) error
+	// Owner 返回持有该 Agent 的实例 ID, 没有实例持有时返回 ""
+	Owner(ctx context.Context, agentID string) (string, error)
+}
+
+// Bus 把 Message 投递给指定实例
+type Bus interface {
+	Send(ctx context.Context, instanceID string, msg Message) error
+	// Listen 阻塞接收发给本实例的消息, 直到 ctx 结束
+	Listen(ctx context.Context, handler func(Message)) error
+}
+
+// Node 当前实例在集群中的身份
+type Node struct {
+	ID       string
+	Registry Registry
+	Bus      Bus
+}
+
+// DefaultInstanceID 主机名 + 随机后缀, 同一台机器起多个实例也不会冲突
+func DefaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "sentinel"
+	}
+	return fmt.Sprintf("%s-%s", host, uuid.NewString()[:8])
+}
+
+// NewLocalNode 单实例模式: 注册表和消息都只在进程内
+func NewLocalNode(id string) *Node {
+	return &Node{
+		ID:       id,
+		Registry: &localRegistry{id: id, owners: make(map[string]string)},
+		Bus:      &localBus{id: id, ch: make(chan Message, 256)},
+	}
+}
+
+type localRegistry struct {
+	id     string
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+func (r *localRegistry) Claim(ctx context.Context, agentID string) error {
+	r.mu.Lock()
+	r.owners[agentID] = r.id
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *localRegistry) Release(ctx context.Context, agentID string) error {
+	r.mu.Lock()
+	delete(r.owners, agentID)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *localRegistry) Owner(ctx context.Context, agentID string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.owners[agentID], nil
+}
+
+type localBus struct {
+	id string
+	ch chan Message
+}
+
+func (b *localBus) Send(ctx context.Context, instanceID string, msg Message) error {
+	if instanceID != b.id {
+		return fmt.Errorf("unknown instance %s (single-instance mode)", instanceID)
+	}
+	select {
+	case b.ch <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *localBus) Listen(ctx context.Context, handler func(Message)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-b.ch:
+			handler(msg)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaderFence 业务库里每个选举名见过的最大 fencing token.
+// 租约可能在 Redis 里, 所以单独放一行: 受保护的写操作和它在同一个事务里, 先锁住这一行再比较 token,
+// 新 leader 当选时先把它推到自己的 token, 之后旧 leader 的写事务一律失败.
+// Redis 数据丢失导致 token 计数器回退时, 需要手动删掉对应的行.
+type LeaderFence struct {
+	Name  string `gorm:"primaryKey;size:191"`
+	Token int64  `gorm:"not null;default:0"`
+}
+
+// Fence 在业务数据库里校验 fencing token
+type Fence struct {
+	db *gorm.DB
+}
+
+func NewFence(db *gorm.DB) *Fence {
+	return &Fence{db: db}
+}
+
+// Advance 新 leader 启动任务之前调用, 把记录推到 token; 记录已经更大说明租约早就被接管了
+func (f *Fence) Advance(ctx context.Context, name string, token int64) error {
+	return f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return f.guard(tx, name, token)
+	})
+}
+
+// guard 锁住 fence 行并确认 token 不比记录的小, 必要时推进记录. 锁一直持有到事务结束.
+func (f *Fence) guard(tx *gorm.DB, name string, token int64) error {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&LeaderFence{Name: name}).Error
+	if err != nil {
+		return err
+	}
+	var row LeaderFence
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).First(&row).Error; err != nil {
+		return err
+	}
+	if row.Token > token {
+		return ErrLeaseLost
+	}
+	if row.Token < token {
+		return tx.Model(&LeaderFence{}).Where("name = ?", name).Update("token", token).Error
+	}
+	return nil
+}
+
+// Fenced 在一个事务里先校验 fencing token 再执行 fn. 旧 leader 在 Check 之后卡住也没用,
+// 新 leader 当选时已经推进了 fence, 旧 token 的事务拿到行锁后会返回 ErrLeaseLost 并回滚.
+func (e *Elector) Fenced(ctx context.Context, token int64, fn func(tx *gorm.DB) error) error {
+	if err := e.Check(ctx, token); err != nil {
+		return err
+	}
+	return e.fence.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := e.fence.guard(tx, e.name, token); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
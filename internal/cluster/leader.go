@@ -0,0 +1,191 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrLeaseLost = errors.New("leader lease lost")
+
+// Lease 一次当选的租约. Token 在同一个 name 下单调递增, 作为 fencing token:
+// 旧 leader 即使"以为"自己还在位, 它手里的 token 也一定比新 leader 的小.
+type Lease struct {
+	Name      string
+	Holder    string
+	Token     int64
+	ExpiresAt time.Time
+}
+
+// LeaseStore 租约的存储后端 (Redis / MySQL)
+type LeaseStore interface {
+	// Acquire 租约空闲/过期时抢占并返回新 token; 自己已持有时只续期, token 不变.
+	// 被别人持有时返回 ok=false.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (lease Lease, ok bool, err error)
+	// Renew 续期, 租约已不属于 (holder, token) 时返回 ErrLeaseLost
+	Renew(ctx context.Context, name, holder string, token int64, ttl time.Duration) error
+	// Release 主动让位, 让其他实例不必等到过期
+	Release(ctx context.Context, name, holder string, token int64) error
+	// Current 返回当前租约 (可能已过期或为空)
+	Current(ctx context.Context, name string) (Lease, error)
+}
+
+// LeaderTask 只在 leader 上运行的任务. ctx 结束代表失去领导权或进程退出, 任务必须尽快返回.
+type LeaderTask func(ctx context.Context, token int64)
+
+type namedTask struct {
+	name string
+	run  LeaderTask
+}
+
+// Elector 周期性竞选, 当选后启动所有注册的任务, 失去租约前把它们全部停掉
+type Elector struct {
+	store LeaseStore
+	fence *Fence
+	name  string
+	id    string
+	ttl   time.Duration
+
+	mu     sync.Mutex
+	tasks  []namedTask
+	token  int64
+	leader bool
+}
+
+func NewElector(store LeaseStore, fence *Fence, name, id string, ttl time.Duration) *Elector {
+	return &Elector{store: store, fence: fence, name: name, id: id, ttl: ttl}
+}
+
+// Register 注册 leader 专属任务, 需要在 Run 之前调用
+func (e *Elector) Register(name string, task LeaderTask) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tasks = append(e.tasks, namedTask{name: name, run: task})
+}
+
+// IsLeader 返回本实例当前是否为 leader 以及对应的 fencing token
+func (e *Elector) IsLeader() (bool, int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader, e.token
+}
+
+// Check 确认 token 仍然是最新租约. 只能用来提前放弃, 写操作要用 Fenced
+func (e *Elector) Check(ctx context.Context, token int64) error {
+	cur, err := e.store.Current(ctx, e.name)
+	if err != nil {
+		return err
+	}
+	if cur.Holder != e.id || cur.Token != token || time.Now().After(cur.ExpiresAt) {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Run 在后台运行选举循环直到 ctx 结束. 返回的 channel 在任务全部停掉、租约释放之后关闭.
+func (e *Elector) Run(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.loop(ctx)
+	}()
+	return done
+}
+
+func (e *Elector) loop(ctx context.Context) {
+	interval := e.ttl / 3
+	for {
+		lease, ok, err := e.store.Acquire(ctx, e.name, e.id, e.ttl)
+		if err != nil {
+			log.Printf("[Leader] 竞选 %s 失败: %v", e.name, err)
+		} else if ok {
+			e.lead(ctx, lease)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// lead 持有租约期间的主循环: 启动任务 -> 定期续期 -> 续不上或 ctx 结束就停任务
+func (e *Elector) lead(ctx context.Context, lease Lease) {
+	log.Printf("[Leader] 实例 %s 当选 %s (token=%d)", e.id, e.name, lease.Token)
+	// 先推进 fence, 旧 leader 还没提交的写操作从这一刻起都会失败
+	if err := e.fence.Advance(ctx, e.name, lease.Token); err != nil {
+		log.Printf("[Leader] 推进 %s 的 fencing token 失败, 放弃本次当选: %v", e.name, err)
+		e.release(lease)
+		return
+	}
+	e.setLeader(true, lease.Token)
+
+	leadCtx, stop := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	e.mu.Lock()
+	tasks := append([]namedTask(nil), e.tasks...)
+	e.mu.Unlock()
+	for _, t := range tasks {
+		wg.Add(1)
+		go func(t namedTask) {
+			defer wg.Done()
+			log.Printf("[Leader] 启动任务 %s", t.name)
+			t.run(leadCtx, lease.Token)
+			log.Printf("[Leader] 任务 %s 已停止", t.name)
+		}(t)
+	}
+
+	// 留出 1/3 ttl 的余量: 续期迟迟不成功时, 在租约真正过期之前就主动停掉任务
+	interval := e.ttl / 3
+	deadline := time.Now().Add(e.ttl - interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			rctx, cancel := context.WithTimeout(ctx, interval)
+			err := e.store.Renew(rctx, e.name, e.id, lease.Token, e.ttl)
+			cancel()
+			if err == nil {
+				deadline = time.Now().Add(e.ttl - interval)
+				continue
+			}
+			if errors.Is(err, ErrLeaseLost) {
+				log.Printf("[Leader] %s 的租约已被接管", e.name)
+				break loop
+			}
+			log.Printf("[Leader] 续期 %s 失败: %v", e.name, err)
+			if time.Now().After(deadline) {
+				log.Printf("[Leader] 续期超时, 主动让出 %s", e.name)
+				break loop
+			}
+		}
+	}
+
+	stop()
+	wg.Wait()
+	e.setLeader(false, 0)
+	e.release(lease)
+	log.Printf("[Leader] 实例 %s 已卸任 %s", e.id, e.name)
+}
+
+func (e *Elector) release(lease Lease) {
+	rctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := e.store.Release(rctx, e.name, e.id, lease.Token); err != nil && !errors.Is(err, ErrLeaseLost) {
+		log.Printf("[Leader] 释放 %s 租约失败: %v", e.name, err)
+	}
+}
+
+func (e *Elector) setLeader(leader bool, token int64) {
+	e.mu.Lock()
+	e.leader, e.token = leader, token
+	e.mu.Unlock()
+}
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaderLease MySQL 里的租约行, 过期判断统一用数据库的 NOW(3), 避免各实例时钟不一致
+type LeaderLease struct {
+	Name      string    `gorm:"primaryKey;size:191"`
+	Holder    string    `gorm:"size:191"`
+	Token     int64     `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"type:datetime(3)"`
+}
+
+// MySQLLeaseStore 没有 Redis 时的租约后端, 靠条件 UPDATE 的原子性保证只有一个实例抢到
+type MySQLLeaseStore struct {
+	db *gorm.DB
+}
+
+func NewMySQLLeaseStore(db *gorm.DB) *MySQLLeaseStore {
+	return &MySQLLeaseStore{db: db}
+}
+
+func expiresIn(ttl time.Duration) clause.Expr {
+	return gorm.Expr("NOW(3) + INTERVAL ? MICROSECOND", ttl.Microseconds())
+}
+
+func (m *MySQLLeaseStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (Lease, bool, error) {
+	db := m.db.WithContext(ctx)
+
+	// 第一次竞选时先插一行空租约
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&LeaderLease{Name: name, ExpiresAt: time.Unix(0, 0)}).Error
+	if err != nil {
+		return Lease{}, false, err
+	}
+
+	// 自己还在位: 只续期
+	res := db.Model(&LeaderLease{}).
+		Where("name = ? AND holder = ? AND expires_at > NOW(3)", name, holder).
+		Update("expires_at", expiresIn(ttl))
+	if res.Error != nil {
+		return Lease{}, false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		// 空闲或已过期: 抢占并递增 token
+		res = db.Model(&LeaderLease{}).
+			Where("name = ? AND (holder = '' OR expires_at <= NOW(3))", name).
+			Updates(map[string]any{
+				"holder":     holder,
+				"token":      gorm.Expr("token + 1"),
+				"expires_at": expiresIn(ttl),
+			})
+		if res.Error != nil {
+			return Lease{}, false, res.Error
+		}
+		if res.RowsAffected == 0 {
+			return Lease{}, false, nil
+		}
+	}
+
+	lease, err := m.Current(ctx, name)
+	if err != nil {
+		return Lease{}, false, err
+	}
+	if lease.Holder != holder {
+		return Lease{}, false, nil
+	}
+	return lease, true, nil
+}
+
+func (m *MySQLLeaseStore) Renew(ctx context.Context, name, holder string, token int64, ttl time.Duration) error {
+	res := m.db.WithContext(ctx).Model(&LeaderLease{}).
+		Where("name = ? AND holder = ? AND token = ? AND expires_at > NOW(3)", name, holder, token).
+		Update("expires_at", expiresIn(ttl))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (m *MySQLLeaseStore) Release(ctx context.Context, name, holder string, token int64) error {
+	res := m.db.WithContext(ctx).Model(&LeaderLease{}).
+		Where("name = ? AND holder = ? AND token = ?", name, holder, token).
+		Updates(map[string]any{"holder": "", "expires_at": gorm.Expr("NOW(3)")})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (m *MySQLLeaseStore) Current(ctx context.Context, name string) (Lease, error) {
+	var row LeaderLease
+	if err := m.db.WithContext(ctx).Where("name = ?", name).Limit(1).Find(&row).Error; err != nil {
+		return Lease{}, err
+	}
+	return Lease{Name: name, Holder: row.Holder, Token: row.Token, ExpiresAt: row.ExpiresAt}, nil
+}
@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const leaseKeyPrefix = "sentinel:leader:"
+
+var (
+	// KEYS[1]=租约 hash, KEYS[2]=token 计数器; ARGV[1]=holder, ARGV[2]=ttl(ms)
+	acquireLeaseScript = redis.NewScript(`
+local cur = redis.call("HGET", KEYS[1], "holder")
+if cur and cur ~= ARGV[1] then
+	return {0, 0}
+end
+if cur == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return {1, tonumber(redis.call("HGET", KEYS[1], "token"))}
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("HSET", KEYS[1], "holder", ARGV[1], "token", token)
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return {1, token}`)
+
+	// ARGV[1]=holder, ARGV[2]=token, ARGV[3]=ttl(ms), ttl 为 0 表示删除
+	renewLeaseScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "holder") ~= ARGV[1] or redis.call("HGET", KEYS[1], "token") ~= ARGV[2] then
+	return 0
+end
+if ARGV[3] == "0" then
+	return redis.call("DEL", KEYS[1])
+end
+return redis.call("PEXPIRE", KEYS[1], ARGV[3])`)
+)
+
+// RedisLeaseStore 租约是一个带 TTL 的 hash, token 来自独立的 INCR 计数器 (不设过期)
+type RedisLeaseStore struct {
+	client *redis.Client
+}
+
+func NewRedisLeaseStore(client *redis.Client) *RedisLeaseStore {
+	return &RedisLeaseStore{client: client}
+}
+
+func (r *RedisLeaseStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (Lease, bool, error) {
+	key := leaseKeyPrefix + name
+	vals, err := acquireLeaseScript.Run(ctx, r.client, []string{key, key + ":token"}, holder, ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Lease{}, false, err
+	}
+	if vals[0] == 0 {
+		return Lease{}, false, nil
+	}
+	return Lease{Name: name, Holder: holder, Token: vals[1], ExpiresAt: time.Now().Add(ttl)}, true, nil
+}
+
+func (r *RedisLeaseStore) Renew(ctx context.Context, name, holder string, token int64, ttl time.Duration) error {
+	return r.update(ctx, name, holder, token, ttl.Milliseconds())
+}
+
+func (r *RedisLeaseStore) Release(ctx context.Context, name, holder string, token int64) error {
+	return r.update(ctx, name, holder, token, 0)
+}
+
+func (r *RedisLeaseStore) update(ctx context.Context, name, holder string, token, ttlMillis int64) error {
+	n, err := renewLeaseScript.Run(ctx, r.client, []string{leaseKeyPrefix + name},
+		holder, strconv.FormatInt(token, 10), strconv.FormatInt(ttlMillis, 10)).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *RedisLeaseStore) Current(ctx context.Context, name string) (Lease, error) {
+	key := leaseKeyPrefix + name
+	pipe := r.client.Pipeline()
+	fields := pipe.HGetAll(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return Lease{}, err
+	}
+	lease := Lease{Name: name, Holder: fields.Val()["holder"]}
+	lease.Token, _ = strconv.ParseInt(fields.Val()["token"], 10, 64)
+	if d := pttl.Val(); d > 0 {
+		lease.ExpiresAt = time.Now().Add(d)
+	}
+	return lease, nil
+}
@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/db"
+)
+
+const (
+	ownerKeyPrefix  = "sentinel:agent-owner:"
+	instanceChannel = "sentinel:instance:"
+)
+
+var releaseOwnerScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// NewRedisNode 多实例模式: 归属关系存在 Redis (带 TTL, 实例挂掉后自动过期), 指令走 Redis Pub/Sub
+func NewRedisNode(client *redis.Client, id string, ttl time.Duration) *Node {
+	return &Node{
+		ID:       id,
+		Registry: &redisRegistry{client: client, id: id, ttl: ttl},
+		Bus:      &redisBus{client: client, id: id},
+	}
+}
+
+type redisRegistry struct {
+	client *redis.Client
+	id     string
+	ttl    time.Duration
+}
+
+// Claim 无条件覆盖: Agent 重连到哪个实例, 哪个实例就是新的持有者
+func (r *redisRegistry) Claim(ctx context.Context, agentID string) error {
+	return r.client.Set(ctx, ownerKeyPrefix+agentID, r.id, r.ttl).Err()
+}
+
+func (r *redisRegistry) Release(ctx context.Context, agentID string) error {
+	return releaseOwnerScript.Run(ctx, r.client, []string{ownerKeyPrefix + agentID}, r.id).Err()
+}
+
+func (r *redisRegistry) Owner(ctx context.Context, agentID string) (string, error) {
+	owner, err := r.client.Get(ctx, ownerKeyPrefix+agentID).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return owner, err
+}
+
+type redisBus struct {
+	client *redis.Client
+	id     string
+}
+
+func (b *redisBus) Send(ctx context.Context, instanceID string, msg Message) error {
+	msg.From = b.id
+	return db.PublishJSON(ctx, b.client, instanceChannel+instanceID, msg)
+}
+
+func (b *redisBus) Listen(ctx context.Context, handler func(Message)) error {
+	return db.Subscribe(ctx, b.client, func(channel string, payload []byte) {
+		var msg Message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			log.Printf("[Cluster] 无法解析转发消息: %v", err)
+			return
+		}
+		handler(msg)
+	}, instanceChannel+b.id)
+}
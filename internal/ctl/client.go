@@ -0,0 +1,329 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// projectHeader 和服务端 server.ProjectHeader 一致
+const projectHeader = "X-Sentinel-Project"
+
+// APIError 服务端返回的非 2xx 响应
+type APIError struct {
+	Status     int
+	Message    string
+	RetryAfter time.Duration // 429 时服务端给出的建议等待时间
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("HTTP %d: %s", e.Status, e.Message)
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (%s 后重试)", e.RetryAfter)
+	}
+	return msg
+}
+
+// Client 管理 API 的客户端
+type Client struct {
+	base    string
+	token   string
+	project string
+	http    *http.Client
+}
+
+func NewClient(cfg *Config) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s 里没有可用的证书", cfg.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &Client{
+		base:    cfg.Server,
+		token:   cfg.Token,
+		project: cfg.Project,
+		http:    &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}, nil
+}
+
+// envelope 服务端响应的公共外壳, data 以外的字段 (next_cursor, job, jobs ...) 由调用方按需解析
+type envelope struct {
+	Error             string          `json:"error"`
+	RetryAfterSeconds int             `json:"retry_after_seconds"`
+	Data              json.RawMessage `json:"data"`
+}
+
+// do 发请求并把整个响应体解析到 out (可以为 nil)
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if c.project != "" {
+		req.Header.Set(projectHeader, c.project)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		var env envelope
+		apiErr := &APIError{Status: resp.StatusCode, Message: string(raw)}
+		if json.Unmarshal(raw, &env) == nil && env.Error != "" {
+			apiErr.Message = env.Error
+		}
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(s) * time.Second
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// data 只取响应里的 data 字段
+func (c *Client) data(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var env envelope
+	if err := c.do(ctx, method, path, query, body, &env); err != nil {
+		return err
+	}
+	return json.Unmarshal(env.Data, out)
+}
+
+// Agent 和服务端 AgentModel 的 JSON 一致 (没有 json tag, 字段名原样输出)
+type Agent struct {
+	AgentID       string
+	Project       string
+	Hostname      string
+	IP            string
+	Status        string
+	Tags          string // ",a,b," 形式
+	LastSeen      time.Time
+	Version       string
+	OS            string
+	Arch          string
+	TargetVersion string
+	UpdateError   string
+	CreatedAt     time.Time
+}
+
+// Job 和服务端 JobView 一致
+type Job struct {
+	JobID       string     `json:"job_id"`
+	AgentID     string     `json:"agent_id"`
+	Project     string     `json:"project"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	Payload     string     `json:"payload"`
+	Result      *string    `json:"result,omitempty"`
+	SubmittedBy string     `json:"submitted_by"`
+	ApprovedBy  string     `json:"approved_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ExecutedAt  *time.Time `json:"executed_at,omitempty"`
+}
+
+// Finished 任务已经到了终态
+func (j *Job) Finished() bool {
+	switch j.Status {
+	case "Success", "Failed", "Cancelled", "Rejected":
+		return true
+	}
+	return false
+}
+
+// OutputPage 和服务端 server.JobOutputPage 一致
+type OutputPage struct {
+	Chunks []struct {
+		Seq  int64  `json:"seq"`
+		Data string `json:"data"`
+	} `json:"chunks"`
+	Status   string `json:"status"`
+	Finished bool   `json:"finished"`
+}
+
+// Selector 和服务端 server.Selector 一致
+type Selector struct {
+	All      bool     `json:"all,omitempty"`
+	AgentIDs []string `json:"agent_ids,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Status   string   `json:"status,omitempty"`
+}
+
+type JobRequest struct {
+	Target   string            `json:"target,omitempty"`
+	Selector *Selector         `json:"selector,omitempty"`
+	Type     string            `json:"type"`
+	Cmd      string            `json:"cmd"`
+	Secrets  map[string]string `json:"secrets,omitempty"`
+}
+
+// Submitted POST /job 返回的一个任务
+type Submitted struct {
+	JobID   string `json:"job"`
+	AgentID string `json:"agent"`
+	Status  string `json:"status"`
+}
+
+func (c *Client) ListAgents(ctx context.Context) ([]Agent, error) {
+	var agents []Agent
+	err := c.data(ctx, http.MethodGet, "/agent", nil, nil, &agents)
+	return agents, err
+}
+
+func (c *Client) GetAgent(ctx context.Context, id string) (*Agent, error) {
+	var agent Agent
+	err := c.data(ctx, http.MethodGet, "/agent/"+url.PathEscape(id), nil, nil, &agent)
+	return &agent, err
+}
+
+// AgentConfig 生效配置原样返回, 字段随服务端变化, 不在这里定义结构
+func (c *Client) AgentConfig(ctx context.Context, id string) (json.RawMessage, error) {
+	var cfg json.RawMessage
+	err := c.data(ctx, http.MethodGet, "/agent/"+url.PathEscape(id)+"/config", nil, nil, &cfg)
+	return cfg, err
+}
+
+// SubmitJob 单个 target 和 selector 两种提交方式统一返回任务列表
+func (c *Client) SubmitJob(ctx context.Context, req JobRequest) ([]Submitted, error) {
+	var resp struct {
+		Submitted
+		Jobs []Submitted `json:"jobs"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/job", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	if req.Selector != nil {
+		return resp.Jobs, nil
+	}
+	resp.Submitted.AgentID = req.Target
+	return []Submitted{resp.Submitted}, nil
+}
+
+func (c *Client) GetJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	err := c.data(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil, nil, &job)
+	return &job, err
+}
+
+// JobOutput seq 大于 after 的增量输出
+func (c *Client) JobOutput(ctx context.Context, id string, after int64) (*OutputPage, error) {
+	var page OutputPage
+	query := url.Values{"after": {strconv.FormatInt(after, 10)}}
+	err := c.data(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id)+"/output", query, nil, &page)
+	return &page, err
+}
+
+// TailJob 每隔 interval 拉一次增量输出交给 onOutput, 直到任务结束并且输出拉完, 返回结束时的任务.
+func (c *Client) TailJob(ctx context.Context, id string, interval time.Duration, onOutput func(string)) (*Job, error) {
+	var after int64
+	for {
+		page, err := c.JobOutput(ctx, id, after)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range page.Chunks {
+			onOutput(chunk.Data)
+			after = chunk.Seq
+		}
+		if page.Finished {
+			return c.GetJob(ctx, id)
+		}
+		if len(page.Chunks) > 0 {
+			continue // 可能还有下一页
+		}
+		select {
+		case <-ctx.Done():
+			return &Job{JobID: id, Status: page.Status}, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ListJobs agent 不为空时查这台 Agent 的任务. 返回下一页的游标, 为空表示没有了.
+func (c *Client) ListJobs(ctx context.Context, agent string, query url.Values) ([]Job, string, error) {
+	path := "/jobs"
+	if agent != "" {
+		path = "/agent/" + url.PathEscape(agent) + "/jobs"
+	}
+	var resp struct {
+		Data       []Job  `json:"data"`
+		NextCursor string `json:"next_cursor"`
+	}
+	err := c.do(ctx, http.MethodGet, path, query, nil, &resp)
+	return resp.Data, resp.NextCursor, err
+}
+
+func (c *Client) CancelJob(ctx context.Context, id string) (string, error) {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	err := c.do(ctx, http.MethodPost, "/job/"+url.PathEscape(id)+"/cancel", nil, nil, &resp)
+	return resp.Status, err
+}
+
+// WaitJob 每隔 interval 查一次, 直到任务结束或 ctx 结束. onChange 在状态变化时调用, 可以为 nil.
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration, onChange func(*Job)) (*Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := ""
+	for {
+		job, err := c.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Status != last && onChange != nil {
+			onChange(job)
+		}
+		last = job.Status
+		if job.Finished() {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
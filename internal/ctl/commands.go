@@ -0,0 +1,500 @@
+package ctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// 退出码: 0 成功, 1 出错或等到的任务没有成功, 2 用法不对
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// errJobsFailed 等到的任务里有没成功的, 输出已经打印过, 只需要非 0 退出
+var errJobsFailed = errors.New("some jobs did not succeed")
+
+type command struct {
+	usage string // 第一行是参数格式, 后面是说明
+	flags func(fs *pflag.FlagSet)
+	run   func(ctx context.Context, e *env, fs *pflag.FlagSet) error
+}
+
+// env 子命令运行时的上下文
+type env struct {
+	cfg    *Config
+	client *Client
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (e *env) json() bool { return e.cfg.Output == OutputJSON }
+
+var commands = map[string]*command{
+	"agents": {
+		usage: "agents [--status Online|Offline] [--tags a,b]\n列出当前项目的 Agent",
+		flags: func(fs *pflag.FlagSet) {
+			fs.String("status", "", "只看这个状态")
+			fs.StringSlice("tags", nil, "只看同时带有这些标签的")
+		},
+		run: runAgents,
+	},
+	"agent": {
+		usage: "agent <agent-id>\n查看一个 Agent 的详情、生效配置和最近的任务",
+		run:   runAgent,
+	},
+	"run": {
+		usage: "run (--agent ID... | --tags a,b | --status S | --all) [--type SHELL] [--secret ENV=name]... [--wait] -- <command>\n" +
+			"提交任务. 只有一个 --agent 时发给这台 Agent, 否则按 selector 给命中的每台 Agent 各发一个",
+		flags: func(fs *pflag.FlagSet) {
+			fs.StringSliceP("agent", "a", nil, "目标 Agent, 可以重复")
+			fs.StringSlice("tags", nil, "按标签挑选 Agent")
+			fs.String("status", "", "按状态挑选 Agent (Online / Offline)")
+			fs.Bool("all", false, "当前项目的全部 Agent")
+			fs.StringP("type", "t", "SHELL", "任务类型: SHELL / PING / SCAN")
+			fs.StringToString("secret", nil, "注入密钥: 环境变量名=密钥名, 可以重复")
+			waitFlags(fs)
+			fs.BoolP("wait", "w", false, "等任务结束并打印输出")
+		},
+		run: runRun,
+	},
+	"wait": {
+		usage: "wait <job-id>...\n等任务结束, 有任务没成功时退出码为 1",
+		flags: waitFlags,
+		run:   runWait,
+	},
+	"logs": {
+		usage: "logs <job-id> [-f]\n打印任务输出. -f 在任务执行过程中持续打印 Agent 上报的输出, 直到任务结束",
+		flags: func(fs *pflag.FlagSet) {
+			fs.BoolP("follow", "f", false, "任务没结束就持续打印新的输出")
+			waitFlags(fs)
+		},
+		run: runLogs,
+	},
+	"cancel": {
+		usage: "cancel <job-id>...\n取消还没执行完的任务",
+		run:   runCancel,
+	},
+	"history": {
+		usage: "history [--agent ID] [--status S,...] [--type T] [--since 24h] [--until T] [-q text] [--sort -created_at] [--limit N] [--cursor C]\n查看任务历史",
+		flags: func(fs *pflag.FlagSet) {
+			fs.StringP("agent", "a", "", "只看这台 Agent")
+			fs.StringSlice("status", nil, "只看这些状态")
+			fs.StringP("type", "t", "", "只看这种任务类型")
+			fs.String("submitted-by", "", "只看这个用户提交的")
+			fs.String("since", "", "起始时间, RFC3339 或者相对现在的时长 (例如 24h)")
+			fs.String("until", "", "结束时间, 格式同 --since")
+			fs.StringP("query", "q", "", "在命令和输出里搜索文本")
+			fs.String("sort", "", "排序: created_at / updated_at / executed_at, 前缀 - 表示降序")
+			fs.Int("limit", 20, "每页条数")
+			fs.String("cursor", "", "上一页给出的游标")
+		},
+		run: runHistory,
+	},
+}
+
+func waitFlags(fs *pflag.FlagSet) {
+	fs.Duration("timeout", 0, "最多等多久, 0 表示一直等")
+	fs.Duration("interval", 2*time.Second, "查询任务状态的间隔")
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "用法: sentinelctl <命令> [参数]")
+	fmt.Fprintln(w)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines := strings.SplitN(commands[name].usage, "\n", 2)
+		fmt.Fprintf(w, "  %-10s %s\n", name, lines[len(lines)-1])
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "通用参数: --server --token --project/-p --output/-o table|json --config --ca-file")
+	fmt.Fprintln(w, "配置文件默认读 "+DefaultConfigPath()+", 环境变量用 SENTINELCTL_ 前缀 (例如 SENTINELCTL_TOKEN)")
+	fmt.Fprintln(w, "sentinelctl <命令> --help 查看每个命令的参数")
+}
+
+// Main 执行一条命令, 返回退出码
+func Main(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "未知命令 %q\n\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+
+	fs := pflag.NewFlagSet("sentinelctl "+args[0], pflag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "用法: sentinelctl "+cmd.usage)
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+	addGlobalFlags(fs)
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(fs)
+	if err != nil {
+		fmt.Fprintln(stderr, "错误:", err)
+		return exitUsage
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		fmt.Fprintln(stderr, "错误:", err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = cmd.run(ctx, &env{cfg: cfg, client: client, stdout: stdout, stderr: stderr}, fs)
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(stderr, "错误:", err)
+		fs.Usage()
+		return exitUsage
+	case errors.Is(err, errJobsFailed):
+		return exitError
+	default:
+		fmt.Fprintln(stderr, "错误:", err)
+		return exitError
+	}
+}
+
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func runAgents(ctx context.Context, e *env, fs *pflag.FlagSet) error {
+	agents, err := e.client.ListAgents(ctx)
+	if err != nil {
+		return err
+	}
+	status, _ := fs.GetString("status")
+	tags, _ := fs.GetStringSlice("tags")
+	filtered := agents[:0]
+	for _, a := range agents {
+		if status != "" && !strings.EqualFold(a.Status, status) {
+			continue
+		}
+		if !hasTags(a.Tags, tags) {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].AgentID < filtered[j].AgentID })
+	if e.json() {
+		return printJSON(e.stdout, filtered)
+	}
+	return printAgents(e.stdout, filtered)
+}
+
+func hasTags(agentTags string, want []string) bool {
+	for _, t := range want {
+		if !strings.Contains(agentTags, ","+t+",") {
+			return false
+		}
+	}
+	return true
+}
+
+func runAgent(ctx context.Context, e *env, fs *pflag.FlagSet) error {
+	if fs.NArg() != 1 {
+		return usageError("需要一个 Agent ID")
+	}
+	id := fs.Arg(0)
+	agent, err := e.client.GetAgent(ctx, id)
+	if err != nil {
+		return err
+	}
+	cfg, err := e.client.AgentConfig(ctx, id)
+	if err != nil {
+		return err
+	}
+	jobs, _, err := e.client.ListJobs(ctx, id, url.Values{"limit": {"5"}})
+	if err != nil {
+		return err
+	}
+	if e.json() {
+		return printJSON(e.stdout, map[string]any{"agent": agent, "config": cfg, "recent_jobs": jobs})
+	}
+	if err := printAgent(e.stdout, agent); err != nil {
+		return err
+	}
+	fmt.Fprintln(e.stdout, "\nEffective config:")
+	var pretty map[string]any
+	if json.Unmarshal(cfg, &pretty) == nil {
+		if err := printJSON(e.stdout, pretty); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(e.stdout, "\nRecent jobs:")
+	return printJobs(e.stdout, jobs)
+}
+
+func runRun(ctx context.Context, e *env, fs *pflag.FlagSet) error {
+	if fs.NArg() == 0 {
+		return usageError("需要在 -- 之后给出要执行的命令")
+	}
+	agents, _ := fs.GetStringSlice("agent")
+	tags, _ := fs.GetStringSlice("tags")
+	status, _ := fs.GetString("status")
+	all, _ := fs.GetBool("all")
+	jobType, _ := fs.GetString("type")
+	secrets, _ := fs.GetStringToString("secret")
+
+	req := JobRequest{Type: strings.ToUpper(jobType), Cmd: strings.Join(fs.Args(), " "), Secrets: secrets}
+	if len(agents) == 1 && len(tags) == 0 && status == "" && !all {
+		req.Target = agents[0]
+	} else {
+		req.Selector = &Selector{All: all, AgentIDs: agents, Tags: tags, Status: status}
+		if !all && len(agents) == 0 && len(tags) == 0 && status == "" {
+			return usageError("需要 --agent / --tags / --status / --all 之一")
+		}
+	}
+
+	submitted, err := e.client.SubmitJob(ctx, req)
+	if err != nil {
+		return err
+	}
+	if wait, _ := fs.GetBool("wait"); wait {
+		ids := make([]string, len(submitted))
+		for i, s := range submitted {
+			ids[i] = s.JobID
+		}
+		return waitJobs(ctx, e, fs, ids, true)
+	}
+	if e.json() {
+		return printJSON(e.stdout, submitted)
+	}
+	t := newTable(e.stdout, "JOB", "AGENT", "STATUS")
+	for _, s := range submitted {
+		t.row(s.JobID, s.AgentID, s.Status)
+	}
+	return t.flush()
+}
+
+func runWait(ctx context.Context, e *env, fs *pflag.FlagSet) error {
+	if fs.NArg() == 0 {
+		return usageError("需要至少一个任务 ID")
+	}
+	return waitJobs(ctx, e, fs, fs.Args(), false)
+}
+
+// waitJobs 依次等每个任务结束, 状态变化打到 stderr. withOutput 时把每个任务的输出也打出来.
+func waitJobs(ctx context.Context, e *env, fs *pflag.FlagSet, ids []string, withOutput bool) error {
+	timeout, _ := fs.GetDuration("timeout")
+	interval, _ := fs.GetDuration("interval")
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	jobs := make([]Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := e.client.WaitJob(ctx, id, interval, func(j *Job) {
+			fmt.Fprintf(e.stderr, "%s  %s  %s\n", time.Now().Format(time.TimeOnly), j.JobID, j.Status)
+		})
+		if errors.Is(err, context.DeadlineExceeded) && job != nil {
+			return fmt.Errorf("等待 %s 超时, 当前状态 %s", id, job.Status)
+		}
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	if e.json() {
+		if err := printJSON(e.stdout, jobs); err != nil {
+			return err
+		}
+	} else if withOutput {
+		for i := range jobs {
+			if len(jobs) > 1 {
+				fmt.Fprintf(e.stdout, "==> %s (%s) %s <==\n", jobs[i].AgentID, jobs[i].JobID, jobs[i].Status)
+			}
+			if jobs[i].Result != nil {
+				fmt.Fprintln(e.stdout, strings.TrimRight(*jobs[i].Result, "\n"))
+			}
+		}
+	} else if err := printJobs(e.stdout, jobs); err != nil {
+		return err
+	}
+
+	for _, j := range jobs {
+		if j.Status != "Success" {
+			return errJobsFailed
+		}
+	}
+	return nil
+}
+
+func runLogs(ctx context.Context, e *env, fs *pflag.FlagSet) error {
+	if fs.NArg() != 1 {
+		return usageError("需要一个任务 ID")
+	}
+	job, err := e.client.GetJob(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if follow, _ := fs.GetBool("follow"); follow && !job.Finished() {
+		if e.json() {
+			return waitJobs(ctx, e, fs, []string{job.JobID}, true)
+		}
+		return followLogs(ctx, e, fs, job.JobID)
+	}
+	if e.json() {
+		return printJSON(e.stdout, job)
+	}
+	if !job.Finished() {
+		fmt.Fprintf(e.stderr, "任务还没结束 (%s), 用 -f 等它结束\n", job.Status)
+	}
+	if job.Result != nil && *job.Result != "" {
+		fmt.Fprintln(e.stdout, strings.TrimRight(*job.Result, "\n"))
+	}
+	return nil
+}
+
+// followLogs 边执行边打印输出. 不支持增量上报的旧 Agent 没有分片, 结束时打印完整输出.
+func followLogs(ctx context.Context, e *env, fs *pflag.FlagSet, id string) error {
+	timeout, _ := fs.GetDuration("timeout")
+	interval, _ := fs.GetDuration("interval")
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	streamed := false
+	job, err := e.client.TailJob(ctx, id, interval, func(data string) {
+		streamed = true
+		fmt.Fprint(e.stdout, data)
+	})
+	if errors.Is(err, context.DeadlineExceeded) && job != nil {
+		return fmt.Errorf("等待 %s 超时, 当前状态 %s", id, job.Status)
+	}
+	if err != nil {
+		return err
+	}
+	if !streamed && job.Result != nil && *job.Result != "" {
+		fmt.Fprintln(e.stdout, strings.TrimRight(*job.Result, "\n"))
+	}
+	fmt.Fprintf(e.stderr, "%s  %s  %s\n", time.Now().Format(time.TimeOnly), job.JobID, job.Status)
+	if job.Status != "Success" {
+		return errJobsFailed
+	}
+	return nil
+}
+
+func runCancel(ctx context.Context, e *env, fs *pflag.FlagSet) error {
+	if fs.NArg() == 0 {
+		return usageError("需要至少一个任务 ID")
+	}
+	var failed bool
+	results := make([]Submitted, 0, fs.NArg())
+	for _, id := range fs.Args() {
+		status, err := e.client.CancelJob(ctx, id)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "取消 %s 失败: %v\n", id, err)
+			failed = true
+			continue
+		}
+		results = append(results, Submitted{JobID: id, Status: status})
+	}
+	if e.json() {
+		if err := printJSON(e.stdout, results); err != nil {
+			return err
+		}
+	} else if len(results) > 0 {
+		t := newTable(e.stdout, "JOB", "STATUS")
+		for _, r := range results {
+			t.row(r.JobID, r.Status)
+		}
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+	if failed {
+		return errJobsFailed
+	}
+	return nil
+}
+
+func runHistory(ctx context.Context, e *env, fs *pflag.FlagSet) error {
+	agent, _ := fs.GetString("agent")
+	status, _ := fs.GetStringSlice("status")
+	limit, _ := fs.GetInt("limit")
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if len(status) > 0 {
+		query.Set("status", strings.Join(status, ","))
+	}
+	for flag, param := range map[string]string{"type": "type", "submitted-by": "submitted_by", "query": "q", "sort": "sort", "cursor": "cursor"} {
+		if v, _ := fs.GetString(flag); v != "" {
+			query.Set(param, v)
+		}
+	}
+	for _, flag := range []string{"since", "until"} {
+		v, _ := fs.GetString(flag)
+		if v == "" {
+			continue
+		}
+		t, err := parseTimeFlag(v)
+		if err != nil {
+			return usageError(fmt.Sprintf("--%s: %v", flag, err))
+		}
+		query.Set(flag, t.UTC().Format(time.RFC3339))
+	}
+
+	jobs, next, err := e.client.ListJobs(ctx, agent, query)
+	if err != nil {
+		return err
+	}
+	if e.json() {
+		return printJSON(e.stdout, map[string]any{"jobs": jobs, "next_cursor": next})
+	}
+	if err := printJobs(e.stdout, jobs); err != nil {
+		return err
+	}
+	if next != "" {
+		fmt.Fprintf(e.stderr, "\n还有更多, 下一页: --cursor %s\n", next)
+	}
+	return nil
+}
+
+// parseTimeFlag RFC3339 时间, 或者 "24h" 这种相对现在往前的时长
+func parseTimeFlag(v string) (time.Time, error) {
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,94 @@
+// Package ctl sentinelctl 的实现: 读配置, 调管理 API, 按表格或 JSON 输出.
+package ctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// 和服务端一样: 命令行参数 > 环境变量 > 配置文件 > 默认值.
+// 环境变量用 SENTINELCTL_ 前缀, 和服务端的 SENTINEL_ 分开, 例如 SENTINELCTL_TOKEN.
+const envPrefix = "SENTINELCTL"
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+type Config struct {
+	Server  string `mapstructure:"server"`  // 管理 API 地址, 例如 https://sentinel.example.com:8080
+	Token   string `mapstructure:"token"`   // 建议用环境变量 SENTINELCTL_TOKEN
+	Project string `mapstructure:"project"` // 为空时服务端按 default 项目处理
+	Output  string `mapstructure:"output"`  // table / json
+	CAFile  string `mapstructure:"ca_file"` // 服务端证书不是公共 CA 签发时指定
+}
+
+// DefaultConfigPath $XDG_CONFIG_HOME/sentinelctl/config.yaml, 取不到用户配置目录时为空
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sentinelctl", "config.yaml")
+}
+
+// addGlobalFlags 每个子命令都接受的参数
+func addGlobalFlags(fs *pflag.FlagSet) {
+	fs.String("config", "", "配置文件路径 (默认 "+DefaultConfigPath()+")")
+	fs.String("server", "", "管理 API 地址")
+	fs.String("token", "", "API token")
+	fs.StringP("project", "p", "", "项目")
+	fs.StringP("output", "o", "", "输出格式: table / json")
+	fs.String("ca-file", "", "校验服务端证书用的 CA")
+}
+
+// loadConfig 配置文件不存在不算错误, 只用环境变量和参数也可以
+func loadConfig(fs *pflag.FlagSet) (*Config, error) {
+	v := viper.New()
+	v.SetDefault("server", "http://127.0.0.1:8080")
+	v.SetDefault("output", OutputTable)
+	v.SetEnvPrefix(envPrefix)
+	v.AutomaticEnv()
+	for key, flag := range map[string]string{"server": "server", "token": "token", "project": "project", "output": "output", "ca_file": "ca-file"} {
+		if err := v.BindPFlag(key, fs.Lookup(flag)); err != nil {
+			return nil, err
+		}
+	}
+
+	path, _ := fs.GetString("config")
+	if path == "" {
+		path = os.Getenv(envPrefix + "_CONFIG")
+	}
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigPath()
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			var notFound *os.PathError
+			if explicit || !errors.As(err, &notFound) {
+				return nil, fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+			}
+		}
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, err
+	}
+	cfg.Server = strings.TrimRight(cfg.Server, "/")
+	if cfg.Output != OutputTable && cfg.Output != OutputJSON {
+		return nil, fmt.Errorf("output 只能是 %s 或 %s", OutputTable, OutputJSON)
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("没有配置 token: 用 --token, 环境变量 SENTINELCTL_TOKEN 或配置文件里的 token")
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,136 @@
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// printJSON 缩进输出, 方便直接交给 jq
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// table 基于 tabwriter 的简单表格, 空值显示成 "-"
+type table struct {
+	tw *tabwriter.Writer
+}
+
+func newTable(w io.Writer, header ...string) *table {
+	t := &table{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	t.row(header...)
+	return t
+}
+
+func (t *table) row(cols ...string) {
+	for i, c := range cols {
+		if c == "" {
+			cols[i] = "-"
+		}
+	}
+	fmt.Fprintln(t.tw, strings.Join(cols, "\t"))
+}
+
+func (t *table) flush() error { return t.tw.Flush() }
+
+// ago 相对时间, 比绝对时间更容易看出 Agent 有没有掉线
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := time.Since(t).Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+// truncate 表格里的命令只显示一行的开头
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// agentTags ",a,b," -> "a,b"
+func agentTags(tags string) string { return strings.Trim(tags, ",") }
+
+func printAgents(w io.Writer, agents []Agent) error {
+	t := newTable(w, "AGENT", "STATUS", "HOSTNAME", "IP", "TAGS", "VERSION", "LAST SEEN")
+	for _, a := range agents {
+		t.row(a.AgentID, a.Status, a.Hostname, a.IP, agentTags(a.Tags), a.Version, ago(a.LastSeen))
+	}
+	return t.flush()
+}
+
+func printJobs(w io.Writer, jobs []Job) error {
+	t := newTable(w, "JOB", "AGENT", "TYPE", "STATUS", "SUBMITTED BY", "CREATED", "COMMAND")
+	for _, j := range jobs {
+		t.row(j.JobID, j.AgentID, j.Type, j.Status, j.SubmittedBy, formatTime(&j.CreatedAt), truncate(j.Payload, 50))
+	}
+	return t.flush()
+}
+
+func printAgent(w io.Writer, a *Agent) error {
+	t := &table{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	t.row("Agent:", a.AgentID)
+	t.row("Project:", a.Project)
+	t.row("Status:", a.Status)
+	t.row("Hostname:", a.Hostname)
+	t.row("IP:", a.IP)
+	t.row("Tags:", agentTags(a.Tags))
+	t.row("Version:", strings.TrimSpace(a.Version+" "+a.OS+"/"+a.Arch))
+	if a.TargetVersion != "" {
+		t.row("Target version:", a.TargetVersion)
+	}
+	if a.UpdateError != "" {
+		t.row("Update error:", a.UpdateError)
+	}
+	t.row("Last seen:", formatTime(&a.LastSeen)+" ("+ago(a.LastSeen)+")")
+	t.row("Registered:", formatTime(&a.CreatedAt))
+	return t.flush()
+}
+
+func printJob(w io.Writer, j *Job) error {
+	t := &table{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	t.row("Job:", j.JobID)
+	t.row("Agent:", j.AgentID)
+	t.row("Project:", j.Project)
+	t.row("Type:", j.Type)
+	t.row("Status:", j.Status)
+	t.row("Submitted by:", j.SubmittedBy)
+	if j.ApprovedBy != "" {
+		t.row("Approved by:", j.ApprovedBy)
+	}
+	t.row("Created:", formatTime(&j.CreatedAt))
+	t.row("Executed:", formatTime(j.ExecutedAt))
+	t.row("Command:", j.Payload)
+	if err := t.flush(); err != nil {
+		return err
+	}
+	if j.Result != nil && *j.Result != "" {
+		fmt.Fprintln(w, "Output:")
+		fmt.Fprintln(w, strings.TrimRight(*j.Result, "\n"))
+	}
+	return nil
+}
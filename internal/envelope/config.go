@@ -0,0 +1,59 @@
+package envelope
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+	"gorm.io/gorm"
+)
+
+// FromConfig 按配置加载 KEK, 未启用时返回 nil
+func FromConfig(db *gorm.DB, cfg config.EncryptionConfig) (*Keyring, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	current, err := loadKEK(cfg.KEK, cfg.KEKFile)
+	if err != nil {
+		return nil, err
+	}
+	var previous [][]byte
+	for _, enc := range cfg.PreviousKEKs {
+		key, err := loadKEK(enc, "")
+		if err != nil {
+			return nil, fmt.Errorf("previous kek: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	for _, path := range cfg.PreviousKEKFiles {
+		key, err := loadKEK("", path)
+		if err != nil {
+			return nil, fmt.Errorf("previous kek: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	return NewKeyring(db, current, previous...)
+}
+
+// loadKEK 文件对组或其他用户可读时拒绝加载
+func loadKEK(encoded, path string) ([]byte, error) {
+	if path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode().Perm()&0o077 != 0 {
+			return nil, fmt.Errorf("%s is accessible by group or others (mode %s), chmod 600 it", path, info.Mode().Perm())
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(data)
+	}
+	key, err := ParseKEK(encoded)
+	if err != nil && path != "" {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, err
+}
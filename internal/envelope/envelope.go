@@ -0,0 +1,292 @@
+// Package envelope 数据库列的信封加密. 每个作用域 (目前按月) 一把随机的数据密钥 (DEK) 加密数据,
+// DEK 再用密钥加密密钥 (KEK) 包装后存在 data_keys 表里. KEK 只存在于进程内存和配置里,
+// 拿到数据库备份也解不开; 轮换 KEK 时只需要重新包装 DEK, 不用重写业务数据.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 密文前缀, 没有这个前缀的值按明文处理 (启用加密之前写入的旧数据)
+const sealedPrefix = "sev1:"
+
+var (
+	ErrNoKeyring  = errors.New("value is encrypted but no key-encryption key is configured")
+	ErrUnknownKEK = errors.New("data key is wrapped by a key-encryption key that is not configured")
+	ErrMalformed  = errors.New("malformed encrypted value")
+)
+
+// DataKey 包装后的数据密钥
+type DataKey struct {
+	ID         uint32 `gorm:"primaryKey;autoIncrement"`
+	Scope      string `gorm:"index;size:64"`
+	KEKID      string `gorm:"column:kek_id;index;size:16"`
+	Nonce      []byte `gorm:"type:varbinary(12)"`
+	WrappedKey []byte `gorm:"type:varbinary(64)"`
+	CreatedAt  time.Time
+}
+
+func (DataKey) TableName() string { return "data_keys" }
+
+type kek struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring 持有当前 KEK (新的 DEK 用它包装) 和轮换期间仍需要的旧 KEK
+type Keyring struct {
+	db      *gorm.DB
+	primary kek
+	keks    map[string]cipher.AEAD
+
+	mu       sync.Mutex
+	deks     map[uint32]cipher.AEAD // 已解包的 DEK
+	current  map[string]uint32      // scope -> 写入时使用的 DEK
+	indexKey []byte                 // 见 IndexKey
+}
+
+// ParseKEK 解析 base64 编码的 32 字节密钥 (openssl rand -base64 32 的输出)
+func ParseKEK(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// kekID 由密钥内容派生, 不需要另外配置, 也不会泄露密钥
+func kekID(key []byte) string {
+	sum := sha256.Sum256(append([]byte("sentinel-kek-id"), key...))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NewKeyring current 为当前 KEK, previous 为轮换前的旧 KEK (只用来解包)
+func NewKeyring(db *gorm.DB, current []byte, previous ...[]byte) (*Keyring, error) {
+	k := &Keyring{
+		db:      db,
+		keks:    make(map[string]cipher.AEAD),
+		deks:    make(map[uint32]cipher.AEAD),
+		current: make(map[string]uint32),
+	}
+	for i, key := range append([][]byte{current}, previous...) {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		id := kekID(key)
+		k.keks[id] = aead
+		if i == 0 {
+			k.primary = kek{id: id, aead: aead}
+		}
+	}
+	return k, nil
+}
+
+// PrimaryID 当前 KEK 的 ID, 启动日志用
+func (k *Keyring) PrimaryID() string { return k.primary.id }
+
+// MonthScope 按月划分数据密钥
+func MonthScope(t time.Time) string { return t.UTC().Format("2006-01") }
+
+func wrapAAD(scope string) []byte { return []byte("sentinel-dek:" + scope) }
+
+// dataKey 返回 scope 当前可写的 DEK, 没有就生成一把.
+// 多个实例同时生成时会各自插入一行, 密文里记录了 DEK 的 ID, 不影响解密.
+func (k *Keyring) dataKey(ctx context.Context, scope string) (uint32, cipher.AEAD, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if id, ok := k.current[scope]; ok {
+		return id, k.deks[id], nil
+	}
+
+	var row DataKey
+	err := k.db.WithContext(ctx).Where("scope = ? AND kek_id = ?", scope, k.primary.id).Order("id DESC").First(&row).Error
+	switch {
+	case err == nil:
+		aead, err := k.unwrap(&row)
+		if err != nil {
+			return 0, nil, err
+		}
+		k.deks[row.ID], k.current[scope] = aead, row.ID
+		return row.ID, aead, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return 0, nil, err
+	}
+
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return 0, nil, err
+	}
+	row, err = k.wrapKey(scope, dek)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := k.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return 0, nil, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return 0, nil, err
+	}
+	k.deks[row.ID], k.current[scope] = aead, row.ID
+	return row.ID, aead, nil
+}
+
+// wrapKey 用当前 KEK 包装 key, 返回待插入 data_keys 的行
+func (k *Keyring) wrapKey(scope string, key []byte) (DataKey, error) {
+	row := DataKey{Scope: scope, KEKID: k.primary.id, Nonce: make([]byte, k.primary.aead.NonceSize())}
+	if _, err := rand.Read(row.Nonce); err != nil {
+		return DataKey{}, err
+	}
+	row.WrappedKey = k.primary.aead.Seal(nil, row.Nonce, key, wrapAAD(scope))
+	return row, nil
+}
+
+func (k *Keyring) unwrap(row *DataKey) (cipher.AEAD, error) {
+	dek, err := k.unwrapKey(row)
+	if err != nil {
+		return nil, err
+	}
+	return newAEAD(dek)
+}
+
+func (k *Keyring) unwrapKey(row *DataKey) ([]byte, error) {
+	wrapper, ok := k.keks[row.KEKID]
+	if !ok {
+		return nil, fmt.Errorf("%w (data key %d, kek %s)", ErrUnknownKEK, row.ID, row.KEKID)
+	}
+	dek, err := wrapper.Open(nil, row.Nonce, row.WrappedKey, wrapAAD(row.Scope))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key %d: %w", row.ID, err)
+	}
+	return dek, nil
+}
+
+// dataKeyByID 解密时按密文里记录的 ID 取 DEK
+func (k *Keyring) dataKeyByID(ctx context.Context, id uint32) (cipher.AEAD, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if aead, ok := k.deks[id]; ok {
+		return aead, nil
+	}
+	var row DataKey
+	if err := k.db.WithContext(ctx).First(&row, id).Error; err != nil {
+		return nil, fmt.Errorf("load data key %d: %w", id, err)
+	}
+	aead, err := k.unwrap(&row)
+	if err != nil {
+		return nil, err
+	}
+	k.deks[id] = aead
+	return aead, nil
+}
+
+// Encrypt 输出 "sev1:" + base64(DEK ID | nonce | 密文). aad 绑定数据所在的位置 (表.列), 密文挪到别的列解不开.
+func (k *Keyring) Encrypt(ctx context.Context, scope, aad string, plaintext []byte) (string, error) {
+	id, aead, err := k.dataKey(ctx, scope)
+	if err != nil {
+		return "", err
+	}
+	buf := binary.BigEndian.AppendUint32(nil, id)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	buf = append(buf, nonce...)
+	buf = aead.Seal(buf, nonce, plaintext, []byte(aad))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// Decrypt 不是密文的值原样返回
+func (k *Keyring) Decrypt(ctx context.Context, aad, value string) ([]byte, error) {
+	if !IsSealed(value) {
+		return []byte(value), nil
+	}
+	if k == nil {
+		return nil, ErrNoKeyring
+	}
+	buf, err := base64.StdEncoding.DecodeString(value[len(sealedPrefix):])
+	if err != nil || len(buf) < 4 {
+		return nil, ErrMalformed
+	}
+	aead, err := k.dataKeyByID(ctx, binary.BigEndian.Uint32(buf))
+	if err != nil {
+		return nil, err
+	}
+	buf = buf[4:]
+	if len(buf) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plain, err := aead.Open(nil, buf[:aead.NonceSize()], buf[aead.NonceSize():], []byte(aad))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return plain, nil
+}
+
+func IsSealed(value string) bool { return strings.HasPrefix(value, sealedPrefix) }
+
+// Rewrap 把不是由当前 KEK 包装的 DEK 全部改用当前 KEK 包装, 返回处理的行数.
+// 业务数据不需要动; 完成之后就可以从配置里去掉旧 KEK.
+func (k *Keyring) Rewrap(ctx context.Context) (int, error) {
+	var rows []DataKey
+	if err := k.db.WithContext(ctx).Where("kek_id <> ?", k.primary.id).Find(&rows).Error; err != nil {
+		return 0, err
+	}
+	for i, row := range rows {
+		wrapped, err := k.rewrap(&row)
+		if err != nil {
+			return i, err
+		}
+		err = k.db.WithContext(ctx).Model(&DataKey{}).
+			Where("id = ? AND kek_id = ?", row.ID, row.KEKID).
+			Updates(map[string]any{
+				"kek_id":      wrapped.KEKID,
+				"nonce":       wrapped.Nonce,
+				"wrapped_key": wrapped.WrappedKey,
+			}).Error
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(rows), nil
+}
+
+// rewrap 解开 row 里的 DEK 再用当前 KEK 包装, 返回新的行 (ID 不变), 不落库
+func (k *Keyring) rewrap(row *DataKey) (DataKey, error) {
+	dek, err := k.unwrapKey(row)
+	if err != nil {
+		return DataKey{}, err
+	}
+	wrapped, err := k.wrapKey(row.Scope, dek)
+	if err != nil {
+		return DataKey{}, err
+	}
+	wrapped.ID, wrapped.CreatedAt = row.ID, row.CreatedAt
+	return wrapped, nil
+}
@@ -0,0 +1,163 @@
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func newKEK(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// newTestKeyring 不连数据库: 生成一把 DEK 包装成 data_keys 的一行, 直接放进缓存
+func newTestKeyring(t *testing.T, scope string, current []byte, previous ...[]byte) (*Keyring, DataKey) {
+	t.Helper()
+	k, err := NewKeyring(nil, current, previous...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := k.wrapKey(scope, newKEK(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	row.ID = 1
+	load(t, k, row)
+	k.current[scope] = row.ID
+	return k, row
+}
+
+// load 模拟从 data_keys 读出 row 并解包
+func load(t *testing.T, k *Keyring, row DataKey) {
+	t.Helper()
+	aead, err := k.unwrap(&row)
+	if err != nil {
+		t.Fatalf("unwrap data key %d: %v", row.ID, err)
+	}
+	k.deks[row.ID] = aead
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	ctx := context.Background()
+	k, _ := newTestKeyring(t, "2026-10", newKEK(t))
+
+	tests := []struct {
+		name  string
+		plain string
+	}{
+		{"empty", ""},
+		{"text", "uptime"},
+		{"multiline", "line 1\nline 2\n"},
+		{"binary", "\x00\xff\x01sev1:"},
+	}
+	for _, tt := range tests {
+		sealed, err := k.Encrypt(ctx, "2026-10", "job_records.payload", []byte(tt.plain))
+		if err != nil {
+			t.Fatalf("%s: Encrypt: %v", tt.name, err)
+		}
+		if !IsSealed(sealed) {
+			t.Fatalf("%s: Encrypt returned %q without the sealed prefix", tt.name, sealed)
+		}
+		got, err := k.Decrypt(ctx, "job_records.payload", sealed)
+		if err != nil {
+			t.Fatalf("%s: Decrypt: %v", tt.name, err)
+		}
+		if string(got) != tt.plain {
+			t.Errorf("%s: Decrypt = %q, want %q", tt.name, got, tt.plain)
+		}
+	}
+
+	// 启用加密之前写入的明文原样读出
+	if got, err := k.Decrypt(ctx, "job_records.payload", "plain"); err != nil || string(got) != "plain" {
+		t.Errorf("Decrypt(plain) = %q, %v", got, err)
+	}
+	var none *Keyring
+	sealed, _ := k.Encrypt(ctx, "2026-10", "job_records.payload", []byte("x"))
+	if _, err := none.Decrypt(ctx, "job_records.payload", sealed); !errors.Is(err, ErrNoKeyring) {
+		t.Errorf("Decrypt without keyring = %v, want ErrNoKeyring", err)
+	}
+}
+
+func TestDecryptBindsColumn(t *testing.T) {
+	ctx := context.Background()
+	k, _ := newTestKeyring(t, "2026-10", newKEK(t))
+	sealed, err := k.Encrypt(ctx, "2026-10", "job_records.result", []byte("secret output"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		aad     string
+		value   string
+		wantErr bool
+		want    error
+	}{
+		{name: "same column", aad: "job_records.result", value: sealed},
+		{name: "other column", aad: "job_records.payload", value: sealed, wantErr: true},
+		{name: "other table", aad: "job_output_chunks.result", value: sealed, wantErr: true},
+		{name: "truncated", aad: "job_records.result", value: sealed[:len(sealedPrefix)+4], wantErr: true},
+		{name: "not base64", aad: "job_records.result", value: sealedPrefix + "!!", wantErr: true, want: ErrMalformed},
+	}
+	for _, tt := range tests {
+		got, err := k.Decrypt(ctx, tt.aad, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Decrypt = %q, %v; want error %v", tt.name, got, err, tt.wantErr)
+			continue
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: Decrypt error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRewrapAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	oldKEK, newKEKey := newKEK(t), newKEK(t)
+	old, row := newTestKeyring(t, "2026-10", oldKEK)
+	sealed, err := old.Encrypt(ctx, "2026-10", "job_records.result", []byte("before rotation"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 新 KEK 在前, 旧 KEK 只用来解包
+	rotating, err := NewKeyring(nil, newKEKey, oldKEK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrapped, err := rotating.rewrap(&row)
+	if err != nil {
+		t.Fatalf("rewrap: %v", err)
+	}
+	if rewrapped.ID != row.ID || rewrapped.KEKID != rotating.PrimaryID() {
+		t.Fatalf("rewrap = id %d kek %s, want id %d kek %s", rewrapped.ID, rewrapped.KEKID, row.ID, rotating.PrimaryID())
+	}
+
+	// 去掉旧 KEK 之后, 原来的行解不开, 重新包装过的行可以
+	rotated, err := NewKeyring(nil, newKEKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotated.unwrap(&row); !errors.Is(err, ErrUnknownKEK) {
+		t.Fatalf("unwrap with the old kek removed = %v, want ErrUnknownKEK", err)
+	}
+	load(t, rotated, rewrapped)
+	got, err := rotated.Decrypt(ctx, "job_records.result", sealed)
+	if err != nil {
+		t.Fatalf("Decrypt after rewrap: %v", err)
+	}
+	if string(got) != "before rotation" {
+		t.Errorf("Decrypt after rewrap = %q", got)
+	}
+
+	// 没配置旧 KEK 时无法重新包装
+	if _, err := rotated.rewrap(&row); !errors.Is(err, ErrUnknownKEK) {
+		t.Errorf("rewrap without the old kek = %v, want ErrUnknownKEK", err)
+	}
+}
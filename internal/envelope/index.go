@@ -0,0 +1,72 @@
+package envelope
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// 全文索引的 HMAC 密钥也作为一行 DEK 存在 data_keys 里. 轮换 KEK 时 Rewrap 只会重新包装它, 密钥本身不变, 索引不用重建.
+const indexScope = "search-index"
+
+// IndexKey 返回全文索引用的 HMAC 密钥, 库里还没有就生成一把.
+// 多个实例同时生成时会各插入一行, 一律以 id 最小的那把为准.
+func (k *Keyring) IndexKey(ctx context.Context) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.indexKey != nil {
+		return k.indexKey, nil
+	}
+
+	var row DataKey
+	err := k.db.WithContext(ctx).Where("scope = ?", indexScope).Order("id").First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if row, err = k.wrapKey(indexScope, key); err != nil {
+			return nil, err
+		}
+		if err := k.db.WithContext(ctx).Create(&row).Error; err != nil {
+			return nil, err
+		}
+		err = k.db.WithContext(ctx).Where("scope = ?", indexScope).Order("id").First(&row).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := k.unwrapKey(&row)
+	if err != nil {
+		return nil, err
+	}
+	k.indexKey = key
+	return key, nil
+}
+
+// TermHasher 返回把索引词变成定长摘要的函数, 索引表里只存摘要, 不存原词.
+// 配置了 KEK 时是 HMAC-SHA256(索引密钥, 词), 拿到数据库也没法按字典反推; 否则是普通 SHA-256.
+// 两者结果不同, 启用加密之后要重建一次索引.
+func TermHasher(ctx context.Context) (func(term string) string, error) {
+	k := active.Load()
+	if k == nil {
+		return func(term string) string {
+			sum := sha256.Sum256([]byte(term))
+			return hex.EncodeToString(sum[:16])
+		}, nil
+	}
+	key, err := k.IndexKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(term string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(term))
+		return hex.EncodeToString(mac.Sum(nil)[:16])
+	}, nil
+}
@@ -0,0 +1,56 @@
+package envelope
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// 进程内生效的 Keyring. gorm 的 serializer 是全局注册的, 拿不到 *gorm.DB 上的状态, 只能放在包级变量里.
+var active atomic.Pointer[Keyring]
+
+func init() {
+	schema.RegisterSerializer("envelope", Serializer{})
+}
+
+// Use 设置进程内的 Keyring, nil 表示写入明文 (读取已加密的值会报错)
+func Use(k *Keyring) { active.Store(k) }
+
+// Serializer 给 string 字段加上 `gorm:"serializer:envelope"` 即可透明加解密.
+// 注意 map 形式的 Updates 不经过 serializer, 更新这些列要用结构体 + Select.
+type Serializer struct{}
+
+func columnAAD(field *schema.Field) string {
+	return field.Schema.Table + "." + field.DBName
+}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	var s string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("envelope: unsupported column type %T for %s", dbValue, field.Name)
+	}
+	plain, err := active.Load().Decrypt(ctx, columnAAD(field), s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", columnAAD(field), err)
+	}
+	return field.Set(ctx, dst, string(plain))
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	s, _ := fieldValue.(string)
+	k := active.Load()
+	if k == nil || s == "" {
+		return s, nil
+	}
+	return k.Encrypt(ctx, MonthScope(time.Now()), columnAAD(field), []byte(s))
+}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const emitterBuffer = 1024
+
+// Emitter 异步发布事件, 业务路径只负责入队, 不会被消息队列的抖动拖慢.
+// 缓冲区满时丢弃并打日志: 事件是通知性质的, 权威数据仍在 MySQL.
+type Emitter struct {
+	pub    Publisher
+	source string
+	ch     chan Event
+}
+
+func NewEmitter(pub Publisher, source string) *Emitter {
+	e := &Emitter{pub: pub, source: source, ch: make(chan Event, emitterBuffer)}
+	go e.loop()
+	return e
+}
+
+func (e *Emitter) Emit(eventType string, data any) {
+	ev := New(eventType, e.source, data)
+	select {
+	case e.ch <- ev:
+	default:
+		log.Printf("[Events] 事件缓冲区已满, 丢弃 %s (%s)", ev.Type, ev.ID)
+	}
+}
+
+func (e *Emitter) loop() {
+	for ev := range e.ch {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := e.pub.Publish(ctx, ev); err != nil {
+			log.Printf("[Events] 发布 %s 失败: %v", ev.Type, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 事件类型同时就是 RabbitMQ 的 routing key, 下游可以按 "job.*" / "agent.offline" 这样订阅
+const (
+	TypeJobPendingApproval = "job.pending_approval"
+	TypeJobQueued          = "job.queued"
+	TypeJobDispatched      = "job.dispatched"
+	TypeJobCompleted       = "job.completed"
+	TypeJobFailed          = "job.failed"
+	TypeJobCancelled       = "job.cancelled"
+	TypeJobRejected        = "job.rejected"
+
+	TypeAgentOnline  = "agent.online"
+	TypeAgentOffline = "agent.offline"
+)
+
+// SchemaVersions 每种事件 Data 结构的版本号. 字段只增不删; 出现不兼容修改时对应版本号 +1,
+// 下游按 schema_version 分支解析.
+//
+// job.* v2: 去掉了 payload, 命令在库里是加密存储的, 不能明文出现在消息队列和死信队列里
+var SchemaVersions = map[string]int{
+	TypeJobPendingApproval: 2,
+	TypeJobQueued:          2,
+	TypeJobDispatched:      2,
+	TypeJobCompleted:       2,
+	TypeJobFailed:          2,
+	TypeJobCancelled:       2,
+	TypeJobRejected:        2,
+	TypeAgentOnline:        1,
+	TypeAgentOffline:       1,
+}
+
+// Event 所有事件共用的信封
+type Event struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	SchemaVersion int       `json:"schema_version"`
+	Source        string    `json:"source"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Data          any       `json:"data"`
+}
+
+// JobEvent job.* 事件的 Data (schema v2). 不携带命令和执行输出, 需要的话按 job_id 回查 API.
+type JobEvent struct {
+	JobID       string `json:"job_id"`
+	AgentID     string `json:"agent_id"`
+	Project     string `json:"project,omitempty"`
+	JobType     string `json:"job_type"`
+	Status      string `json:"status"`
+	OutputBytes int    `json:"output_bytes,omitempty"`
+	SubmittedBy string `json:"submitted_by,omitempty"`
+	ApprovedBy  string `json:"approved_by,omitempty"`
+}
+
+// AgentEvent agent.* 事件的 Data (schema v1)
+type AgentEvent struct {
+	AgentID  string    `json:"agent_id"`
+	Project  string    `json:"project,omitempty"`
+	Hostname string    `json:"hostname,omitempty"`
+	IP       string    `json:"ip,omitempty"`
+	Status   string    `json:"status"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+func New(eventType, source string, data any) Event {
+	return Event{
+		ID:            uuid.NewString(),
+		Type:          eventType,
+		SchemaVersion: SchemaVersions[eventType],
+		Source:        source,
+		OccurredAt:    time.Now().UTC(),
+		Data:          data,
+	}
+}
+
+// Publisher 把事件投递到外部系统
+type Publisher interface {
+	Publish(ctx context.Context, ev Event) error
+}
+
+// NopPublisher 没配置消息队列时使用, 事件直接丢弃
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(ctx context.Context, ev Event) error { return nil }
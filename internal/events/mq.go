@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
+)
+
+// DefaultExchange 事件默认发布到的 topic exchange
+const DefaultExchange = "sentinel.events"
+
+// BrokerPublisher 把事件以 JSON 发布到 topic exchange, routing key 即事件类型
+type BrokerPublisher struct {
+	broker   mq.Broker
+	exchange string
+}
+
+func NewBrokerPublisher(ctx context.Context, broker mq.Broker, exchange string) (*BrokerPublisher, error) {
+	if err := broker.DeclareExchange(ctx, exchange); err != nil {
+		return nil, err
+	}
+	return &BrokerPublisher{broker: broker, exchange: exchange}, nil
+}
+
+func (p *BrokerPublisher) Publish(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return p.broker.Publish(ctx, p.exchange, ev.Type, mq.Message{
+		ID:          ev.ID,
+		ContentType: "application/json",
+		Type:        ev.Type,
+		Timestamp:   ev.OccurredAt,
+		Headers: map[string]any{
+			"schema_version": int32(ev.SchemaVersion),
+			"source":         ev.Source,
+		},
+		Body: body,
+	})
+}
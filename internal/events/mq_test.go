@@ -0,0 +1,135 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
+)
+
+// consume 订阅 exchange 上匹配 keys 的事件, 收到的消息原样送进返回的 channel
+func consume(t *testing.T, broker mq.Broker, exchange, queue string, keys ...string) <-chan *mq.Delivery {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan *mq.Delivery, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		broker.Subscribe(ctx, mq.ConsumeOptions{Queue: queue, Exchange: exchange, RoutingKeys: keys},
+			mq.Handle(func(ctx context.Context, d *mq.Delivery) error {
+				out <- d
+				return nil
+			}))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return out
+}
+
+// publishUntilDelivered MemoryBroker 的绑定在 Subscribe 里异步生效, 绑定之前发布的消息会被丢弃, 所以重发到收到为止
+func publishUntilDelivered(t *testing.T, publish func(), got <-chan *mq.Delivery) *mq.Delivery {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		publish()
+		select {
+		case d := <-got:
+			return d
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("event was not delivered")
+		}
+	}
+}
+
+func TestBrokerPublisher(t *testing.T) {
+	ctx := context.Background()
+	broker := mq.NewMemoryBroker()
+	defer broker.Close()
+	pub, err := NewBrokerPublisher(ctx, broker, DefaultExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := consume(t, broker, DefaultExchange, "audit", "job.*")
+
+	ev := New(TypeJobCompleted, "node-1", JobEvent{JobID: "j1", AgentID: "web-01", JobType: "SHELL", Status: "Success", OutputBytes: 42})
+	d := publishUntilDelivered(t, func() {
+		if err := pub.Publish(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}, got)
+
+	if d.RoutingKey != TypeJobCompleted || d.Type != TypeJobCompleted {
+		t.Errorf("routing key %q / type %q, want %q", d.RoutingKey, d.Type, TypeJobCompleted)
+	}
+	if d.ID != ev.ID || d.ContentType != "application/json" {
+		t.Errorf("message ID %q content type %q", d.ID, d.ContentType)
+	}
+	if v := d.Headers["schema_version"]; v != int32(SchemaVersions[TypeJobCompleted]) {
+		t.Errorf("schema_version header = %v", v)
+	}
+	if v := d.Headers["source"]; v != "node-1" {
+		t.Errorf("source header = %v", v)
+	}
+
+	var body struct {
+		Event
+		Data JobEvent `json:"data"`
+	}
+	if err := json.Unmarshal(d.Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Type != TypeJobCompleted || body.SchemaVersion != 2 || body.Source != "node-1" {
+		t.Errorf("envelope = %+v", body.Event)
+	}
+	if body.Data.JobID != "j1" || body.Data.AgentID != "web-01" || body.Data.OutputBytes != 42 {
+		t.Errorf("data = %+v", body.Data)
+	}
+	// 命令在库里加密存储, 不能出现在消息里
+	var raw struct {
+		Data map[string]any `json:"data"`
+	}
+	json.Unmarshal(d.Body, &raw)
+	if _, ok := raw.Data["payload"]; ok {
+		t.Errorf("job event carries the payload: %s", d.Body)
+	}
+}
+
+func TestEmitterRoutesByType(t *testing.T) {
+	ctx := context.Background()
+	broker := mq.NewMemoryBroker()
+	defer broker.Close()
+	pub, err := NewBrokerPublisher(ctx, broker, DefaultExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs := consume(t, broker, DefaultExchange, "jobs", "job.*")
+	offline := consume(t, broker, DefaultExchange, "offline", TypeAgentOffline)
+	emitter := NewEmitter(pub, "node-1")
+
+	d := publishUntilDelivered(t, func() {
+		emitter.Emit(TypeAgentOffline, AgentEvent{AgentID: "web-01", Status: "Offline"})
+	}, offline)
+	if d.RoutingKey != TypeAgentOffline {
+		t.Errorf("offline queue got %q", d.RoutingKey)
+	}
+	d = publishUntilDelivered(t, func() {
+		emitter.Emit(TypeJobQueued, JobEvent{JobID: "j1", Status: "Queued"})
+	}, jobs)
+	if d.RoutingKey != TypeJobQueued {
+		t.Errorf("jobs queue got %q", d.RoutingKey)
+	}
+
+	// agent.* 事件不会进 job.* 的队列
+	select {
+	case d := <-jobs:
+		if d.RoutingKey != TypeJobQueued {
+			t.Errorf("jobs queue got unexpected %q", d.RoutingKey)
+		}
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -6,13 +6,25 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	TaskStatusPending   = "Pending"
+	TaskStatusRunning   = "Running"
+	TaskStatusCompleted = "Completed"
+	TaskStatusFailed    = "Failed"
+)
+
 // Task 对应数据库里的 tasks 表
 type Task struct {
 	ID     uint   `gorm:"primaryKey" json:"id"`
 	Target string `json:"target"` // 扫描目标
-	Status string `json:"status"` // 状态: Pending, Running, Completed
+	Ports  string `json:"ports"`  // 要探测的端口, 逗号分隔; 为空时使用默认端口列表
+	Status string `json:"status"` // 状态: Pending, Running, Completed, Failed
+
+	Result string `gorm:"type:text" json:"result"` // 扫描结果 (JSON)
+	Error  string `gorm:"type:text" json:"error,omitempty"`
 
-	Result string `json:"result"` // 扫描结果
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
 
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
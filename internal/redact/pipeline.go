@@ -0,0 +1,100 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 规则里名为 secret 的分组命中时只替换这一段, 保留前面的 "password=" 之类的上下文; 没有这个分组就替换整段
+const secretGroup = "secret"
+
+type rule struct {
+	name  string
+	re    *regexp.Regexp
+	group int // secret 分组的下标, 没有为 -1
+}
+
+// Builtin 内置检测器, 默认全部启用
+var Builtin = []struct{ Name, Pattern string }{
+	{"aws_access_key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{"aws_secret_key", `(?i)aws_secret_access_key["']?\s*[=:]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40,})`},
+	// 输出被截断时可能只有 BEGIN 没有 END, 这种情况抹到结尾
+	{"pem_block", `-----BEGIN [A-Z0-9 ]+-----(?:[\s\S]*?-----END [A-Z0-9 ]+-----|[\s\S]*$)`},
+	{"bearer_token", `(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`},
+	// 不要求单词边界, DB_PASSWORD=... 这类也要命中
+	{"password_pair", `(?i)(?:password|passwd|pwd|secret|token|api[_-]?key)["']?\s*[=:]\s*(?P<secret>"[^"]*"|'[^']*'|[^\s"'&;,]+)`},
+}
+
+// Pipeline 按顺序执行一组检测规则, 命中的内容替换成 [REDACTED:<规则名>]
+type Pipeline struct {
+	rules []rule
+}
+
+// NewPipeline disabled 为要关闭的内置检测器名字, patterns 为自定义正则 (规则名 custom_<序号>, 从 1 开始)
+func NewPipeline(disabled, patterns []string) (*Pipeline, error) {
+	known := make(map[string]bool)
+	p := &Pipeline{}
+	for _, b := range Builtin {
+		known[b.Name] = true
+		if slices.Contains(disabled, b.Name) {
+			continue
+		}
+		if err := p.add(b.Name, b.Pattern); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range disabled {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown builtin detector %q", name)
+		}
+	}
+	for i, expr := range patterns {
+		if err := p.add(fmt.Sprintf("custom_%d", i+1), expr); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Pipeline) add(name, expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("detector %s: %w", name, err)
+	}
+	p.rules = append(p.rules, rule{name: name, re: re, group: re.SubexpIndex(secretGroup)})
+	return nil
+}
+
+// Redact nil 的 Pipeline 原样返回
+func (p *Pipeline) Redact(text string) string {
+	if p == nil || text == "" {
+		return text
+	}
+	for _, r := range p.rules {
+		text = r.apply(text)
+	}
+	return text
+}
+
+func (r rule) apply(text string) string {
+	matches := r.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	mask := "[REDACTED:" + r.name + "]"
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if r.group > 0 && m[2*r.group] >= 0 {
+			start, end = m[2*r.group], m[2*r.group+1]
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(mask)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,28 @@
+// Package redact 在任务输出落库、打日志之前抹掉敏感内容
+package redact
+
+import (
+	"sort"
+	"strings"
+)
+
+// Mask 替换敏感内容用的占位符
+const Mask = "******"
+
+// Values 把 text 里出现的每个 value 替换成 Mask. 长的先替换, 避免一个密钥是另一个的前缀时只抹掉一半.
+func Values(text string, values ...string) string {
+	if text == "" || len(values) == 0 {
+		return text
+	}
+	sorted := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, v := range sorted {
+		text = strings.ReplaceAll(text, v, Mask)
+	}
+	return text
+}
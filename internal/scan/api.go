@@ -0,0 +1,108 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/model"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
+)
+
+// API 扫描任务的 HTTP 入口: 落库 -> 发消息, 真正的扫描在 Worker 里做
+type API struct {
+	DB     *gorm.DB
+	Broker mq.Broker
+	Queue  string
+}
+
+type ScanRequest struct {
+	Target string `json:"target" binding:"required"`
+	Ports  string `json:"ports"`
+}
+
+// Register canSubmit / canView 是鉴权中间件, 分别挂在提交和查询接口前面
+func (a *API) Register(r gin.IRouter, canSubmit, canView gin.HandlerFunc) {
+	r.POST("/api/scan", canSubmit, a.submit)
+	r.GET("/api/task", canView, a.get)
+}
+
+// Submit 先落库再发布; 发布失败时把任务标记为 Failed, 不留下永远 Pending 的任务.
+// 主机数 x 端口数超过上限时返回 ErrTooManyProbes, 否则扫描会超过 RabbitMQ 的 consumer timeout 被反复重投.
+func (a *API) Submit(ctx context.Context, target, ports string) (*model.Task, error) {
+	hosts, err := ExpandTarget(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+	portList, err := ParsePorts(ports)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckProbes(hosts, portList); err != nil {
+		return nil, err
+	}
+
+	task := &model.Task{Target: target, Ports: ports, Status: model.TaskStatusPending}
+	if err := a.DB.WithContext(ctx).Create(task).Error; err != nil {
+		return nil, err
+	}
+
+	body, _ := json.Marshal(TaskMessage{TaskID: task.ID})
+	err = a.Broker.Publish(ctx, "", a.Queue, mq.Message{
+		ID:          uuid.NewString(),
+		ContentType: "application/json",
+		Type:        "scan.task",
+		Body:        body,
+	})
+	if err != nil {
+		a.DB.Model(task).Updates(map[string]any{"status": model.TaskStatusFailed, "error": "publish: " + err.Error()})
+		return nil, fmt.Errorf("publish task %d: %w", task.ID, err)
+	}
+	return task, nil
+}
+
+func (a *API) submit(c *gin.Context) {
+	var req ScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "JSON 格式不对"})
+		return
+	}
+	task, err := a.Submit(c.Request.Context(), req.Target, req.Ports)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("[API] 扫描任务 %d 已提交: %s", task.ID, task.Target)
+	c.JSON(200, gin.H{"code": 200, "task_id": task.ID, "status": task.Status})
+}
+
+func (a *API) get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Query("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "id 参数不对"})
+		return
+	}
+	var task model.Task
+	if err := a.DB.WithContext(c.Request.Context()).First(&task, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(404, gin.H{"error": "任务不存在"})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Result 存的是 JSON 字符串, 返回时展开成对象
+	var result any
+	if task.Result != "" {
+		json.Unmarshal([]byte(task.Result), &result)
+	}
+	c.JSON(200, gin.H{"code": 200, "data": task, "result": result})
+}
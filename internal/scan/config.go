@@ -0,0 +1,23 @@
+package scan
+
+import (
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
+)
+
+// BrokerFromConfig rabbitmq.url / rabbitmq.host 都为空时返回进程内 broker, 方便本地不装 RabbitMQ 直接跑
+func BrokerFromConfig(cfg config.RabbitMQConfig) (mq.Broker, error) {
+	return mq.New(cfg.BrokerURL())
+}
+
+// WorkerFromConfig 按 scan.* 配置组装 Worker
+func WorkerFromConfig(cfg *config.Config, w *Worker) *Worker {
+	w.Queue = cfg.RabbitMQ.QueueName
+	w.Workers = cfg.Scan.Workers
+	w.Deadline = cfg.Scan.Deadline
+	w.Scanner = &Scanner{
+		Timeout:     cfg.Scan.Timeout,
+		Concurrency: cfg.Scan.Concurrency,
+	}
+	return w
+}
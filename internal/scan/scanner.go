@@ -0,0 +1,177 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPorts 没有指定端口时探测的常见端口
+var DefaultPorts = []int{21, 22, 23, 25, 53, 80, 110, 143, 443, 445, 3306, 3389, 5432, 6379, 8080, 8443}
+
+// 单个任务最多展开的主机数, 防止有人提交一个 /8
+const maxHosts = 256
+
+// 单个任务最多探测的 主机 x 端口 数. 按默认并发 200、超时 2s, 全部超时也在 11 分钟左右扫完,
+// 离 scan.deadline 和 RabbitMQ 30 分钟的 consumer timeout 都有余量.
+const maxProbes = 1 << 16
+
+var ErrTooManyProbes = errors.New("too many probes")
+
+// HostResult 单个主机的探测结果
+type HostResult struct {
+	Host      string `json:"host"`
+	Alive     bool   `json:"alive"`
+	OpenPorts []int  `json:"open_ports"`
+}
+
+// Result 写进 Task.Result 的整体结果
+type Result struct {
+	Hosts    []HostResult `json:"hosts"`
+	Duration string       `json:"duration"`
+}
+
+// Scanner TCP connect 扫描器, Concurrency 限制同时在途的连接数
+type Scanner struct {
+	Timeout     time.Duration
+	Concurrency int
+}
+
+// ParsePorts 解析 "22,80,8000-8010" 这样的端口列表, 空串返回 DefaultPorts
+func ParsePorts(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return DefaultPorts, nil
+	}
+	seen := make(map[int]bool)
+	var ports []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				return nil, fmt.Errorf("invalid port range %q", part)
+			}
+		}
+		if start < 1 || end > 65535 || start > end {
+			return nil, fmt.Errorf("port out of range %q", part)
+		}
+		for p := start; p <= end; p++ {
+			if !seen[p] {
+				seen[p] = true
+				ports = append(ports, p)
+			}
+		}
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// ExpandTarget 把目标展开成主机列表: 支持单个 IP / 主机名 / CIDR
+func ExpandTarget(target string) ([]string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("empty target")
+	}
+	if !strings.Contains(target, "/") {
+		return []string{target}, nil
+	}
+
+	ip, ipnet, err := net.ParseCIDR(target)
+	if err != nil {
+		return nil, err
+	}
+	ones, bits := ipnet.Mask.Size()
+	if bits-ones > 8 {
+		return nil, fmt.Errorf("cidr %s too large (max %d hosts)", target, maxHosts)
+	}
+
+	var hosts []string
+	for cur := ip.Mask(ipnet.Mask); ipnet.Contains(cur); cur = nextIP(cur) {
+		hosts = append(hosts, cur.String())
+	}
+	// IPv4 去掉网络地址和广播地址
+	if ip.To4() != nil && len(hosts) > 2 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	return hosts, nil
+}
+
+// CheckProbes 主机数 x 端口数超过 maxProbes 时返回 ErrTooManyProbes
+func CheckProbes(hosts []string, ports []int) error {
+	if n := len(hosts) * len(ports); n > maxProbes {
+		return fmt.Errorf("%w: %d hosts x %d ports exceeds %d", ErrTooManyProbes, len(hosts), len(ports), maxProbes)
+	}
+	return nil
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// Scan 对每个主机的每个端口做 TCP connect, 任一端口能连上即视为存活
+func (s *Scanner) Scan(ctx context.Context, hosts []string, ports []int) []HostResult {
+	results := make([]HostResult, len(hosts))
+	sem := make(chan struct{}, s.Concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, host := range hosts {
+		results[i] = HostResult{Host: host, OpenPorts: []int{}}
+		for _, port := range ports {
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				return results
+			case sem <- struct{}{}:
+			}
+			wg.Add(1)
+			go func(i int, host string, port int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				if !s.probe(ctx, host, port) {
+					return
+				}
+				mu.Lock()
+				results[i].Alive = true
+				results[i].OpenPorts = append(results[i].OpenPorts, port)
+				mu.Unlock()
+			}(i, host, port)
+		}
+	}
+	wg.Wait()
+
+	for i := range results {
+		sort.Ints(results[i].OpenPorts)
+	}
+	return results
+}
+
+func (s *Scanner) probe(ctx context.Context, host string, port int) bool {
+	d := net.Dialer{Timeout: s.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,115 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/model"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/mq"
+)
+
+// TaskMessage 队列里传递的消息, 只带 ID, 任务内容以 MySQL 为准
+type TaskMessage struct {
+	TaskID uint `json:"task_id"`
+}
+
+// Worker 从队列消费扫描任务. Workers 个任务并发执行, 每个任务内部再由 Scanner 控制连接并发.
+type Worker struct {
+	DB      *gorm.DB
+	Broker  mq.Broker
+	Queue   string
+	Workers int
+	Scanner *Scanner
+	// Deadline 单个任务的扫描时限, 超时判失败不再重试; 必须小于 RabbitMQ 的 consumer timeout
+	Deadline time.Duration
+}
+
+// Run 阻塞消费直到 ctx 结束
+func (w *Worker) Run(ctx context.Context) error {
+	log.Printf("[Worker] 扫描 Worker Pool 已启动 | 队列: %s | 并发: %d", w.Queue, w.Workers)
+	return w.Broker.Subscribe(ctx, mq.ConsumeOptions{
+		Queue:    w.Queue,
+		Prefetch: w.Workers,
+	}, mq.Handle(w.handle))
+}
+
+func (w *Worker) handle(ctx context.Context, d *mq.Delivery) error {
+	var msg TaskMessage
+	if err := json.Unmarshal(d.Body, &msg); err != nil {
+		return mq.Permanent(fmt.Errorf("bad task message: %w", err))
+	}
+
+	var task model.Task
+	if err := w.DB.WithContext(ctx).First(&task, msg.TaskID).Error; err != nil {
+		return mq.Permanent(fmt.Errorf("load task %d: %w", msg.TaskID, err))
+	}
+	// 重复投递的消息: 已经完成的任务不再扫一遍
+	if task.Status == model.TaskStatusCompleted {
+		return nil
+	}
+
+	ports, err := ParsePorts(task.Ports)
+	if err != nil {
+		w.fail(&task, err)
+		return mq.Permanent(err)
+	}
+	hosts, err := ExpandTarget(task.Target)
+	if err != nil {
+		w.fail(&task, err)
+		return mq.Permanent(err)
+	}
+	// 上限之前提交的任务也在这里挡掉
+	if err := CheckProbes(hosts, ports); err != nil {
+		w.fail(&task, err)
+		return mq.Permanent(err)
+	}
+
+	now := time.Now()
+	w.DB.Model(&task).Updates(map[string]any{"status": model.TaskStatusRunning, "started_at": &now, "error": ""})
+	log.Printf("[Worker] 开始扫描任务 %d: %s (%d 主机 x %d 端口)", task.ID, task.Target, len(hosts), len(ports))
+
+	scanCtx, cancel := context.WithTimeout(ctx, w.Deadline)
+	defer cancel()
+	hostResults := w.Scanner.Scan(scanCtx, hosts, ports)
+	if ctx.Err() != nil {
+		// 进程退出中断了扫描, 交给重试队列重新执行
+		return ctx.Err()
+	}
+	if scanCtx.Err() != nil {
+		// 超时的任务重投也还是超时, 直接判失败
+		err := fmt.Errorf("scan exceeded deadline %s", w.Deadline)
+		w.fail(&task, err)
+		return mq.Permanent(err)
+	}
+
+	body, err := json.Marshal(Result{Hosts: hostResults, Duration: time.Since(now).Round(time.Millisecond).String()})
+	if err != nil {
+		return err
+	}
+	finished := time.Now()
+	err = w.DB.Model(&task).Updates(map[string]any{
+		"status":      model.TaskStatusCompleted,
+		"result":      string(body),
+		"finished_at": &finished,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("save result of task %d: %w", task.ID, err)
+	}
+	log.Printf("[Worker] 任务 %d 扫描完成, 耗时 %s", task.ID, finished.Sub(now).Round(time.Millisecond))
+	return nil
+}
+
+func (w *Worker) fail(task *model.Task, err error) {
+	finished := time.Now()
+	w.DB.Model(task).Updates(map[string]any{
+		"status":      model.TaskStatusFailed,
+		"error":       err.Error(),
+		"finished_at": &finished,
+	})
+	log.Printf("[Worker] 任务 %d 失败: %v", task.ID, err)
+}
@@ -0,0 +1,788 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/audit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// AdminServer SentinelAdmin gRPC 服务. 和 HttpServer 一样只做参数和结果的转换,
+// 鉴权、项目隔离、配额和业务规则都在 SentinelServer 里, 两边不会各自演化.
+type AdminServer struct {
+	pb.UnimplementedSentinelAdminServer
+	Srv *SentinelServer
+}
+
+func NewAdminServer(srv *SentinelServer) *AdminServer {
+	return &AdminServer{Srv: srv}
+}
+
+// adminProjectMetadata 和 HTTP 的 X-Sentinel-Project 头对应, gRPC metadata 的 key 只能是小写
+var adminProjectMetadata = strings.ToLower(ProjectHeader)
+
+var adminMethodPrefix = "/" + pb.SentinelAdmin_ServiceDesc.ServiceName + "/"
+
+// adminCall 一次调用的身份和审计信息, 由拦截器放进 ctx
+type adminCall struct {
+	method      string
+	user        *User
+	project     string
+	target      string // 审计记录的 target, handler 可以换成更具体的值
+	payloadHash string
+}
+
+type adminCallKey struct{}
+
+// callFrom 只能在拦截器放行之后调用
+func callFrom(ctx context.Context) *adminCall {
+	return ctx.Value(adminCallKey{}).(*adminCall)
+}
+
+func setAuditTarget(ctx context.Context, target string) {
+	callFrom(ctx).target = target
+}
+
+// projectRequest 带 project 字段的请求, 字段优先于 metadata, 对应 HTTP 的 :project 路径参数
+type projectRequest interface {
+	GetProject() string
+}
+
+// UnaryInterceptor 只处理 SentinelAdmin 的方法: 鉴权, 把错误映射成 gRPC 状态码, 调用结束后写一条审计记录.
+// Agent 使用的 SentinelService 原样放行.
+func (a *AdminServer) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !strings.HasPrefix(info.FullMethod, adminMethodPrefix) {
+			return handler(ctx, req)
+		}
+		call := &adminCall{method: info.FullMethod}
+		if m, ok := req.(proto.Message); ok {
+			if data, err := proto.Marshal(m); err == nil {
+				call.payloadHash = audit.HashPayload(data)
+			}
+		}
+		ctx, err := a.authorize(ctx, call, req)
+		var resp any
+		if err == nil {
+			resp, err = handler(ctx, req)
+		}
+		err = adminError(err)
+		a.audit(ctx, call, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor 同 UnaryInterceptor. 流式方法的请求在 handler 里才读, 不计算 payload_hash.
+func (a *AdminServer) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, adminMethodPrefix) {
+			return handler(srv, ss)
+		}
+		call := &adminCall{method: info.FullMethod}
+		ctx, err := a.authorize(ss.Context(), call, nil)
+		if err == nil {
+			err = handler(srv, &ctxStream{ServerStream: ss, ctx: ctx})
+		}
+		err = adminError(err)
+		a.audit(ss.Context(), call, err)
+		return err
+	}
+}
+
+// ctxStream 拦截器往 ctx 里放了东西之后, 用它把新的 ctx 交给流式 handler
+type ctxStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *ctxStream) Context() context.Context { return s.ctx }
+
+// authorize 校验 token 和角色, 通过后返回带 adminCall 的 ctx, 项目内的方法还会限定项目, 见 AuthorizeProject
+func (a *AdminServer) authorize(ctx context.Context, call *adminCall, req any) (context.Context, error) {
+	access, ok := operations[strings.TrimPrefix(call.method, adminMethodPrefix)]
+	if !ok {
+		return ctx, status.Error(codes.PermissionDenied, "method is not available")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	token, ok := strings.CutPrefix(firstMetadata(md, "authorization"), "Bearer ")
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "missing authorization: Bearer <token>")
+	}
+	u, err := a.Srv.Authenticate(ctx, strings.TrimSpace(token))
+	if errors.Is(err, ErrUserNotFound) {
+		return ctx, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if err != nil {
+		return ctx, err
+	}
+	call.user = u
+
+	if !access.project {
+		if err := Authorize(u, access.role); err != nil {
+			return ctx, err
+		}
+		return context.WithValue(ctx, adminCallKey{}, call), nil
+	}
+	project := firstMetadata(md, adminProjectMetadata)
+	if r, ok := req.(projectRequest); ok && r.GetProject() != "" {
+		project = r.GetProject()
+	}
+	scoped, project, err := a.Srv.AuthorizeProject(ctx, u, project, access.role)
+	if err != nil {
+		return ctx, err
+	}
+	call.project, call.target = project, project
+	return context.WithValue(scoped, adminCallKey{}, call), nil
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// audit 和 HTTP 的 auditTrail 一样, 认证失败的调用也记; result 为状态码加错误信息
+func (a *AdminServer) audit(ctx context.Context, call *adminCall, err error) {
+	entry := &audit.Entry{
+		SourceIP:    peerIP(ctx),
+		Action:      call.method,
+		Target:      call.target,
+		PayloadHash: call.payloadHash,
+		Result:      status.Code(err).String(),
+	}
+	if call.user != nil {
+		entry.Actor = call.user.Name
+	}
+	if err != nil {
+		entry.Result += " " + status.Convert(err).Message()
+	}
+	a.Srv.recordAudit(ctx, entry)
+}
+
+// adminError 把服务层的错误映射成 gRPC 状态码, 对应 HTTP 接口的 4xx / 5xx
+func adminError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var qe *QuotaError
+	if errors.As(err, &qe) {
+		// 对应 HTTP 的 429 + Retry-After, 等一等就能放行时带上 RetryInfo
+		st := status.New(codes.ResourceExhausted, qe.Error())
+		if qe.RetryAfter > 0 {
+			if withInfo, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(qe.RetryAfter)}); err == nil {
+				st = withInfo
+			}
+		}
+		return st.Err()
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, ErrPermissionDenied), errors.Is(err, ErrSecretDenied), errors.Is(err, ErrSelfApproval):
+		code = codes.PermissionDenied
+	case errors.Is(err, ErrAgentNotFound), errors.Is(err, ErrNoAgentsMatched), errors.Is(err, ErrJobNotFound),
+		errors.Is(err, ErrProjectNotFound), errors.Is(err, ErrUserNotFound), errors.Is(err, ErrMemberNotFound),
+		errors.Is(err, ErrAgentConfigNotFound), errors.Is(err, ErrReleaseNotFound), errors.Is(err, ErrApprovalRuleNotFound),
+		errors.Is(err, ErrSecretNotFound), errors.Is(err, ErrQuotaNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ErrInvalidJobRequest), errors.Is(err, ErrInvalidSelector), errors.Is(err, ErrInvalidSecretRef),
+		errors.Is(err, ErrInvalidJobQuery), errors.Is(err, ErrInvalidSearch), errors.Is(err, ErrInvalidAgentConfig),
+		errors.Is(err, ErrInvalidRelease), errors.Is(err, ErrInvalidApprovalRule), errors.Is(err, ErrInvalidSecret),
+		errors.Is(err, ErrInvalidUser), errors.Is(err, ErrInvalidProject), errors.Is(err, ErrInvalidQuota):
+		code = codes.InvalidArgument
+	case errors.Is(err, ErrUserExists), errors.Is(err, ErrProjectExists), errors.Is(err, ErrReleaseExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, ErrJobFinished), errors.Is(err, ErrJobNotPending), errors.Is(err, ErrApprovalExpired),
+		errors.Is(err, ErrProjectNotEmpty), errors.Is(err, ErrSecretsDisabled):
+		code = codes.FailedPrecondition
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
+
+// ---- Agent ----
+
+func (a *AdminServer) ListAgents(ctx context.Context, _ *pb.ListAgentsReq) (*pb.AgentList, error) {
+	agents, err := a.Srv.ListAgents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pbAgentList(agents), nil
+}
+
+func (a *AdminServer) GetAgent(ctx context.Context, req *pb.AgentReq) (*pb.Agent, error) {
+	setAuditTarget(ctx, req.AgentId)
+	agent, err := a.Srv.GetAgent(ctx, req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+	return pbAgent(agent), nil
+}
+
+func (a *AdminServer) SelectAgents(ctx context.Context, req *pb.Selector) (*pb.AgentList, error) {
+	agents, err := a.Srv.SelectAgents(ctx, fromPBSelector(req))
+	if err != nil {
+		return nil, err
+	}
+	return pbAgentList(agents), nil
+}
+
+func (a *AdminServer) GetAgentMetrics(ctx context.Context, req *pb.AgentMetricsReq) (*pb.AgentMetricsResp, error) {
+	window := time.Hour
+	if req.WindowSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "window_seconds must not be negative")
+	}
+	if req.WindowSeconds > 0 {
+		window = time.Duration(req.WindowSeconds) * time.Second
+	}
+	metrics, err := a.Srv.AgentMetrics(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.AgentMetricsResp{Agents: make(map[string]*pb.MetricSeries, len(metrics))}
+	for agentID, samples := range metrics {
+		series := &pb.MetricSeries{Points: make([]*pb.MetricPoint, len(samples))}
+		for i, m := range samples {
+			series.Points[i] = &pb.MetricPoint{Time: m.At.Unix(), Cpu: m.CPU, Mem: m.Mem}
+		}
+		resp.Agents[agentID] = series
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) GetEffectiveAgentConfig(ctx context.Context, req *pb.AgentReq) (*pb.AgentConfig, error) {
+	setAuditTarget(ctx, req.AgentId)
+	if _, err := a.Srv.GetAgent(ctx, req.AgentId); err != nil {
+		return nil, err
+	}
+	return a.Srv.EffectiveAgentConfig(ctx, req.AgentId)
+}
+
+func (a *AdminServer) ResetAgentCredential(ctx context.Context, req *pb.AgentReq) (*pb.Agent, error) {
+	setAuditTarget(ctx, req.AgentId)
+	agent, err := a.Srv.ResetAgentCredential(ctx, req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+	return pbAgent(agent), nil
+}
+
+// ---- 任务 ----
+
+func (a *AdminServer) SubmitJob(ctx context.Context, req *pb.SubmitJobReq) (*pb.SubmitJobResp, error) {
+	call := callFrom(ctx)
+	jr := JobRequest{TargetAgent: req.Target, Type: req.Type, Cmd: req.Cmd, Secrets: req.Secrets}
+	if req.Selector != nil {
+		sel := fromPBSelector(req.Selector)
+		jr.Selector = &sel
+	}
+	records, err := a.Srv.Submit(ctx, call.user.Name, jr)
+	if err != nil {
+		var qe *QuotaError
+		if errors.As(err, &qe) {
+			log.Printf("[Quota] %s 触发配额限制: %s", call.user.Name, qe.Error())
+		}
+		return nil, err
+	}
+	resp := &pb.SubmitJobResp{Jobs: make([]*pb.JobInfo, len(records))}
+	ids := make([]string, len(records))
+	for i, r := range records {
+		resp.Jobs[i] = pbJob(newJobView(r, false))
+		ids[i] = r.JobID
+	}
+	call.target = strings.Join(ids, ",")
+	log.Printf("[gRPC] %s 下发任务 -> %s (%d 台) : %s", call.user.Name, call.project, len(records), a.Srv.Redactor.Redact(req.Cmd))
+	return resp, nil
+}
+
+func (a *AdminServer) CancelJob(ctx context.Context, req *pb.JobReq) (*pb.JobInfo, error) {
+	setAuditTarget(ctx, req.JobId)
+	record, err := a.Srv.CancelJob(ctx, req.JobId)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] %s 取消任务 -> %s", callFrom(ctx).user.Name, record.JobID)
+	return pbJob(newJobView(record, false)), nil
+}
+
+func (a *AdminServer) ApproveJob(ctx context.Context, req *pb.JobReq) (*pb.JobInfo, error) {
+	setAuditTarget(ctx, req.JobId)
+	record, err := a.Srv.ApproveJob(ctx, req.JobId, callFrom(ctx).user)
+	if err != nil {
+		return nil, err
+	}
+	return pbJob(newJobView(record, false)), nil
+}
+
+func (a *AdminServer) GetJob(ctx context.Context, req *pb.JobReq) (*pb.JobInfo, error) {
+	setAuditTarget(ctx, req.JobId)
+	record, err := a.Srv.GetJob(ctx, req.JobId)
+	if err != nil {
+		return nil, err
+	}
+	return pbJob(newJobView(record, true)), nil
+}
+
+func (a *AdminServer) GetJobOutput(ctx context.Context, req *pb.JobOutputReq) (*pb.JobOutputResp, error) {
+	setAuditTarget(ctx, req.JobId)
+	page, err := a.Srv.JobOutput(ctx, req.JobId, req.AfterSeq, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.JobOutputResp{Status: page.Status, Finished: page.Finished}
+	for _, c := range page.Chunks {
+		resp.Chunks = append(resp.Chunks, &pb.JobOutputChunk{JobId: c.JobID, Seq: c.Seq, Data: c.Data})
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) ListJobs(ctx context.Context, req *pb.ListJobsReq) (*pb.ListJobsResp, error) {
+	records, next, err := a.Srv.ListJobs(ctx, JobQuery{
+		AgentID:     req.AgentId,
+		Status:      req.Status,
+		Type:        strings.ToUpper(req.Type),
+		SubmittedBy: req.SubmittedBy,
+		Since:       fromUnix(req.Since),
+		Until:       fromUnix(req.Until),
+		Text:        req.Text,
+		Sort:        req.Sort,
+		Cursor:      req.Cursor,
+		Limit:       int(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListJobsResp{Jobs: make([]*pb.JobInfo, len(records)), NextCursor: next}
+	for i := range records {
+		resp.Jobs[i] = pbJob(newJobView(&records[i], false))
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) SearchJobs(ctx context.Context, req *pb.SearchJobsReq) (*pb.SearchJobsResp, error) {
+	hits, next, err := a.Srv.Search(ctx, SearchQuery{
+		Phrase:  req.Phrase,
+		Regex:   req.Regex,
+		AgentID: req.AgentId,
+		Since:   fromUnix(req.Since),
+		Until:   fromUnix(req.Until),
+		Cursor:  req.Cursor,
+		Limit:   int(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.SearchJobsResp{Hits: make([]*pb.SearchHit, len(hits)), NextCursor: next}
+	for i, h := range hits {
+		resp.Hits[i] = pbSearchHit(h)
+	}
+	return resp, nil
+}
+
+// ---- Agent 配置 ----
+
+func (a *AdminServer) ListAgentConfigRules(ctx context.Context, _ *pb.Empty) (*pb.AgentConfigRuleList, error) {
+	rows, err := a.Srv.ListAgentConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.AgentConfigRuleList{Rules: make([]*pb.AgentConfigRule, len(rows))}
+	for i := range rows {
+		resp.Rules[i] = pbAgentConfigRule(&rows[i])
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) PutAgentConfigRule(ctx context.Context, req *pb.AgentConfigRule) (*pb.AgentConfigRule, error) {
+	setAuditTarget(ctx, req.Scope+"/"+req.Target)
+	saved, err := a.Srv.PutAgentConfig(ctx, &AgentConfigModel{
+		Scope:                    req.Scope,
+		Target:                   req.Target,
+		HeartbeatIntervalSeconds: req.HeartbeatIntervalSeconds,
+		WorkerPoolSize:           req.WorkerPoolSize,
+		AllowedJobTypes:          req.AllowedJobTypes,
+		LogLevel:                 req.LogLevel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员更新 Agent 配置 -> %s/%s", saved.Scope, saved.Target)
+	return pbAgentConfigRule(saved), nil
+}
+
+func (a *AdminServer) DeleteAgentConfigRule(ctx context.Context, req *pb.ScopeTarget) (*pb.Empty, error) {
+	setAuditTarget(ctx, req.Scope+"/"+req.Target)
+	if err := a.Srv.DeleteAgentConfig(ctx, req.Scope, req.Target); err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员删除 Agent 配置 -> %s/%s", req.Scope, req.Target)
+	return &pb.Empty{}, nil
+}
+
+// ---- 发布 ----
+
+// releaseReader 把上传流里的 data 消息拼成 io.Reader, 流结束时返回 io.EOF
+type releaseReader struct {
+	stream grpc.ClientStreamingServer[pb.UploadAgentReleaseReq, pb.AgentRelease]
+	buf    []byte
+}
+
+func (r *releaseReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if msg.GetMeta() != nil {
+			return 0, status.Error(codes.InvalidArgument, "meta must only be sent in the first message")
+		}
+		r.buf = msg.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (a *AdminServer) UploadAgentRelease(stream grpc.ClientStreamingServer[pb.UploadAgentReleaseReq, pb.AgentRelease]) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.GetMeta()
+	if meta == nil {
+		return status.Error(codes.InvalidArgument, "first message must carry meta")
+	}
+	setAuditTarget(ctx, meta.Version+"/"+meta.Os+"/"+meta.Arch)
+	rel, err := a.Srv.PublishRelease(ctx, meta.Version, meta.Os, meta.Arch, &releaseReader{stream: stream})
+	if err != nil {
+		return err
+	}
+	callFrom(ctx).payloadHash = rel.SHA256
+	return stream.SendAndClose(pbAgentRelease(rel))
+}
+
+func (a *AdminServer) ListAgentReleases(ctx context.Context, _ *pb.Empty) (*pb.AgentReleaseList, error) {
+	rels, err := a.Srv.ListReleases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.AgentReleaseList{Releases: make([]*pb.AgentRelease, len(rels))}
+	for i := range rels {
+		resp.Releases[i] = pbAgentRelease(&rels[i])
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) RolloutAgentRelease(ctx context.Context, req *pb.RolloutReq) (*pb.RolloutResp, error) {
+	setAuditTarget(ctx, req.Version)
+	res, err := a.Srv.RolloutRelease(ctx, req.Version, fromPBSelector(req.Selector))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员安排升级 -> %s (%d 台)", res.Version, len(res.Targeted))
+	resp := &pb.RolloutResp{Version: res.Version, Targeted: res.Targeted}
+	for _, s := range res.Skipped {
+		resp.Skipped = append(resp.Skipped, &pb.RolloutSkip{AgentId: s.AgentID, Reason: s.Reason})
+	}
+	return resp, nil
+}
+
+// ---- 审批规则 ----
+
+func (a *AdminServer) ListApprovalRules(ctx context.Context, _ *pb.Empty) (*pb.ApprovalRuleList, error) {
+	rules, err := a.Srv.ListApprovalRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ApprovalRuleList{Rules: make([]*pb.ApprovalRule, len(rules))}
+	for i := range rules {
+		resp.Rules[i] = pbApprovalRule(&rules[i])
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) PutApprovalRule(ctx context.Context, req *pb.ApprovalRule) (*pb.ApprovalRule, error) {
+	setAuditTarget(ctx, req.Name)
+	saved, err := a.Srv.PutApprovalRule(ctx, &ApprovalRule{
+		Name:           req.Name,
+		JobType:        req.JobType,
+		Selector:       fromPBSelector(req.Selector),
+		PayloadPattern: req.PayloadPattern,
+		TTLSeconds:     int(req.TtlSeconds),
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员更新审批规则 -> %s", saved.Name)
+	return pbApprovalRule(saved), nil
+}
+
+func (a *AdminServer) DeleteApprovalRule(ctx context.Context, req *pb.NameReq) (*pb.Empty, error) {
+	setAuditTarget(ctx, req.Name)
+	if err := a.Srv.DeleteApprovalRule(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员删除审批规则 -> %s", req.Name)
+	return &pb.Empty{}, nil
+}
+
+// ---- 密钥 ----
+
+func (a *AdminServer) ListSecrets(ctx context.Context, _ *pb.Empty) (*pb.SecretList, error) {
+	secrets, err := a.Srv.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.SecretList{Secrets: make([]*pb.SecretInfo, len(secrets))}
+	for i := range secrets {
+		resp.Secrets[i] = pbSecret(&secrets[i])
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) PutSecret(ctx context.Context, req *pb.PutSecretReq) (*pb.SecretInfo, error) {
+	in := req.GetSecret()
+	setAuditTarget(ctx, in.GetName())
+	sec := &Secret{
+		Name:         in.GetName(),
+		Description:  in.GetDescription(),
+		Project:      in.GetProject(),
+		Agents:       fromPBSelector(in.GetAgents()),
+		AllowedUsers: in.GetAllowedUsers(),
+	}
+	saved, err := a.Srv.PutSecret(ctx, sec, req.Value, callFrom(ctx).user.Name)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] %s 更新密钥 -> %s", saved.UpdatedBy, saved.Name)
+	return pbSecret(saved), nil
+}
+
+func (a *AdminServer) DeleteSecret(ctx context.Context, req *pb.NameReq) (*pb.Empty, error) {
+	setAuditTarget(ctx, req.Name)
+	if err := a.Srv.DeleteSecret(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] %s 删除密钥 -> %s", callFrom(ctx).user.Name, req.Name)
+	return &pb.Empty{}, nil
+}
+
+// ---- 用户 ----
+
+func (a *AdminServer) ListUsers(ctx context.Context, _ *pb.Empty) (*pb.UserList, error) {
+	users, err := a.Srv.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.UserList{Users: make([]*pb.User, len(users))}
+	for i := range users {
+		resp.Users[i] = pbUser(&users[i])
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) CreateUser(ctx context.Context, req *pb.CreateUserReq) (*pb.CreateUserResp, error) {
+	setAuditTarget(ctx, req.Name)
+	u, token, err := a.Srv.CreateUser(ctx, req.Name, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员创建用户 -> %s (%s)", u.Name, u.Role)
+	return &pb.CreateUserResp{User: pbUser(u), Token: token}, nil
+}
+
+func (a *AdminServer) DeleteUser(ctx context.Context, req *pb.NameReq) (*pb.Empty, error) {
+	setAuditTarget(ctx, req.Name)
+	if err := a.Srv.DeleteUser(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员删除用户 -> %s", req.Name)
+	return &pb.Empty{}, nil
+}
+
+// ---- 项目 ----
+
+func (a *AdminServer) ListProjects(ctx context.Context, _ *pb.Empty) (*pb.ProjectList, error) {
+	projects, err := a.Srv.ListProjects(ctx, callFrom(ctx).user)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ProjectList{Projects: make([]*pb.Project, len(projects))}
+	for i := range projects {
+		resp.Projects[i] = pbProject(&projects[i])
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) CreateProject(ctx context.Context, req *pb.CreateProjectReq) (*pb.CreateProjectResp, error) {
+	setAuditTarget(ctx, req.Name)
+	p, token, err := a.Srv.CreateProject(ctx, req.Name, req.Description)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员创建项目 -> %s", p.Name)
+	return &pb.CreateProjectResp{Project: pbProject(p), EnrollmentToken: token}, nil
+}
+
+func (a *AdminServer) DeleteProject(ctx context.Context, req *pb.ProjectReq) (*pb.Empty, error) {
+	setAuditTarget(ctx, req.Project)
+	if err := a.Srv.DeleteProject(ctx, req.Project); err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员删除项目 -> %s", req.Project)
+	return &pb.Empty{}, nil
+}
+
+func (a *AdminServer) RotateEnrollmentToken(ctx context.Context, _ *pb.ProjectReq) (*pb.EnrollmentToken, error) {
+	call := callFrom(ctx)
+	token, err := a.Srv.RotateEnrollmentToken(ctx, call.project)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] %s 轮换项目 %s 的 enrollment token", call.user.Name, call.project)
+	return &pb.EnrollmentToken{EnrollmentToken: token}, nil
+}
+
+func (a *AdminServer) ListMembers(ctx context.Context, _ *pb.ProjectReq) (*pb.MemberList, error) {
+	members, err := a.Srv.ListMembers(ctx, callFrom(ctx).project)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.MemberList{Members: make([]*pb.Member, len(members))}
+	for i, m := range members {
+		resp.Members[i] = &pb.Member{Project: m.Project, User: m.User, Role: m.Role}
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) PutMember(ctx context.Context, req *pb.Member) (*pb.Member, error) {
+	call := callFrom(ctx)
+	call.target = call.project + "/" + req.User
+	m, err := a.Srv.PutMember(ctx, call.project, req.User, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] %s 设置项目成员 -> %s/%s (%s)", call.user.Name, m.Project, m.User, m.Role)
+	return &pb.Member{Project: m.Project, User: m.User, Role: m.Role}, nil
+}
+
+func (a *AdminServer) DeleteMember(ctx context.Context, req *pb.Member) (*pb.Empty, error) {
+	call := callFrom(ctx)
+	call.target = call.project + "/" + req.User
+	if err := a.Srv.DeleteMember(ctx, call.project, req.User); err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] %s 移除项目成员 -> %s/%s", call.user.Name, call.project, req.User)
+	return &pb.Empty{}, nil
+}
+
+// ---- 配额 ----
+
+func (a *AdminServer) GetQuotaUsage(ctx context.Context, _ *pb.Empty) (*pb.QuotaUsageResp, error) {
+	call := callFrom(ctx)
+	user, err := a.Srv.QuotaUsage(ctx, QuotaScopeUser, call.user.Name)
+	if err != nil {
+		return nil, err
+	}
+	project, err := a.Srv.QuotaUsage(ctx, QuotaScopeProject, call.project)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.QuotaUsageResp{User: pbQuotaUsage(user), Project: pbQuotaUsage(project)}, nil
+}
+
+func (a *AdminServer) ListQuotas(ctx context.Context, _ *pb.Empty) (*pb.QuotaList, error) {
+	rows, err := a.Srv.ListQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.QuotaList{
+		Quotas:         make([]*pb.Quota, len(rows)),
+		DefaultUser:    pbQuotaLimits(a.Srv.UserQuota),
+		DefaultProject: pbQuotaLimits(a.Srv.ProjectQuota),
+	}
+	for i, q := range rows {
+		resp.Quotas[i] = &pb.Quota{Scope: q.Scope, Target: q.Target, Limits: pbQuotaLimits(q.QuotaLimits)}
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) PutQuota(ctx context.Context, req *pb.Quota) (*pb.Quota, error) {
+	setAuditTarget(ctx, req.Scope+"/"+req.Target)
+	saved, err := a.Srv.PutQuota(ctx, &QuotaModel{Scope: req.Scope, Target: req.Target, QuotaLimits: fromPBQuotaLimits(req.Limits)})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员调整配额 -> %s/%s", saved.Scope, saved.Target)
+	return &pb.Quota{Scope: saved.Scope, Target: saved.Target, Limits: pbQuotaLimits(saved.QuotaLimits)}, nil
+}
+
+func (a *AdminServer) DeleteQuota(ctx context.Context, req *pb.ScopeTarget) (*pb.Empty, error) {
+	setAuditTarget(ctx, req.Scope+"/"+req.Target)
+	if err := a.Srv.DeleteQuota(ctx, req.Scope, req.Target); err != nil {
+		return nil, err
+	}
+	log.Printf("[gRPC] 管理员删除配额调整 -> %s/%s", req.Scope, req.Target)
+	return &pb.Empty{}, nil
+}
+
+// ---- 审计 ----
+
+func (a *AdminServer) QueryAudit(ctx context.Context, req *pb.AuditFilter) (*pb.AuditPage, error) {
+	f := fromPBAuditFilter(req)
+	entries, err := a.Srv.Audit.Query(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.AuditPage{Entries: make([]*pb.AuditEntry, len(entries))}
+	for i := range entries {
+		resp.Entries[i] = pbAuditEntry(&entries[i])
+	}
+	if len(entries) == f.Limit {
+		resp.NextAfterSeq = entries[len(entries)-1].Seq
+	}
+	return resp, nil
+}
+
+func (a *AdminServer) ExportAudit(req *pb.AuditFilter, stream grpc.ServerStreamingServer[pb.AuditEntry]) error {
+	return a.Srv.Audit.Export(stream.Context(), fromPBAuditFilter(req), func(e *audit.Entry) error {
+		return stream.Send(pbAuditEntry(e))
+	})
+}
+
+func (a *AdminServer) VerifyAudit(ctx context.Context, _ *pb.Empty) (*pb.AuditVerifyResult, error) {
+	res, err := a.Srv.Audit.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Valid {
+		log.Printf("[Audit] ⚠️ 审计日志哈希链校验失败: seq=%d %s", res.BrokenAt, res.Reason)
+	}
+	return &pb.AuditVerifyResult{
+		Valid:    res.Valid,
+		Entries:  res.Entries,
+		HeadSeq:  res.HeadSeq,
+		HeadHash: res.HeadHash,
+		BrokenAt: res.BrokenAt,
+		Reason:   res.Reason,
+	}, nil
+}
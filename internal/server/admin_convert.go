@@ -0,0 +1,209 @@
+package server
+
+import (
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/audit"
+)
+
+// SentinelAdmin 消息和服务层结构之间的转换, 时间都换成 unix 秒, 零值为 0
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func unixPtr(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return unix(*t)
+}
+
+func fromUnix(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func pbAgent(a *AgentModel) *pb.Agent {
+	return &pb.Agent{
+		AgentId:       a.AgentID,
+		Project:       a.Project,
+		Hostname:      a.Hostname,
+		Ip:            a.IP,
+		Status:        a.Status,
+		Tags:          splitTags(a.Tags),
+		LastSeen:      unix(a.LastSeen),
+		Version:       a.Version,
+		Os:            a.OS,
+		Arch:          a.Arch,
+		TargetVersion: a.TargetVersion,
+		UpdateError:   a.UpdateError,
+		CreatedAt:     unix(a.CreatedAt),
+	}
+}
+
+func pbAgentList(agents []AgentModel) *pb.AgentList {
+	resp := &pb.AgentList{Agents: make([]*pb.Agent, len(agents))}
+	for i := range agents {
+		resp.Agents[i] = pbAgent(&agents[i])
+	}
+	return resp
+}
+
+func fromPBSelector(s *pb.Selector) Selector {
+	return Selector{All: s.GetAll(), AgentIDs: s.GetAgentIds(), Tags: s.GetTags(), Status: s.GetStatus()}
+}
+
+func pbSelector(s Selector) *pb.Selector {
+	return &pb.Selector{All: s.All, AgentIds: s.AgentIDs, Tags: s.Tags, Status: s.Status}
+}
+
+func pbJob(v JobView) *pb.JobInfo {
+	job := &pb.JobInfo{
+		JobId:             v.JobID,
+		AgentId:           v.AgentID,
+		Project:           v.Project,
+		Type:              v.Type,
+		Status:            v.Status,
+		Payload:           v.Payload,
+		Result:            v.Result,
+		SubmittedBy:       v.SubmittedBy,
+		ApprovalRule:      v.ApprovalRule,
+		ApprovalExpiresAt: unixPtr(v.ApprovalExpiresAt),
+		ApprovedBy:        v.ApprovedBy,
+		ApprovedAt:        unixPtr(v.ApprovedAt),
+		CreatedAt:         unix(v.CreatedAt),
+		UpdatedAt:         unix(v.UpdatedAt),
+		DispatchedAt:      unixPtr(v.DispatchedAt),
+		ExecutedAt:        unixPtr(v.ExecutedAt),
+		ExpiresAt:         unixPtr(v.ExpiresAt),
+	}
+	for _, ref := range v.Secrets {
+		job.Secrets = append(job.Secrets, &pb.SecretRef{Env: ref.Env, Name: ref.Name})
+	}
+	return job
+}
+
+func pbSearchHit(h SearchHit) *pb.SearchHit {
+	hit := &pb.SearchHit{Job: pbJob(h.Job), Matches: int32(h.Matches)}
+	for _, sn := range h.Snippets {
+		s := &pb.Snippet{Line: int32(sn.Line)}
+		for _, p := range sn.Parts {
+			s.Parts = append(s.Parts, &pb.SnippetPart{Text: p.Text, Match: p.Match})
+		}
+		hit.Snippets = append(hit.Snippets, s)
+	}
+	return hit
+}
+
+func pbAgentConfigRule(m *AgentConfigModel) *pb.AgentConfigRule {
+	return &pb.AgentConfigRule{
+		Scope:                    m.Scope,
+		Target:                   m.Target,
+		HeartbeatIntervalSeconds: m.HeartbeatIntervalSeconds,
+		WorkerPoolSize:           m.WorkerPoolSize,
+		AllowedJobTypes:          m.AllowedJobTypes,
+		LogLevel:                 m.LogLevel,
+		UpdatedAt:                unix(m.UpdatedAt),
+	}
+}
+
+func pbAgentRelease(r *AgentRelease) *pb.AgentRelease {
+	return &pb.AgentRelease{
+		Version:   r.Version,
+		Os:        r.OS,
+		Arch:      r.Arch,
+		Sha256:    r.SHA256,
+		Size:      r.Size,
+		CreatedAt: unix(r.CreatedAt),
+	}
+}
+
+func pbApprovalRule(r *ApprovalRule) *pb.ApprovalRule {
+	return &pb.ApprovalRule{
+		Name:           r.Name,
+		JobType:        r.JobType,
+		Selector:       pbSelector(r.Selector),
+		PayloadPattern: r.PayloadPattern,
+		TtlSeconds:     int32(r.TTLSeconds),
+	}
+}
+
+func pbSecret(s *Secret) *pb.SecretInfo {
+	return &pb.SecretInfo{
+		Name:         s.Name,
+		Description:  s.Description,
+		Project:      s.Project,
+		Agents:       pbSelector(s.Agents),
+		AllowedUsers: s.AllowedUsers,
+		UpdatedBy:    s.UpdatedBy,
+		UpdatedAt:    unix(s.UpdatedAt),
+	}
+}
+
+func pbUser(u *User) *pb.User {
+	return &pb.User{Name: u.Name, Role: u.Role, CreatedAt: unix(u.CreatedAt)}
+}
+
+func pbProject(p *Project) *pb.Project {
+	return &pb.Project{Name: p.Name, Description: p.Description, CreatedAt: unix(p.CreatedAt)}
+}
+
+func pbQuotaLimits(l QuotaLimits) *pb.QuotaLimits {
+	return &pb.QuotaLimits{
+		JobsPerMinute:     int32(l.JobsPerMinute),
+		MaxConcurrentJobs: int32(l.MaxConcurrentJobs),
+		MaxFanOut:         int32(l.MaxFanOut),
+	}
+}
+
+func fromPBQuotaLimits(l *pb.QuotaLimits) QuotaLimits {
+	return QuotaLimits{
+		JobsPerMinute:     int(l.GetJobsPerMinute()),
+		MaxConcurrentJobs: int(l.GetMaxConcurrentJobs()),
+		MaxFanOut:         int(l.GetMaxFanOut()),
+	}
+}
+
+func pbQuotaUsage(u *QuotaUsage) *pb.QuotaUsage {
+	return &pb.QuotaUsage{
+		Scope:          u.Scope,
+		Target:         u.Target,
+		Limits:         pbQuotaLimits(u.Limits),
+		JobsLastMinute: int32(u.JobsLastMinute),
+		UnfinishedJobs: int32(u.UnfinishedJobs),
+	}
+}
+
+func fromPBAuditFilter(f *pb.AuditFilter) audit.Filter {
+	return audit.Filter{
+		Actor:    f.Actor,
+		Action:   f.Action,
+		Target:   f.Target,
+		Since:    fromUnix(f.Since),
+		Until:    fromUnix(f.Until),
+		AfterSeq: f.AfterSeq,
+		Limit:    auditPageLimit(int(f.Limit)),
+	}
+}
+
+func pbAuditEntry(e *audit.Entry) *pb.AuditEntry {
+	return &pb.AuditEntry{
+		Seq:         e.Seq,
+		TimeMs:      e.Time.UnixMilli(),
+		Actor:       e.Actor,
+		SourceIp:    e.SourceIP,
+		Action:      e.Action,
+		Target:      e.Target,
+		PayloadHash: e.PayloadHash,
+		Result:      e.Result,
+		PrevHash:    e.PrevHash,
+		Hash:        e.Hash,
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// Agent 的身份凭据. 第一次注册成功时服务端签发一个 per-agent credential, 库里只存 sha256;
+// 之后 Agent 的每个调用都在 metadata 里带上自己的 ID 和 credential, 注册以外的方法由拦截器校验.
+const (
+	AgentIDMetadata         = "x-sentinel-agent-id"
+	AgentCredentialMetadata = "x-sentinel-agent-credential"
+)
+
+var ErrAgentCredential = errors.New("invalid agent credential")
+
+// credentialReset 管理员重置过的 Agent 的 CredentialHash, 不会和任何 sha256 相等.
+// 和升级前注册、从没有过 credential 的 Agent 不同, 重置过的必须带 enrollment token 才能重新领取.
+const credentialReset = "reset"
+
+var agentMethodPrefix = "/" + pb.SentinelService_ServiceDesc.ServiceName + "/"
+
+type agentKey struct{}
+
+// agentFrom 拦截器校验过的 Agent, 只能在 SentinelService 的方法里调用
+func agentFrom(ctx context.Context) *AgentModel {
+	return ctx.Value(agentKey{}).(*AgentModel)
+}
+
+func presentedCredential(ctx context.Context) (agentID, credential string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return firstMetadata(md, AgentIDMetadata), firstMetadata(md, AgentCredentialMetadata)
+}
+
+// hasCredential Agent 是否持有有效的 credential; 升级前注册和被重置的 Agent 都没有
+func hasCredential(agent *AgentModel) bool {
+	return agent.CredentialHash != "" && agent.CredentialHash != credentialReset
+}
+
+func credentialMatches(hash, credential string) bool {
+	return hash != "" && credential != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(hashToken(credential))) == 1
+}
+
+// authenticateAgent 按 metadata 里的 ID 和 credential 找到 Agent. 还没拿到 credential 的 Agent 只能调 Register.
+func (s *SentinelServer) authenticateAgent(ctx context.Context) (*AgentModel, error) {
+	agentID, credential := presentedCredential(ctx)
+	if agentID == "" || credential == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing agent credential, register first")
+	}
+	var agent AgentModel
+	err := s.DB.WithContext(ctx).Where("agent_id = ?", agentID).First(&agent).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil || !credentialMatches(agent.CredentialHash, credential) {
+		log.Printf("[Auth] ⚠️ 拒绝 Agent %s 的调用: credential 无效 (来自 %s)", agentID, peerIP(ctx))
+		return nil, status.Error(codes.Unauthenticated, ErrAgentCredential.Error())
+	}
+	return &agent, nil
+}
+
+// AgentUnaryInterceptor 校验 SentinelService 除 Register 以外的调用, 管理接口原样放行
+func (s *SentinelServer) AgentUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !strings.HasPrefix(info.FullMethod, agentMethodPrefix) || info.FullMethod == pb.SentinelService_Register_FullMethodName {
+			return handler(ctx, req)
+		}
+		agent, err := s.authenticateAgent(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, agentKey{}, agent), req)
+	}
+}
+
+// AgentStreamInterceptor 同 AgentUnaryInterceptor, 用于心跳流和二进制下载
+func (s *SentinelServer) AgentStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, agentMethodPrefix) {
+			return handler(srv, ss)
+		}
+		agent, err := s.authenticateAgent(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &ctxStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), agentKey{}, agent)})
+	}
+}
+
+// checkAgentID 请求体里的 agent_id 必须和凭据对应的 Agent 一致
+func checkAgentID(ctx context.Context, agentID string) error {
+	if agent := agentFrom(ctx); agentID != agent.AgentID {
+		log.Printf("[Auth] ⚠️ Agent %s 试图以 %s 的身份调用", agent.AgentID, agentID)
+		return status.Errorf(codes.PermissionDenied, "credential belongs to agent %s", agent.AgentID)
+	}
+	return nil
+}
+
+// admitAgent 注册时的身份校验, 返回 Agent 应在的项目以及新签发的 credential (不需要签发时为空).
+//   - 新 Agent: 按 enrollment token 决定项目, 签发 credential
+//   - 有 credential 的 Agent: 必须出示 credential, 之后才按 enrollment token 处理项目迁移
+//   - 还没有 credential 的 Agent (升级前注册的, 或被管理员重置过): 只能留在原项目, 出示原项目的
+//     enrollment token 后签发; 升级前注册的 Agent 在原项目没有 token 且不要求 enrollment 时直接签发 (首次使用即信任)
+func (s *SentinelServer) admitAgent(ctx context.Context, agent *AgentModel, known bool, req *pb.RegisterReq) (string, string, error) {
+	_, presented := presentedCredential(ctx)
+	if known && hasCredential(agent) {
+		if !credentialMatches(agent.CredentialHash, presented) {
+			return "", "", ErrAgentCredential
+		}
+		project, err := s.enrollmentProject(ctx, agent.Project, req.EnrollmentToken)
+		return project, "", err
+	}
+
+	project := agent.Project
+	if !known {
+		var err error
+		if project, err = s.enrollmentProject(ctx, "", req.EnrollmentToken); err != nil {
+			return "", "", err
+		}
+	} else {
+		p, err := s.findProject(ctx, project)
+		if err != nil {
+			return "", "", err
+		}
+		switch {
+		case req.EnrollmentToken != "" && hashToken(req.EnrollmentToken) == p.EnrollTokenHash:
+		case agent.CredentialHash == "" && p.EnrollTokenHash == "" && !s.RequireEnrollment:
+			log.Printf("[Auth] ⚠️ %s 没有 credential, 项目 %s 也没有 enrollment token, 首次使用即签发", agent.AgentID, project)
+		default:
+			return "", "", fmt.Errorf("%w: agent has no credential yet, register with the enrollment token of project %s", ErrEnrollmentRequired, project)
+		}
+	}
+	credential, err := newToken("sna_")
+	if err != nil {
+		return "", "", err
+	}
+	agent.CredentialHash = hashToken(credential)
+	return project, credential, nil
+}
+
+// ResetAgentCredential 作废 Agent 的 credential, 它的心跳流在下一次同步时断开. 用于 Agent 丢了本地状态或凭据泄露;
+// 之后 Agent 要带所在项目的 enrollment token 重新注册才能拿到新的 credential, 项目没有 token 时先轮换出一个.
+func (s *SentinelServer) ResetAgentCredential(ctx context.Context, agentID string) (*AgentModel, error) {
+	agent, err := s.GetAgent(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DB.WithContext(ctx).Model(&AgentModel{}).Where("agent_id = ?", agentID).Update("credential_hash", credentialReset).Error; err != nil {
+		return nil, err
+	}
+	agent.CredentialHash = credentialReset
+	log.Printf("[Auth] 已重置 Agent %s 的 credential", agentID)
+	return agent, nil
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/cluster"
+	"gorm.io/gorm"
+)
+
+// 配置作用范围: 单个 Agent 或者带某个标签的一组 Agent
+const (
+	ConfigScopeAgent = "agent"
+	ConfigScopeTag   = "tag"
+)
+
+var (
+	ErrInvalidAgentConfig  = errors.New("invalid agent config")
+	ErrAgentConfigNotFound = errors.New("agent config not found")
+)
+
+var validLogLevels = map[string]bool{"": true, "debug": true, "info": true, "warn": true, "error": true}
+
+// AgentConfigModel 服务端管理的 Agent 配置. 零值字段表示不覆盖, 由更低优先级的配置或 Agent 本地配置决定.
+// 合并顺序: 标签组配置 (按标签名排序) < 单个 Agent 的配置.
+type AgentConfigModel struct {
+	gorm.Model
+	Scope                    string `gorm:"uniqueIndex:idx_agent_config_target;size:16" json:"scope"`
+	Target                   string `gorm:"uniqueIndex:idx_agent_config_target;size:191" json:"target"`
+	HeartbeatIntervalSeconds int32  `json:"heartbeat_interval_seconds"`
+	WorkerPoolSize           int32  `json:"worker_pool_size"`
+	AllowedJobTypes          string `json:"allowed_job_types"` // 逗号分隔的 JobType 名字, 例如 "PING,SCAN"
+	LogLevel                 string `gorm:"size:16" json:"log_level"`
+}
+
+func (AgentConfigModel) TableName() string { return "agent_configs" }
+
+// Validate 检查取值范围并规整 AllowedJobTypes, maxHeartbeat 见 SentinelServer.maxHeartbeatInterval
+func (m *AgentConfigModel) Validate(maxHeartbeat time.Duration) error {
+	if m.Scope != ConfigScopeAgent && m.Scope != ConfigScopeTag {
+		return fmt.Errorf("%w: scope must be %q or %q", ErrInvalidAgentConfig, ConfigScopeAgent, ConfigScopeTag)
+	}
+	if m.Target == "" {
+		return fmt.Errorf("%w: target is required", ErrInvalidAgentConfig)
+	}
+	if m.Scope == ConfigScopeTag && !validTag(m.Target) {
+		return fmt.Errorf("%w: tag must not contain ',', '%%' or '_'", ErrInvalidAgentConfig)
+	}
+	if limit := int32(maxHeartbeat / time.Second); m.HeartbeatIntervalSeconds < 0 || m.HeartbeatIntervalSeconds > limit {
+		return fmt.Errorf("%w: heartbeat_interval_seconds must be within 0-%d (a third of cluster.owner_ttl and the offline threshold)",
+			ErrInvalidAgentConfig, limit)
+	}
+	if m.WorkerPoolSize < 0 || m.WorkerPoolSize > 256 {
+		return fmt.Errorf("%w: worker_pool_size must be within 0-256", ErrInvalidAgentConfig)
+	}
+	m.LogLevel = strings.ToLower(m.LogLevel)
+	if !validLogLevels[m.LogLevel] {
+		return fmt.Errorf("%w: unknown log_level %q", ErrInvalidAgentConfig, m.LogLevel)
+	}
+	types, err := parseJobTypes(m.AllowedJobTypes)
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	m.AllowedJobTypes = strings.Join(names, ",")
+	return nil
+}
+
+func parseJobTypes(s string) ([]pb.JobType, error) {
+	var types []pb.JobType
+	seen := make(map[pb.JobType]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		v, ok := pb.JobType_value[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown job type %q", ErrInvalidAgentConfig, name)
+		}
+		if !seen[pb.JobType(v)] {
+			seen[pb.JobType(v)] = true
+			types = append(types, pb.JobType(v))
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types, nil
+}
+
+// joinTags 标签以 ",a,b," 的形式存储, 方便用 LIKE '%,tag,%' 按标签查 Agent
+func joinTags(tags []string) string {
+	var clean []string
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if validTag(t) {
+			clean = append(clean, t)
+		}
+	}
+	if len(clean) == 0 {
+		return ""
+	}
+	sort.Strings(clean)
+	return "," + strings.Join(clean, ",") + ","
+}
+
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' })
+}
+
+// EffectiveAgentConfig 合并出某个 Agent 当前应当生效的配置. 没有任何配置时 Version 为 "".
+func (s *SentinelServer) EffectiveAgentConfig(ctx context.Context, agentID string) (*pb.AgentConfig, error) {
+	var agent AgentModel
+	err := s.DB.WithContext(ctx).Where("agent_id = ?", agentID).First(&agent).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var rows []AgentConfigModel
+	q := s.DB.WithContext(ctx).Where("scope = ? AND target = ?", ConfigScopeAgent, agentID)
+	if tags := splitTags(agent.Tags); len(tags) > 0 {
+		q = q.Or("scope = ? AND target IN ?", ConfigScopeTag, tags)
+	}
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Scope != rows[j].Scope {
+			return rows[i].Scope == ConfigScopeTag
+		}
+		return rows[i].Target < rows[j].Target
+	})
+
+	cfg := &pb.AgentConfig{}
+	// 调小 cluster.owner_ttl 之前保存的配置可能超出上限, 下发时再收紧一次
+	maxHeartbeat := int32(s.maxHeartbeatInterval() / time.Second)
+	for _, r := range rows {
+		if r.HeartbeatIntervalSeconds > 0 {
+			cfg.HeartbeatIntervalSeconds = min(r.HeartbeatIntervalSeconds, maxHeartbeat)
+		}
+		if r.WorkerPoolSize > 0 {
+			cfg.WorkerPoolSize = r.WorkerPoolSize
+		}
+		if r.AllowedJobTypes != "" {
+			cfg.AllowedJobTypes, _ = parseJobTypes(r.AllowedJobTypes)
+		}
+		if r.LogLevel != "" {
+			cfg.LogLevel = r.LogLevel
+		}
+	}
+	cfg.Version = configVersion(cfg)
+	return cfg, nil
+}
+
+// maxHeartbeatInterval 远程配置允许的最长心跳间隔. 归属登记 (OwnerTTL) 和离线判定 (agentOfflineAfter)
+// 过期之前至少要来三次心跳, 否则任务路由会丢失归属, Agent 会在 Online / Offline 之间来回跳.
+func (s *SentinelServer) maxHeartbeatInterval() time.Duration {
+	limit := agentOfflineAfter
+	if s.OwnerTTL > 0 {
+		limit = min(limit, s.OwnerTTL)
+	}
+	return limit / 3
+}
+
+// configVersion 按内容算版本号, 多个实例各自计算也能得到同一个结果
+func configVersion(cfg *pb.AgentConfig) string {
+	if cfg.HeartbeatIntervalSeconds == 0 && cfg.WorkerPoolSize == 0 &&
+		len(cfg.AllowedJobTypes) == 0 && cfg.LogLevel == "" {
+		return ""
+	}
+	types := make([]string, len(cfg.AllowedJobTypes))
+	for i, t := range cfg.AllowedJobTypes {
+		types[i] = t.String()
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d|%d|%s|%s",
+		cfg.HeartbeatIntervalSeconds, cfg.WorkerPoolSize, strings.Join(types, ","), cfg.LogLevel))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *SentinelServer) GetAgentConfig(ctx context.Context, req *pb.GetAgentConfigReq) (*pb.AgentConfig, error) {
+	if err := checkAgentID(ctx, req.AgentId); err != nil {
+		return nil, err
+	}
+	cfg, err := s.EffectiveAgentConfig(ctx, req.AgentId)
+	if err != nil {
+		log.Printf("[Config] 计算 %s 的配置失败: %v", req.AgentId, err)
+		return nil, err
+	}
+	log.Printf("[Config] Agent %s 拉取配置, 版本: %q", req.AgentId, cfg.Version)
+	return cfg, nil
+}
+
+// PutAgentConfig 新建或覆盖一条配置, 并通知受影响的在线 Agent
+func (s *SentinelServer) PutAgentConfig(ctx context.Context, m *AgentConfigModel) (*AgentConfigModel, error) {
+	m.Model = gorm.Model{}
+	if err := m.Validate(s.maxHeartbeatInterval()); err != nil {
+		return nil, err
+	}
+	var existing AgentConfigModel
+	err := s.DB.WithContext(ctx).Where("scope = ? AND target = ?", m.Scope, m.Target).First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.DB.WithContext(ctx).Create(m).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		m.ID, m.CreatedAt = existing.ID, existing.CreatedAt
+		if err := s.DB.WithContext(ctx).Save(m).Error; err != nil {
+			return nil, err
+		}
+	}
+	s.notifyConfigChanged(ctx, m.Scope, m.Target)
+	return m, nil
+}
+
+func (s *SentinelServer) DeleteAgentConfig(ctx context.Context, scope, target string) error {
+	res := s.DB.WithContext(ctx).Unscoped().
+		Where("scope = ? AND target = ?", scope, target).Delete(&AgentConfigModel{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAgentConfigNotFound
+	}
+	s.notifyConfigChanged(ctx, scope, target)
+	return nil
+}
+
+func (s *SentinelServer) ListAgentConfigs(ctx context.Context) ([]AgentConfigModel, error) {
+	var rows []AgentConfigModel
+	err := s.DB.WithContext(ctx).Order("scope, target").Find(&rows).Error
+	return rows, err
+}
+
+// notifyConfigChanged 让持有这些 Agent 心跳流的实例立即重新计算配置版本.
+// 通知丢了也没关系, 心跳流每隔 queueResyncInterval 会自己重算一次.
+func (s *SentinelServer) notifyConfigChanged(ctx context.Context, scope, target string) {
+	agentIDs := []string{target}
+	if scope == ConfigScopeTag {
+		agentIDs = nil
+		err := s.DB.WithContext(ctx).Model(&AgentModel{}).
+			Where("status = ? AND tags LIKE ?", AgentStatusOnline, "%,"+target+",%").
+			Pluck("agent_id", &agentIDs).Error
+		if err != nil {
+			log.Printf("[Config] 查询标签 %s 下的 Agent 失败: %v", target, err)
+			return
+		}
+	}
+	for _, id := range agentIDs {
+		s.route(ctx, cluster.Message{Kind: cluster.KindRefresh, AgentID: id})
+	}
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/cluster"
+	"github.com/stywzn/Go-Cloud-Compute/internal/events"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidApprovalRule  = errors.New("invalid approval rule")
+	ErrApprovalRuleNotFound = errors.New("approval rule not found")
+	ErrJobNotPending        = errors.New("job is not pending approval")
+	ErrSelfApproval         = errors.New("job cannot be approved by its submitter")
+	ErrApprovalExpired      = errors.New("approval window has expired")
+)
+
+// ApprovalRule 命中的任务必须由提交者以外的 approver 批准后才会派发.
+// 任务类型、目标 Agent、payload 三个条件是 AND 关系, 留空的条件不参与匹配.
+type ApprovalRule struct {
+	gorm.Model
+	Name           string   `gorm:"uniqueIndex;size:191" json:"name"`
+	JobType        string   `gorm:"size:32" json:"job_type"`         // 例如 SHELL, 为空匹配所有类型
+	Selector       Selector `gorm:"serializer:json" json:"selector"` // 按 ID / 标签匹配目标 Agent, 不支持 status
+	PayloadPattern string   `json:"payload_pattern"`                 // 正则, 为空匹配所有 payload
+	TTLSeconds     int      `json:"ttl_seconds"`                     // 等待审批的最长时间, 0 表示沿用 server.approval_ttl
+}
+
+// Validate 检查取值并规整 JobType
+func (r *ApprovalRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidApprovalRule)
+	}
+	if r.JobType != "" {
+		v, ok := pb.JobType_value[strings.ToUpper(r.JobType)]
+		if !ok {
+			return fmt.Errorf("%w: unknown job_type %q", ErrInvalidApprovalRule, r.JobType)
+		}
+		r.JobType = pb.JobType(v).String()
+	}
+	if err := r.Selector.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidApprovalRule, err)
+	}
+	if r.Selector.Status != "" {
+		return fmt.Errorf("%w: selector.status is not supported in approval rules", ErrInvalidApprovalRule)
+	}
+	if _, err := regexp.Compile(r.PayloadPattern); err != nil {
+		return fmt.Errorf("%w: payload_pattern: %v", ErrInvalidApprovalRule, err)
+	}
+	if r.TTLSeconds < 0 {
+		return fmt.Errorf("%w: ttl_seconds must not be negative", ErrInvalidApprovalRule)
+	}
+	return nil
+}
+
+// Matches agent 为目标 Agent 的记录, 见 GetAgent
+func (r *ApprovalRule) Matches(record *JobRecord, agent *AgentModel) bool {
+	if r.JobType != "" && r.JobType != record.Type {
+		return false
+	}
+	if !r.Selector.Matches(agent) {
+		return false
+	}
+	if r.PayloadPattern != "" {
+		// 规则入库前校验过, 万一编译失败宁可多拦
+		re, err := regexp.Compile(r.PayloadPattern)
+		if err == nil && !re.MatchString(record.Payload) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchApprovalRule 返回第一条 (按名字排序) 命中的规则, 没有命中返回 nil
+func (s *SentinelServer) matchApprovalRule(ctx context.Context, record *JobRecord, agent *AgentModel) (*ApprovalRule, error) {
+	var rules []ApprovalRule
+	if err := s.DB.WithContext(ctx).Order("name").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].Matches(record, agent) {
+			return &rules[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *SentinelServer) approvalTTL(rule *ApprovalRule) time.Duration {
+	if rule.TTLSeconds > 0 {
+		return time.Duration(rule.TTLSeconds) * time.Second
+	}
+	return s.ApprovalTTL
+}
+
+func (s *SentinelServer) PutApprovalRule(ctx context.Context, r *ApprovalRule) (*ApprovalRule, error) {
+	r.Model = gorm.Model{}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	var existing ApprovalRule
+	err := s.DB.WithContext(ctx).Where("name = ?", r.Name).First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.DB.WithContext(ctx).Create(r).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		r.ID, r.CreatedAt = existing.ID, existing.CreatedAt
+		if err := s.DB.WithContext(ctx).Save(r).Error; err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (s *SentinelServer) DeleteApprovalRule(ctx context.Context, name string) error {
+	res := s.DB.WithContext(ctx).Unscoped().Where("name = ?", name).Delete(&ApprovalRule{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrApprovalRuleNotFound
+	}
+	return nil
+}
+
+func (s *SentinelServer) ListApprovalRules(ctx context.Context) ([]ApprovalRule, error) {
+	var rules []ApprovalRule
+	err := s.DB.WithContext(ctx).Order("name").Find(&rules).Error
+	return rules, err
+}
+
+// ApproveJob PendingApproval -> Queued. 批准人不能是提交者, 角色由 HTTP 层校验.
+// 批准时才签名, 签名有效期从这一刻起算.
+func (s *SentinelServer) ApproveJob(ctx context.Context, jobID string, approver *User) (*JobRecord, error) {
+	var record JobRecord
+	if err := s.DB.WithContext(ctx).Where("job_id = ?", jobID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	if record.Status != JobStatusPendingApproval {
+		return &record, ErrJobNotPending
+	}
+	if record.SubmittedBy == approver.Name {
+		return &record, ErrSelfApproval
+	}
+	now := time.Now()
+	if record.ApprovalExpiresAt != nil && now.After(*record.ApprovalExpiresAt) {
+		if ok, _ := s.expireApproval(s.DB.WithContext(ctx), &record); ok {
+			s.emitJobEvent(events.TypeJobFailed, &record)
+		}
+		return &record, ErrApprovalExpired
+	}
+
+	record.Status = JobStatusQueued
+	record.ApprovedBy = approver.Name
+	record.ApprovedAt = &now
+	s.sealJob(&record)
+	// 条件更新, 和取消、过期清理互斥
+	res := s.DB.WithContext(ctx).Model(&JobRecord{}).
+		Where("job_id = ? AND status = ? AND approval_expires_at > ?", jobID, JobStatusPendingApproval, now).
+		Updates(map[string]any{
+			"status":      JobStatusQueued,
+			"approved_by": record.ApprovedBy,
+			"approved_at": record.ApprovedAt,
+			"expires_at":  record.ExpiresAt,
+			"signature":   record.Signature,
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected != 1 {
+		if err := s.DB.WithContext(ctx).Where("job_id = ?", jobID).First(&record).Error; err != nil {
+			return nil, err
+		}
+		return &record, ErrJobNotPending
+	}
+	log.Printf("[Approval] 任务 %s 已由 %s 批准 (提交者: %s)", jobID, approver.Name, record.SubmittedBy)
+	s.emitJobEvent(events.TypeJobQueued, &record)
+	s.route(ctx, cluster.Message{Kind: cluster.KindPush, AgentID: record.AgentID, JobID: jobID})
+	return &record, nil
+}
+
+// expireApproval 审批超时的任务判失败, record 原地更新. 事件由调用方在事务提交之后发.
+func (s *SentinelServer) expireApproval(db *gorm.DB, record *JobRecord) (bool, error) {
+	now := time.Now()
+	res := db.Model(&JobRecord{}).
+		Where("job_id = ? AND status = ?", record.JobID, JobStatusPendingApproval).
+		Select("status", "result", "executed_at").
+		Updates(&JobRecord{Status: JobStatusFailed, Result: "approval expired", ExecutedAt: now})
+	if res.Error != nil || res.RowsAffected != 1 {
+		return false, res.Error
+	}
+	record.Status, record.Result, record.ExecutedAt = JobStatusFailed, "approval expired", now
+	log.Printf("[Approval] 任务 %s 超过审批期限未获批准, 判失败", record.JobID)
+	return true, nil
+}
+
+// ApprovalExpirer 定期把审批超时的任务判失败, 只应在 leader 上运行
+func (s *SentinelServer) ApprovalExpirer(elector *cluster.Elector) cluster.LeaderTask {
+	return func(ctx context.Context, token int64) {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := elector.Check(ctx, token); err != nil {
+				log.Printf("[Approval] 租约校验失败, 跳过本轮 (token=%d): %v", token, err)
+				continue
+			}
+
+			var records []JobRecord
+			err := s.DB.WithContext(ctx).
+				Where("status = ? AND approval_expires_at < ?", JobStatusPendingApproval, time.Now()).
+				Limit(purgeBatchSize).Find(&records).Error
+			if err != nil {
+				log.Printf("[Approval] 查询审批超时任务失败: %v", err)
+				continue
+			}
+			for i := range records {
+				var expired bool
+				err := elector.Fenced(ctx, token, func(tx *gorm.DB) error {
+					var err error
+					expired, err = s.expireApproval(tx, &records[i])
+					return err
+				})
+				if errors.Is(err, cluster.ErrLeaseLost) {
+					log.Printf("[Approval] 租约已失效, 中止本轮 (token=%d)", token)
+					break
+				}
+				if expired {
+					s.emitJobEvent(events.TypeJobFailed, &records[i])
+				}
+			}
+		}
+	}
+}
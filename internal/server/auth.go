@@ -0,0 +1,299 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 角色按权限从低到高排列, 高的角色包含低的全部权限
+const (
+	RoleViewer   = "viewer"   // 只读
+	RoleOperator = "operator" // 下发 / 取消任务
+	RoleApprover = "approver" // 审批别人提交的敏感任务
+	RoleAdmin    = "admin"    // 配置、发布、审批规则、用户管理
+)
+
+var roleRank = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleApprover: 3, RoleAdmin: 4}
+
+// 启动时自动创建的管理员, 见 server.bootstrap_admin_token
+const bootstrapAdminName = "admin"
+
+var (
+	ErrInvalidUser      = errors.New("invalid user")
+	ErrUserExists       = errors.New("user already exists")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// RoleError 角色不够. Project 为空表示校验的是全局角色; Have 为空表示在项目里没有任何角色.
+type RoleError struct {
+	Project string
+	Role    string // 要求的最低角色
+	Have    string
+}
+
+func (e *RoleError) Error() string {
+	switch {
+	case e.Project == "":
+		return "requires " + e.Role + " role or above"
+	case e.Have == "":
+		return "no access to project " + e.Project
+	default:
+		return "requires " + e.Role + " role or above in project " + e.Project
+	}
+}
+
+func (e *RoleError) Unwrap() error { return ErrPermissionDenied }
+
+// User 管理 API 的调用方. 库里只存 token 的 sha256, 明文只在创建时返回一次.
+type User struct {
+	gorm.Model
+	Name      string `gorm:"uniqueIndex;size:191"`
+	Role      string `gorm:"size:32"`
+	TokenHash string `gorm:"uniqueIndex;size:64" json:"-"`
+}
+
+// HasRole 全局角色是否不低于 role. 项目内的角色见 ProjectMember, 全局 admin 在所有项目里都是 admin.
+func (u *User) HasRole(role string) bool {
+	return roleAtLeast(u.Role, role)
+}
+
+func roleAtLeast(have, want string) bool {
+	return roleRank[have] > 0 && roleRank[have] >= roleRank[want]
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken prefix 区分用途: snt_ 为用户 token, sne_ 为项目的 enrollment token, sna_ 为 Agent 的 credential
+func newToken(prefix string) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// CreateUser 返回新用户和明文 token
+func (s *SentinelServer) CreateUser(ctx context.Context, name, role string) (*User, string, error) {
+	if name == "" || len(name) > 191 {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidUser)
+	}
+	if roleRank[role] == 0 {
+		return nil, "", fmt.Errorf("%w: role must be one of viewer, operator, approver, admin", ErrInvalidUser)
+	}
+	token, err := newToken("snt_")
+	if err != nil {
+		return nil, "", err
+	}
+	u := &User{Name: name, Role: role, TokenHash: hashToken(token)}
+	if err := s.createUser(ctx, u); err != nil {
+		return nil, "", err
+	}
+	return u, token, nil
+}
+
+func (s *SentinelServer) createUser(ctx context.Context, u *User) error {
+	var n int64
+	if err := s.DB.WithContext(ctx).Model(&User{}).Where("name = ?", u.Name).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrUserExists
+	}
+	return s.DB.WithContext(ctx).Create(u).Error
+}
+
+func (s *SentinelServer) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	err := s.DB.WithContext(ctx).Order("name").Find(&users).Error
+	return users, err
+}
+
+// DeleteUser 同时退出所有项目
+func (s *SentinelServer) DeleteUser(ctx context.Context, name string) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("name = ?", name).Delete(&User{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return tx.Unscoped().Where("user_name = ?", name).Delete(&ProjectMember{}).Error
+	})
+}
+
+// Authenticate 按 token 查用户, 找不到返回 ErrUserNotFound
+func (s *SentinelServer) Authenticate(ctx context.Context, token string) (*User, error) {
+	if token == "" {
+		return nil, ErrUserNotFound
+	}
+	var u User
+	err := s.DB.WithContext(ctx).Where("token_hash = ?", hashToken(token)).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Authorize 校验全局角色, 用于不属于任何项目的操作 (用户、发布、审批规则等)
+func Authorize(u *User, role string) error {
+	if !u.HasRole(role) {
+		return &RoleError{Role: role, Have: u.Role}
+	}
+	return nil
+}
+
+// AuthorizeProject 校验 u 在 project (为空即 default) 里的角色不低于 role.
+// 返回限定在该项目里的 ctx, 之后的查询都只能看到这个项目, 见 WithProject.
+// 非 admin 看不出项目是否存在, 不存在的项目和没有角色的项目一样返回 RoleError; admin 才会得到 ErrProjectNotFound.
+func (s *SentinelServer) AuthorizeProject(ctx context.Context, u *User, project, role string) (context.Context, string, error) {
+	if project == "" {
+		project = DefaultProject
+	}
+	have, err := s.ProjectRole(ctx, u, project)
+	if err != nil {
+		return nil, "", err
+	}
+	if have == "" || !roleAtLeast(have, role) {
+		return nil, "", &RoleError{Project: project, Role: role, Have: have}
+	}
+	return WithProject(ctx, project), project, nil
+}
+
+// BootstrapAdmin 库里还没有任何用户时, 用配置的 token 创建管理员, 解决第一个用户从哪来的问题.
+// 已有用户时什么都不做, 所以上线后可以把配置删掉.
+func (s *SentinelServer) BootstrapAdmin(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	var n int64
+	if err := s.DB.WithContext(ctx).Model(&User{}).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	if err := s.createUser(ctx, &User{Name: bootstrapAdminName, Role: RoleAdmin, TokenHash: hashToken(token)}); err != nil {
+		return err
+	}
+	log.Printf("[Auth] 已用 server.bootstrap_admin_token 创建管理员 %q", bootstrapAdminName)
+	return nil
+}
+
+const (
+	currentUserKey    = "sentinel.user"
+	currentProjectKey = "sentinel.project"
+)
+
+// ProjectHeader 指定请求作用的项目, 缺省为 default 项目
+const ProjectHeader = "X-Sentinel-Project"
+
+// authenticate 校验 Authorization: Bearer <token>, 失败时已经写好响应
+func (h *HttpServer) authenticate(c *gin.Context) (*User, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		c.AbortWithStatusJSON(401, gin.H{"error": "缺少 Authorization: Bearer <token>"})
+		return nil, false
+	}
+	u, err := h.Srv.Authenticate(c.Request.Context(), strings.TrimSpace(token))
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		c.AbortWithStatusJSON(401, gin.H{"error": "token 无效"})
+		return nil, false
+	case err != nil:
+		c.AbortWithStatusJSON(500, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	c.Set(currentUserKey, u)
+	return u, true
+}
+
+// requireRole 按全局角色校验, 用于不属于任何项目的接口 (用户、发布、审批规则等), 角色不够返回 403
+func (h *HttpServer) requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, ok := h.authenticate(c)
+		if !ok {
+			return
+		}
+		if err := Authorize(u, role); err != nil {
+			roleDenied(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole 给共用同一个库的其他 HTTP 服务 (如扫描 API) 用, 用户和 token 与管理 API 相同, 按全局角色校验
+func RequireRole(db *gorm.DB, role string) gin.HandlerFunc {
+	h := &HttpServer{DB: db, Srv: &SentinelServer{DB: db}}
+	return h.requireRole(role)
+}
+
+// requireProjectRole 按用户在项目里的角色校验. 项目取路径参数 :project, 没有就取 X-Sentinel-Project 头, 都没有为 default.
+// 校验通过后项目写进 request context, 之后的查询都限定在这个项目里, 见 WithProject.
+func (h *HttpServer) requireProjectRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, ok := h.authenticate(c)
+		if !ok {
+			return
+		}
+		name := c.Param("project")
+		if name == "" {
+			name = c.GetHeader(ProjectHeader)
+		}
+		ctx, project, err := h.Srv.AuthorizeProject(c.Request.Context(), u, name, role)
+		if errors.Is(err, ErrProjectNotFound) {
+			c.AbortWithStatusJSON(404, gin.H{"error": "项目不存在: " + cmp.Or(name, DefaultProject)})
+			return
+		}
+		if err != nil {
+			roleDenied(c, err)
+			return
+		}
+		c.Set(currentProjectKey, project)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// roleDenied 把 Authorize / AuthorizeProject 的错误写成响应
+func roleDenied(c *gin.Context, err error) {
+	var re *RoleError
+	switch {
+	case errors.As(err, &re) && re.Project == "":
+		c.AbortWithStatusJSON(403, gin.H{"error": "需要 " + re.Role + " 及以上角色"})
+	case errors.As(err, &re) && re.Have == "":
+		c.AbortWithStatusJSON(403, gin.H{"error": "无权访问项目 " + re.Project})
+	case errors.As(err, &re):
+		c.AbortWithStatusJSON(403, gin.H{"error": "需要项目 " + re.Project + " 的 " + re.Role + " 及以上角色"})
+	default:
+		c.AbortWithStatusJSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// currentUser 只能在 requireRole / requireProjectRole 之后调用
+func currentUser(c *gin.Context) *User {
+	return c.MustGet(currentUserKey).(*User)
+}
+
+// currentProject 只能在 requireProjectRole 之后调用
+func currentProject(c *gin.Context) string {
+	return c.MustGet(currentProjectKey).(string)
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboard 管理面板的静态文件, 编进二进制, 不需要单独部署前端.
+// 页面本身不带任何数据, 登录后用 API token 调用同源的管理接口.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// mountDashboard 面板挂在 /ui/, 访问 / 时跳转过去. 静态文件不需要鉴权, 也不记审计.
+func mountDashboard(r *gin.Engine) {
+	sub, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err) // 目录是编译期确定的
+	}
+	r.StaticFS("/ui", http.FS(sub))
+	r.GET("/", func(c *gin.Context) {
+		c.Redirect(http.StatusFound, "/ui/")
+	})
+}
@@ -0,0 +1,386 @@
+// Sentinel 管理面板: 纯前端, 直接调用同源的管理 API.
+// token 只放在 sessionStorage, 关掉标签页就失效; 所有服务端数据都用 textContent 渲染.
+"use strict";
+
+const FINISHED = ["Success", "Failed", "Cancelled", "Rejected"];
+const state = { timer: null };
+
+const $ = (sel) => document.querySelector(sel);
+
+// el("td", {class: "mono"}, "text", child...) 创建元素, 字符串子节点一律当文本
+function el(tag, attrs, ...children) {
+  const n = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    if (k.startsWith("on")) n.addEventListener(k.slice(2), v);
+    else if (v !== undefined && v !== null && v !== false) n.setAttribute(k, v === true ? "" : v);
+  }
+  for (const c of children.flat()) {
+    if (c === null || c === undefined) continue;
+    n.append(c instanceof Node ? c : document.createTextNode(String(c)));
+  }
+  return n;
+}
+
+function token() { return sessionStorage.getItem("sentinel.token") || ""; }
+function project() { return sessionStorage.getItem("sentinel.project") || ""; }
+
+class APIError extends Error {
+  constructor(status, message) { super(message); this.status = status; }
+}
+
+// api 返回整个响应体; 非 2xx 抛 APIError, 401 回到登录页
+async function api(method, path, body) {
+  const headers = { Authorization: "Bearer " + token() };
+  if (project()) headers["X-Sentinel-Project"] = project();
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  const resp = await fetch(path, { method, headers, body: body === undefined ? undefined : JSON.stringify(body) });
+  let data = {};
+  try { data = await resp.json(); } catch (e) { /* 非 JSON 响应 */ }
+  if (!resp.ok) {
+    if (resp.status === 401) showLogin("token 无效或已过期");
+    let msg = data.error || resp.statusText;
+    if (data.retry_after_seconds) msg += " (" + data.retry_after_seconds + "s 后重试)";
+    throw new APIError(resp.status, msg);
+  }
+  return data;
+}
+
+function showError(err) { $("#error").textContent = err ? String(err.message || err) : ""; }
+
+function statusBadge(s) { return el("span", { class: "status " + s }, s || "-"); }
+
+function fmtTime(s) {
+  if (!s || s.startsWith("0001-")) return "-";
+  return new Date(s).toLocaleString();
+}
+
+function ago(s) {
+  if (!s || s.startsWith("0001-")) return "-";
+  const d = Math.max(0, (Date.now() - new Date(s).getTime()) / 1000);
+  if (d < 60) return Math.round(d) + "s ago";
+  if (d < 3600) return Math.round(d / 60) + "m ago";
+  if (d < 172800) return Math.round(d / 3600) + "h ago";
+  return Math.round(d / 86400) + "d ago";
+}
+
+function truncate(s, n) {
+  s = (s || "").replace(/\s+/g, " ").trim();
+  return s.length > n ? s.slice(0, n - 1) + "…" : s;
+}
+
+const SVG = "http://www.w3.org/2000/svg";
+
+// sparkline CPU / 内存两条折线, 纵轴固定 0-100%, 横轴是最近 windowMs
+function sparkline(points, windowMs) {
+  const w = 160, h = 32;
+  const svg = document.createElementNS(SVG, "svg");
+  svg.setAttribute("class", "spark");
+  svg.setAttribute("width", w);
+  svg.setAttribute("height", h);
+  svg.setAttribute("viewBox", `0 0 ${w} ${h}`);
+  const now = Date.now();
+  for (const key of ["cpu", "mem"]) {
+    const coords = points.map((p) => {
+      const x = w - ((now - new Date(p.t).getTime()) / windowMs) * w;
+      const y = h - (Math.min(100, Math.max(0, p[key])) / 100) * (h - 2) - 1;
+      return x.toFixed(1) + "," + y.toFixed(1);
+    });
+    const line = document.createElementNS(SVG, "polyline");
+    line.setAttribute("class", key);
+    line.setAttribute("points", coords.join(" "));
+    svg.append(line);
+  }
+  return svg;
+}
+
+function lastMetric(points) {
+  if (!points.length) return "-";
+  const p = points[points.length - 1];
+  return el("span", { class: "legend" },
+    el("span", { class: "cpu" }, "CPU " + p.cpu.toFixed(0) + "%"), " ",
+    el("span", { class: "mem" }, "MEM " + p.mem.toFixed(0) + "%"));
+}
+
+// ---- Agents ----
+
+async function viewAgents(view) {
+  const span = "1h";
+  const render = async () => {
+    const [agents, metrics] = await Promise.all([api("GET", "/agent"), api("GET", "/agent-metrics?window=" + span)]);
+    const rows = (agents.data || []).map((a) => {
+      const points = (metrics.data || {})[a.AgentID] || [];
+      return el("tr", {},
+        el("td", { class: "mono" }, el("a", { href: "#/jobs?agent=" + encodeURIComponent(a.AgentID) }, a.AgentID)),
+        el("td", {}, statusBadge(a.Status)),
+        el("td", {}, a.Hostname),
+        el("td", { class: "mono" }, a.IP),
+        el("td", {}, (a.Tags || "").replace(/^,|,$/g, "")),
+        el("td", {}, [a.Version, a.OS && a.OS + "/" + a.Arch].filter(Boolean).join(" ")),
+        el("td", { title: fmtTime(a.LastSeen) }, ago(a.LastSeen)),
+        el("td", {}, points.length ? sparkline(points, 3600e3) : el("span", { class: "muted" }, "no data")),
+        el("td", {}, lastMetric(points)));
+    });
+    view.replaceChildren(
+      el("div", { class: "toolbar" }, el("h2", {}, "Agents"), el("span", { class: "muted" }, `${rows.length} agents, metrics over last ${span}, refreshed every 15s`)),
+      el("table", {},
+        el("thead", {}, el("tr", {}, ["Agent", "Status", "Hostname", "IP", "Tags", "Version", "Last seen", "CPU / MEM", "Now"].map((h) => el("th", {}, h)))),
+        el("tbody", {}, rows)));
+  };
+  await render();
+  state.timer = setInterval(() => render().then(() => showError(null), showError), 15000);
+}
+
+// ---- Run ----
+
+function parseList(s) { return s.split(",").map((x) => x.trim()).filter(Boolean); }
+
+function viewRun(view) {
+  const target = el("input", { placeholder: "agent id", size: 30 });
+  const ids = el("input", { placeholder: "id1,id2", size: 30 });
+  const tags = el("input", { placeholder: "tag1,tag2", size: 30 });
+  const status = el("select", {}, el("option", { value: "" }, "any"), el("option", {}, "Online"), el("option", {}, "Offline"));
+  const all = el("input", { type: "checkbox" });
+  const type = el("select", {}, ["SHELL", "PING", "SCAN"].map((t) => el("option", {}, t)));
+  const cmd = el("textarea", { placeholder: "command / target", required: true });
+  const secrets = el("input", { placeholder: "ENV=secret-name,ENV2=other", size: 50 });
+  const preview = el("div");
+  const result = el("div");
+
+  const mode = { value: "target" };
+  const radio = (value, label) => el("label", {}, el("input", {
+    type: "radio", name: "mode", value, checked: value === "target",
+    onchange: () => { mode.value = value; targetBox.hidden = value !== "target"; selBox.hidden = value !== "selector"; },
+  }), " " + label);
+
+  const selector = () => ({ all: all.checked, agent_ids: parseList(ids.value), tags: parseList(tags.value), status: status.value });
+
+  const targetBox = el("div", {}, el("label", {}, "Agent ", target));
+  const selBox = el("div", { hidden: true },
+    el("label", {}, "IDs ", ids), el("label", {}, "Tags ", tags), el("label", {}, "Status ", status),
+    el("label", {}, all, " all agents"),
+    el("button", {
+      type: "button", onclick: async () => {
+        try {
+          const resp = await api("POST", "/agent/select", selector());
+          const agents = resp.data || [];
+          preview.replaceChildren(
+            el("p", {}, `${agents.length} agents match`),
+            el("ul", {}, agents.map((a) => el("li", {}, el("span", { class: "mono" }, a.AgentID), " ", statusBadge(a.Status)))));
+        } catch (e) { preview.replaceChildren(el("p", { class: "error" }, e.message)); }
+      },
+    }, "Preview"),
+    preview);
+
+  const submit = async (ev) => {
+    ev.preventDefault();
+    const req = { type: type.value, cmd: cmd.value };
+    if (mode.value === "target") req.target = target.value.trim();
+    else req.selector = selector();
+    const sec = {};
+    for (const pair of parseList(secrets.value)) {
+      const i = pair.indexOf("=");
+      if (i > 0) sec[pair.slice(0, i).trim()] = pair.slice(i + 1).trim();
+    }
+    if (Object.keys(sec).length) req.secrets = sec;
+    try {
+      const resp = await api("POST", "/job", req);
+      if (!req.selector) {
+        location.hash = "#/job/" + encodeURIComponent(resp.job);
+        return;
+      }
+      result.replaceChildren(el("table", {},
+        el("thead", {}, el("tr", {}, ["Job", "Agent", "Status"].map((h) => el("th", {}, h)))),
+        el("tbody", {}, (resp.jobs || []).map((j) => el("tr", {},
+          el("td", { class: "mono" }, el("a", { href: "#/job/" + encodeURIComponent(j.job) }, j.job)),
+          el("td", { class: "mono" }, j.agent),
+          el("td", {}, statusBadge(j.status)))))));
+    } catch (e) { result.replaceChildren(el("p", { class: "error" }, e.message)); }
+  };
+
+  view.replaceChildren(
+    el("h2", {}, "Run job"),
+    el("form", { onsubmit: submit },
+      el("fieldset", {}, el("legend", {}, "Target"), radio("target", "single agent"), radio("selector", "selector"), targetBox, selBox),
+      el("fieldset", {}, el("legend", {}, "Job"),
+        el("label", {}, "Type ", type), cmd, el("label", {}, "Secrets ", secrets)),
+      el("button", { type: "submit" }, "Submit")),
+    result);
+}
+
+// ---- History ----
+
+async function viewJobs(view, params) {
+  const filters = {
+    agent: el("input", { placeholder: "agent", value: params.get("agent") || "" }),
+    status: el("input", { placeholder: "Failed,Success", value: params.get("status") || "" }),
+    type: el("input", { placeholder: "SHELL", value: params.get("type") || "", size: 8 }),
+    submitted_by: el("input", { placeholder: "user", value: params.get("submitted_by") || "" }),
+    q: el("input", { placeholder: "output contains", value: params.get("q") || "" }),
+  };
+  const tbody = el("tbody");
+  const more = el("button", { type: "button", hidden: true }, "Load more");
+  let cursor = "";
+
+  const load = async () => {
+    const q = new URLSearchParams();
+    for (const [k, input] of Object.entries(filters)) if (input.value.trim()) q.set(k, input.value.trim());
+    if (cursor) q.set("cursor", cursor);
+    const resp = await api("GET", "/jobs?" + q.toString());
+    for (const j of resp.data || []) {
+      tbody.append(el("tr", {},
+        el("td", { class: "mono" }, el("a", { href: "#/job/" + encodeURIComponent(j.job_id) }, j.job_id)),
+        el("td", { class: "mono" }, j.agent_id),
+        el("td", {}, j.type),
+        el("td", {}, statusBadge(j.status)),
+        el("td", {}, j.submitted_by),
+        el("td", {}, fmtTime(j.created_at)),
+        el("td", { class: "mono", title: j.payload }, truncate(j.payload, 60))));
+    }
+    cursor = resp.next_cursor || "";
+    more.hidden = !cursor;
+  };
+  more.addEventListener("click", () => load().catch(showError));
+
+  const apply = (ev) => {
+    ev.preventDefault();
+    const q = new URLSearchParams();
+    for (const [k, input] of Object.entries(filters)) if (input.value.trim()) q.set(k, input.value.trim());
+    location.hash = "#/jobs" + (q.toString() ? "?" + q.toString() : "");
+  };
+
+  view.replaceChildren(
+    el("h2", {}, "Job history"),
+    el("form", { class: "toolbar", onsubmit: apply }, Object.values(filters), el("button", { type: "submit" }, "Filter")),
+    el("table", {},
+      el("thead", {}, el("tr", {}, ["Job", "Agent", "Type", "Status", "Submitted by", "Created", "Command"].map((h) => el("th", {}, h)))),
+      tbody),
+    el("div", { class: "toolbar" }, more));
+  await load();
+}
+
+// ---- Job detail ----
+
+async function viewJob(view, id) {
+  const meta = el("table");
+  const output = el("pre", {}, "");
+  const cancel = el("button", { type: "button", hidden: true }, "Cancel");
+  cancel.addEventListener("click", async () => {
+    if (!confirm("Cancel job " + id + "?")) return;
+    try { await api("POST", "/job/" + encodeURIComponent(id) + "/cancel"); } catch (e) { showError(e); }
+  });
+  view.replaceChildren(el("div", { class: "toolbar" }, el("h2", {}, "Job ", el("code", {}, id)), cancel), meta, el("h3", {}, "Output"), output);
+
+  const render = async () => {
+    const j = (await api("GET", "/jobs/" + encodeURIComponent(id))).data;
+    const row = (k, v) => el("tr", {}, el("th", {}, k), el("td", {}, v));
+    meta.replaceChildren(
+      row("Agent", el("a", { href: "#/jobs?agent=" + encodeURIComponent(j.agent_id) }, j.agent_id)),
+      row("Project", j.project),
+      row("Type", j.type),
+      row("Status", statusBadge(j.status)),
+      row("Submitted by", j.submitted_by || "-"),
+      j.approved_by ? row("Approved by", j.approved_by) : null,
+      row("Created", fmtTime(j.created_at)),
+      row("Executed", fmtTime(j.executed_at)),
+      row("Command", el("code", {}, j.payload)));
+    const finished = FINISHED.includes(j.status);
+    // 结束后换成完整输出, 以它为准; 之前显示 Agent 增量上报的输出
+    if (finished) output.textContent = j.result || "(no output)";
+    else if (!after) output.textContent = "waiting for agent… (" + j.status + ")";
+    cancel.hidden = finished;
+    return finished;
+  };
+
+  // 按 seq 追加执行中的输出, 停在底部时跟着滚动; 不支持增量上报的旧 Agent 没有分片
+  let after = 0;
+  const tail = async () => {
+    const page = (await api("GET", "/jobs/" + encodeURIComponent(id) + "/output?after=" + after)).data;
+    if (!page.chunks.length) return;
+    const atBottom = output.scrollTop + output.clientHeight >= output.scrollHeight - 4;
+    if (!after) output.textContent = "";
+    for (const c of page.chunks) {
+      output.append(c.data);
+      after = c.seq;
+    }
+    if (atBottom) output.scrollTop = output.scrollHeight;
+  };
+
+  const tick = async () => {
+    if (await render()) return true;
+    await tail();
+    return false;
+  };
+  if (await tick()) return;
+  state.timer = setInterval(() => tick().then((finished) => finished && stopTimer(), showError), 1000);
+}
+
+// ---- 框架: 登录 / 项目 / 路由 ----
+
+function showLogin(msg) {
+  sessionStorage.removeItem("sentinel.token");
+  stopTimer();
+  $("#login").hidden = false;
+  $("#view").hidden = true;
+  $("header").hidden = true;
+  $("#login-error").textContent = msg || "";
+}
+
+async function loadProjects() {
+  const resp = await api("GET", "/project");
+  const sel = $("#project");
+  const names = (resp.data || []).map((p) => p.name);
+  if (!project() || !names.includes(project())) sessionStorage.setItem("sentinel.project", names.includes("default") ? "default" : names[0] || "");
+  sel.replaceChildren(...names.map((n) => el("option", { value: n, selected: n === project() }, n)));
+}
+
+function stopTimer() {
+  if (state.timer) clearInterval(state.timer);
+  state.timer = null;
+}
+
+async function route() {
+  stopTimer();
+  showError(null);
+  if (!token()) return showLogin();
+  const [path, query] = (location.hash.slice(1) || "/agents").split("?");
+  const params = new URLSearchParams(query || "");
+  for (const a of document.querySelectorAll("header nav a")) {
+    a.classList.toggle("active", path.startsWith(a.getAttribute("href").slice(1)));
+  }
+  const view = $("#view");
+  try {
+    if (path === "/agents") await viewAgents(view);
+    else if (path === "/run") viewRun(view);
+    else if (path === "/jobs") await viewJobs(view, params);
+    else if (path.startsWith("/job/")) await viewJob(view, decodeURIComponent(path.slice(5)));
+    else location.hash = "#/agents";
+  } catch (e) { showError(e); }
+}
+
+async function start() {
+  $("#login").hidden = true;
+  $("#view").hidden = false;
+  $("header").hidden = false;
+  try {
+    await loadProjects();
+  } catch (e) {
+    if (e.status !== 401) showError(e);
+    return;
+  }
+  await route();
+}
+
+$("#login-form").addEventListener("submit", (ev) => {
+  ev.preventDefault();
+  sessionStorage.setItem("sentinel.token", $("#token").value.trim());
+  $("#token").value = "";
+  start();
+});
+$("#logout").addEventListener("click", () => showLogin());
+$("#project").addEventListener("change", (ev) => {
+  sessionStorage.setItem("sentinel.project", ev.target.value);
+  route();
+});
+window.addEventListener("hashchange", route);
+
+if (token()) start(); else showLogin();
@@ -0,0 +1,439 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/cluster"
+	"github.com/stywzn/Go-Cloud-Compute/internal/events"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/jobsign"
+	"gorm.io/gorm"
+)
+
+// 心跳流每隔多久从库里补一次排队任务
+const queueResyncInterval = 30 * time.Second
+
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobFinished       = errors.New("job already finished")
+	ErrInvalidJobRequest = errors.New("invalid job request")
+)
+
+// JobQueue 本实例内存里的信箱, 只缓存心跳流挂在本实例上的 Agent 的待派发任务和取消指令.
+// 任务的持久状态以 JobRecord 为准, 信箱丢了可以从库里重建.
+type JobQueue struct {
+	mu      sync.Mutex
+	jobs    map[string][]*pb.Job
+	cancels map[string][]string
+	refresh map[string]bool
+}
+
+func NewJobQueue() *JobQueue {
+	return &JobQueue{
+		jobs:    make(map[string][]*pb.Job),
+		cancels: make(map[string][]string),
+		refresh: make(map[string]bool),
+	}
+}
+
+// Push 同一个 JobID 只保留一份
+func (q *JobQueue) Push(agentID string, job *pb.Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs[agentID] {
+		if j.JobId == job.JobId {
+			return
+		}
+	}
+	q.jobs[agentID] = append(q.jobs[agentID], job)
+}
+
+func (q *JobQueue) PushCancel(agentID, jobID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.cancels[agentID] = append(q.cancels[agentID], jobID)
+}
+
+// PushRefresh 标记某个 Agent 的远程配置 / 目标版本需要重新计算
+func (q *JobQueue) PushRefresh(agentID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.refresh[agentID] = true
+}
+
+// TakeRefresh 返回并清除重新计算标记
+func (q *JobQueue) TakeRefresh(agentID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	changed := q.refresh[agentID]
+	delete(q.refresh, agentID)
+	return changed
+}
+
+// Drain 取出并清空某个 Agent 的全部待派发任务和取消指令
+func (q *JobQueue) Drain(agentID string) ([]*pb.Job, []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs, cancels := q.jobs[agentID], q.cancels[agentID]
+	delete(q.jobs, agentID)
+	delete(q.cancels, agentID)
+	return jobs, cancels
+}
+
+func newJobID(agentID string) string {
+	return fmt.Sprintf("manual-%s-%d", agentID, time.Now().UnixNano())
+}
+
+// recordToJob 密钥只带 env / name, 明文在派发前由 resolveSecrets 填入
+func recordToJob(rec *JobRecord) *pb.Job {
+	job := &pb.Job{
+		JobId:     rec.JobID,
+		Type:      pb.JobType(pb.JobType_value[rec.Type]),
+		Payload:   rec.Payload,
+		AgentId:   rec.AgentID,
+		Signature: rec.Signature,
+	}
+	for _, ref := range rec.Secrets {
+		job.Secrets = append(job.Secrets, &pb.SecretEnv{Env: ref.Env, Name: ref.Name})
+	}
+	if rec.ExpiresAt != nil {
+		job.ExpiresAt = rec.ExpiresAt.Unix()
+	}
+	return job
+}
+
+func jobExpired(job *pb.Job) bool {
+	return job.ExpiresAt > 0 && time.Now().Unix() > job.ExpiresAt
+}
+
+// JobSpec 提交任务的参数
+type JobSpec struct {
+	AgentID string
+	Type    pb.JobType
+	Payload string
+	Secrets []SecretRef // 按 Env 排序, 签名覆盖它们的顺序
+}
+
+// JobRequest HTTP / gRPC 管理接口提交任务的参数, 见 Submit
+type JobRequest struct {
+	TargetAgent string `json:"target"`
+	// Selector 给命中的每个 Agent 各下发一个任务 (fan-out), 和 target 二选一
+	Selector *Selector `json:"selector"`
+	Type     string    `json:"type"` // PING / SCAN / SHELL, 为空默认 PING
+	Cmd      string    `json:"cmd"`
+	// Secrets 环境变量名 -> 密钥名, 例如 {"PGPASSWORD": "prod-db-password"}
+	Secrets map[string]string `json:"secrets"`
+}
+
+// Submit 按 target 或 selector 提交, 返回的任务按 Agent ID 排序; 只有 target 时只有一个
+func (s *SentinelServer) Submit(ctx context.Context, submitter string, req JobRequest) ([]*JobRecord, error) {
+	jobType := pb.JobType_PING
+	if req.Type != "" {
+		v, ok := pb.JobType_value[strings.ToUpper(req.Type)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown job type %q", ErrInvalidJobRequest, req.Type)
+		}
+		jobType = pb.JobType(v)
+	}
+	spec := JobSpec{AgentID: req.TargetAgent, Type: jobType, Payload: req.Cmd}
+	for env, name := range req.Secrets {
+		spec.Secrets = append(spec.Secrets, SecretRef{Env: env, Name: name})
+	}
+
+	if req.Selector == nil {
+		record, err := s.SubmitJob(ctx, submitter, spec)
+		if err != nil {
+			return nil, err
+		}
+		return []*JobRecord{record}, nil
+	}
+	if req.TargetAgent != "" {
+		return nil, fmt.Errorf("%w: target and selector are mutually exclusive", ErrInvalidJobRequest)
+	}
+	return s.SubmitFanOut(ctx, submitter, *req.Selector, spec)
+}
+
+// SubmitJob 任务先以 Queued 状态落库, 再通知持有该 Agent 心跳流的实例去取.
+// 命中审批规则的任务以 PendingApproval 落库, 等另一个用户批准后才进入队列, 见 ApproveJob.
+// 目标 Agent 必须已经注册在 ctx 限定的项目里, 任务归属同一个项目.
+func (s *SentinelServer) SubmitJob(ctx context.Context, submitter string, spec JobSpec) (*JobRecord, error) {
+	agent, err := s.GetAgent(ctx, spec.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.submitJobs(ctx, submitter, []AgentModel{*agent}, spec)
+	if err != nil {
+		return nil, err
+	}
+	return records[0], nil
+}
+
+// SubmitFanOut 给 selector 命中的每个 Agent 各提交一个任务, spec.AgentID 不用.
+// 配额按命中的 Agent 数一次性占用, 任何一个 Agent 校验不过就一个都不提交.
+func (s *SentinelServer) SubmitFanOut(ctx context.Context, submitter string, sel Selector, spec JobSpec) ([]*JobRecord, error) {
+	agents, err := s.SelectAgents(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, ErrNoAgentsMatched
+	}
+	return s.submitJobs(ctx, submitter, agents, spec)
+}
+
+func (s *SentinelServer) submitJobs(ctx context.Context, submitter string, agents []AgentModel, spec JobSpec) ([]*JobRecord, error) {
+	secrets := slices.SortedFunc(slices.Values(spec.Secrets), func(a, b SecretRef) int { return strings.Compare(a.Env, b.Env) })
+	perProject := make(map[string]int)
+	for i := range agents {
+		if err := s.checkSecretRefs(ctx, submitter, &agents[i], secrets); err != nil {
+			return nil, err
+		}
+		perProject[agents[i].Project]++
+	}
+
+	records := make([]*JobRecord, len(agents))
+	for i := range agents {
+		record, err := s.newJobRecord(ctx, submitter, &agents[i], spec.Type, spec.Payload, secrets)
+		if err != nil {
+			return nil, err
+		}
+		records[i] = record
+	}
+	// 占配额和写入在同一个事务里, 要么全部入队要么一个都不入队
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 不带项目的 ctx 里 selector 可能跨项目, 每个项目各自占名额; 先写入的项目计入用户在后面项目的并发数
+		for _, project := range slices.Sorted(maps.Keys(perProject)) {
+			if err := s.reserveQuota(ctx, tx, submitter, project, perProject[project]); err != nil {
+				return err
+			}
+			for _, record := range records {
+				if record.Project != project {
+					continue
+				}
+				if err := tx.Create(record).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		s.announceJob(ctx, record)
+	}
+	return records, nil
+}
+
+// newJobRecord 生成待写入的任务: 命中审批规则的以 PendingApproval 等待审批, 否则签名后以 Queued 入队
+func (s *SentinelServer) newJobRecord(ctx context.Context, submitter string, agent *AgentModel, jobType pb.JobType, payload string, secrets []SecretRef) (*JobRecord, error) {
+	record := &JobRecord{
+		JobID:       newJobID(agent.AgentID),
+		AgentID:     agent.AgentID,
+		Project:     agent.Project,
+		Type:        jobType.String(),
+		Payload:     payload,
+		Status:      JobStatusQueued,
+		SubmittedBy: submitter,
+		Secrets:     secrets,
+	}
+	rule, err := s.matchApprovalRule(ctx, record, agent)
+	if err != nil {
+		return nil, err
+	}
+	if rule != nil {
+		expiresAt := time.Now().Add(s.approvalTTL(rule))
+		record.Status = JobStatusPendingApproval
+		record.ApprovalRule = rule.Name
+		record.ApprovalExpiresAt = &expiresAt
+	} else {
+		s.sealJob(record)
+	}
+	return record, nil
+}
+
+// announceJob 任务提交之后发事件, Queued 的通知持有心跳流的实例去取
+func (s *SentinelServer) announceJob(ctx context.Context, record *JobRecord) {
+	if record.Status == JobStatusPendingApproval {
+		log.Printf("[Approval] 任务 %s 命中审批规则 %q, 等待审批", record.JobID, record.ApprovalRule)
+		s.emitJobEvent(events.TypeJobPendingApproval, record)
+		return
+	}
+	s.emitJobEvent(events.TypeJobQueued, record)
+	s.route(ctx, cluster.Message{Kind: cluster.KindPush, AgentID: record.AgentID, JobID: record.JobID})
+}
+
+// sealJob 写入过期时间和签名. 任务进入 Queued 时调用, 需要审批的任务在批准时才签名,
+// 有效期从批准时算起, 等待审批的时间不占用它.
+func (s *SentinelServer) sealJob(record *JobRecord) {
+	if s.JobTTL > 0 {
+		expiresAt := time.Now().Add(s.JobTTL).Truncate(time.Second)
+		record.ExpiresAt = &expiresAt
+	}
+	if s.SigningKey != nil {
+		job := recordToJob(record)
+		jobsign.Sign(s.SigningKey, job)
+		record.Signature = job.Signature
+	}
+}
+
+// CancelJob 还没派发 (包括等待审批) 的直接标记取消; 已经派发的转给持有实例, 由 Agent 杀掉进程后汇报 Cancelled
+func (s *SentinelServer) CancelJob(ctx context.Context, jobID string) (*JobRecord, error) {
+	var record JobRecord
+	if err := s.DB.WithContext(ctx).Where("job_id = ?", jobID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	// 条件更新, 和心跳派发的 Queued -> Dispatched 以及审批互斥
+	res := s.DB.WithContext(ctx).Model(&JobRecord{}).
+		Where("job_id = ? AND status IN ?", jobID, []string{JobStatusQueued, JobStatusPendingApproval}).
+		Update("status", JobStatusCancelled)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 1 {
+		record.Status = JobStatusCancelled
+		s.emitJobEvent(events.TypeJobCancelled, &record)
+		return &record, nil
+	}
+
+	if err := s.DB.WithContext(ctx).Where("job_id = ?", jobID).First(&record).Error; err != nil {
+		return nil, err
+	}
+	if record.Status != JobStatusDispatched && record.Status != JobStatusCancelling {
+		return &record, ErrJobFinished
+	}
+
+	s.DB.WithContext(ctx).Model(&JobRecord{}).
+		Where("job_id = ? AND status = ?", jobID, JobStatusDispatched).
+		Update("status", JobStatusCancelling)
+	record.Status = JobStatusCancelling
+	s.route(ctx, cluster.Message{Kind: cluster.KindCancel, AgentID: record.AgentID, JobID: jobID})
+	return &record, nil
+}
+
+// route 找到 Agent 所在实例并投递消息. Agent 不在线时什么都不做, 任务留在库里等它连上.
+func (s *SentinelServer) route(ctx context.Context, msg cluster.Message) {
+	owner, err := s.Node.Registry.Owner(ctx, msg.AgentID)
+	if err != nil {
+		log.Printf("[Cluster] 查询 %s 归属失败: %v", msg.AgentID, err)
+		return
+	}
+	switch owner {
+	case "":
+		log.Printf("[Cluster] Agent %s 当前不在线, %s 指令等待其重连", msg.AgentID, msg.Kind)
+	case s.Node.ID:
+		s.handleClusterMessage(msg)
+	default:
+		if err := s.Node.Bus.Send(ctx, owner, msg); err != nil {
+			log.Printf("[Cluster] 转发 %s 指令到实例 %s 失败: %v", msg.Kind, owner, err)
+			return
+		}
+		log.Printf("[Cluster] %s 指令已转发到实例 %s (Agent: %s, Job: %s)", msg.Kind, owner, msg.AgentID, msg.JobID)
+	}
+}
+
+// ListenCluster 接收其他实例转发过来的指令, 阻塞直到 ctx 结束
+func (s *SentinelServer) ListenCluster(ctx context.Context) error {
+	return s.Node.Bus.Listen(ctx, s.handleClusterMessage)
+}
+
+func (s *SentinelServer) handleClusterMessage(msg cluster.Message) {
+	switch msg.Kind {
+	case cluster.KindPush:
+		var record JobRecord
+		err := s.DB.Where("job_id = ? AND status = ?", msg.JobID, JobStatusQueued).First(&record).Error
+		if err != nil {
+			log.Printf("[Cluster] 任务 %s 已不在排队状态, 忽略: %v", msg.JobID, err)
+			return
+		}
+		s.JobQueue.Push(record.AgentID, recordToJob(&record))
+	case cluster.KindCancel:
+		s.JobQueue.PushCancel(msg.AgentID, msg.JobID)
+	case cluster.KindRefresh:
+		s.JobQueue.PushRefresh(msg.AgentID)
+	default:
+		log.Printf("[Cluster] 未知指令类型: %s", msg.Kind)
+	}
+}
+
+func (s *SentinelServer) loadQueuedJobs(agentID string) {
+	var records []JobRecord
+	err := s.DB.Where("agent_id = ? AND status = ?", agentID, JobStatusQueued).
+		Order("id").Find(&records).Error
+	if err != nil {
+		log.Printf("[DB] 加载 %s 的排队任务失败: %v", agentID, err)
+		return
+	}
+	for i := range records {
+		s.JobQueue.Push(agentID, recordToJob(&records[i]))
+	}
+}
+
+// loadAgent 还没注册过的 Agent 返回只有 AgentID 的记录
+func (s *SentinelServer) loadAgent(ctx context.Context, agentID string) (*AgentModel, error) {
+	agent := AgentModel{AgentID: agentID}
+	err := s.DB.WithContext(ctx).Where("agent_id = ?", agentID).First(&agent).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// expireQueuedJob 签名已经过期的任务派发出去也会被 Agent 拒绝, 直接判失败
+func (s *SentinelServer) expireQueuedJob(job *pb.Job) {
+	s.failQueuedJob(job, "job expired before it could be dispatched")
+}
+
+// failQueuedJob 派发前就确定执行不了的任务 (过期、密钥不可用) 直接判失败
+func (s *SentinelServer) failQueuedJob(job *pb.Job, reason string) {
+	res := s.DB.Model(&JobRecord{}).
+		Where("job_id = ? AND status = ?", job.JobId, JobStatusQueued).
+		Select("status", "result", "executed_at").
+		Updates(&JobRecord{Status: JobStatusFailed, Result: reason, ExecutedAt: time.Now()})
+	if res.Error != nil || res.RowsAffected != 1 {
+		return
+	}
+	log.Printf("[Dispatch] 任务 %s 无法派发: %s", job.JobId, reason)
+	var record JobRecord
+	if err := s.DB.Where("job_id = ?", job.JobId).First(&record).Error; err == nil {
+		s.emitJobEvent(events.TypeJobFailed, &record)
+	}
+}
+
+// markDispatched Queued -> Dispatched 的条件更新, 保证同一个任务在整个集群里只派发一次.
+// project 为 Agent 当前所在项目, 别的项目的任务即使进了信箱也不会派发. sealed 为注入的密钥快照, 一起落库.
+func (s *SentinelServer) markDispatched(agentID, project string, job *pb.Job, sealed []SealedSecret) bool {
+	now := time.Now()
+	res := s.DB.Model(&JobRecord{}).
+		Where("job_id = ? AND status = ? AND project = ?", job.JobId, JobStatusQueued, project).
+		Select("status", "dispatched_at", "dispatched_secrets").
+		Updates(&JobRecord{Status: JobStatusDispatched, DispatchedAt: &now, DispatchedSecrets: sealed})
+	if res.Error != nil {
+		log.Printf("[DB] 标记任务 %s 已派发失败: %v", job.JobId, res.Error)
+		return false
+	}
+	if res.RowsAffected != 1 {
+		return false
+	}
+	s.Events.Emit(events.TypeJobDispatched, events.JobEvent{
+		JobID:   job.JobId,
+		AgentID: agentID,
+		Project: project,
+		JobType: job.Type.String(),
+		Status:  JobStatusDispatched,
+	})
+	return true
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/envelope"
+	"gorm.io/gorm"
+)
+
+const encryptBatchSize = 500
+
+// EncryptLegacyJobs 把启用加密之前写入的明文 payload / result 加密, 返回处理的行数. 可以重复执行.
+// 必须在 envelope.Use 之后调用.
+func EncryptLegacyJobs(ctx context.Context, db *gorm.DB) (int, error) {
+	type rawJob struct {
+		ID      uint
+		Payload string
+		Result  string
+	}
+	var lastID uint
+	total := 0
+	for {
+		// 直接读原始列, 绕开 serializer 才能分辨哪些还是明文
+		var rows []rawJob
+		err := db.WithContext(ctx).Table("job_records").Select("id, payload, result").
+			Where("id > ?", lastID).Order("id").Limit(encryptBatchSize).Scan(&rows).Error
+		if err != nil {
+			return total, err
+		}
+		for _, r := range rows {
+			lastID = r.ID
+			if (r.Payload == "" || envelope.IsSealed(r.Payload)) && (r.Result == "" || envelope.IsSealed(r.Result)) {
+				continue
+			}
+			// 条件里带上原值, 期间被汇报结果改写过的行留给下一次执行
+			res := db.WithContext(ctx).Model(&JobRecord{}).Unscoped().
+				Where("id = ? AND payload = ? AND result = ?", r.ID, r.Payload, r.Result).
+				Select("payload", "result").
+				Updates(&JobRecord{Payload: r.Payload, Result: r.Result})
+			if res.Error != nil {
+				return total, res.Error
+			}
+			total += int(res.RowsAffected)
+		}
+		if len(rows) < encryptBatchSize {
+			return total, nil
+		}
+	}
+}
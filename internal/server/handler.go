@@ -2,37 +2,136 @@ package server
 
 import (
 	"context"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"errors"
 	"log"
-	"sync"
 	"time"
 
 	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/audit"
+	"github.com/stywzn/Go-Cloud-Compute/internal/cluster"
+	"github.com/stywzn/Go-Cloud-Compute/internal/events"
+	"github.com/stywzn/Go-Cloud-Compute/internal/redact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
 )
 
+const (
+	AgentStatusOnline  = "Online"
+	AgentStatusOffline = "Offline"
+)
+
+// 心跳流里 LastSeen 的落库频率, 不必每次心跳都写库
+const lastSeenWriteInterval = 15 * time.Second
+
+const (
+	JobStatusPendingApproval = "PendingApproval" // 命中审批规则, 等待另一个用户批准
+	JobStatusQueued          = "Queued"
+	JobStatusDispatched      = "Dispatched"
+	JobStatusCancelling      = "Cancelling"
+	JobStatusCancelled       = "Cancelled"
+	JobStatusSuccess         = "Success"
+	JobStatusFailed          = "Failed"
+	JobStatusRejected        = "Rejected" // Agent 本地执行策略拒绝执行
+)
+
 type AgentModel struct {
 	gorm.Model
 	AgentID  string `gorm:"uniqueIndex;size:191"`
+	Project  string `gorm:"index;size:64"` // 注册时凭 enrollment token 确定, 见 enrollmentProject
 	Hostname string
 	IP       string
-	Status   string
+	Status   string `gorm:"index;size:32"`
+	Tags     string // ",a,b," 形式, 见 joinTags
+	LastSeen time.Time
+
+	Version       string `gorm:"size:64"`
+	OS            string `gorm:"size:32"`
+	Arch          string `gorm:"size:32"`
+	TargetVersion string `gorm:"size:64"` // 管理员安排的升级目标, 升级完成或失败回滚后清空
+	UpdateError   string // 最近一次自更新失败的原因
+
+	CredentialHash string `gorm:"size:64" json:"-"` // 首次注册时签发的 credential 的 sha256, 见 admitAgent
 }
 
 type JobRecord struct {
 	gorm.Model
-	JobID      string `gorm:"uniqueIndex;size:191"`
-	AgentID    string `gorm:"index;size:191"`
-	Type       string
-	Result     string
-	Payload    string
-	Status     string
-	ExecutedAt time.Time
+	JobID        string `gorm:"uniqueIndex;size:191"`
+	AgentID      string `gorm:"index;size:191"`
+	Project      string `gorm:"index;size:64"` // 和目标 Agent 所在项目一致
+	Type         string
+	Result       string `gorm:"serializer:envelope"` // 配置了 KEK 时加密存储, 见 internal/envelope
+	Payload      string `gorm:"serializer:envelope"`
+	Status       string `gorm:"index;size:32"`
+	DispatchedAt *time.Time
+	ExecutedAt   time.Time
+	ExpiresAt    *time.Time // 签名里的过期时间, 过期还没派发的任务直接判失败
+	Signature    []byte     `gorm:"type:varbinary(64)" json:"-"`
+
+	SubmittedBy       string     `gorm:"size:191"`
+	ApprovalRule      string     `gorm:"size:191"` // 命中的审批规则, 为空表示无需审批
+	ApprovalExpiresAt *time.Time `gorm:"index"`    // 超过这个时间还没批准就判失败
+	ApprovedBy        string     `gorm:"size:191"`
+	ApprovedAt        *time.Time
+
+	Secrets []SecretRef `gorm:"serializer:json"` // 只记录引用, 明文不落库
+	// DispatchedSecrets 派发时密钥的密文快照, 汇报时按它抹掉输出里的密钥
+	DispatchedSecrets []SealedSecret `gorm:"serializer:json" json:"-"`
 }
 
 type SentinelServer struct {
 	pb.UnimplementedSentinelServiceServer
 	DB       *gorm.DB
-	JobQueue sync.Map
+	Node     *cluster.Node
+	JobQueue *JobQueue
+	Events   *events.Emitter
+	Audit    *audit.Log
+
+	// ReleaseDir Agent 二进制的存放目录
+	ReleaseDir string
+
+	// SigningKey 为空时任务不签名, 配置了公钥的 Agent 会拒绝执行
+	SigningKey ed25519.PrivateKey
+	JobTTL     time.Duration
+
+	// ApprovalTTL 审批规则没有单独配置时, 等待审批的最长时间
+	ApprovalTTL time.Duration
+
+	// SecretsKey 为空时不能保存和引用密钥
+	SecretsKey cipher.AEAD
+
+	// Redactor 任务输出落库和打日志之前的脱敏规则
+	Redactor *redact.Pipeline
+
+	// OwnerTTL Agent 归属登记的过期时间, 单实例模式为 0. 远程配置的心跳间隔受它限制, 见 maxHeartbeatInterval
+	OwnerTTL time.Duration
+
+	// RequireEnrollment 为 true 时新 Agent 必须带项目的 enrollment token 注册, 否则归入 default 项目
+	RequireEnrollment bool
+
+	// UserQuota / ProjectQuota 提交任务的默认配额, 个别用户 / 项目的调整见 QuotaModel
+	UserQuota    QuotaLimits
+	ProjectQuota QuotaLimits
+	// RateLimiter 每分钟任务数的计数, 多实例部署时必须换成共享的实现
+	RateLimiter RateLimiter
+}
+
+// 只含内置检测器, 不会出错
+var defaultRedactor, _ = redact.NewPipeline(nil, nil)
+
+func NewSentinelServer(db *gorm.DB, node *cluster.Node) *SentinelServer {
+	return &SentinelServer{
+		DB:       db,
+		Node:     node,
+		JobQueue: NewJobQueue(),
+		Events:   events.NewEmitter(events.NopPublisher{}, node.ID),
+		Audit:    audit.New(db),
+		Redactor: defaultRedactor,
+
+		RateLimiter: newLocalRateLimiter(),
+	}
 }
 
 func (s *SentinelServer) Register(ctx context.Context, req *pb.RegisterReq) (*pb.RegisterResp, error) {
@@ -41,31 +140,113 @@ func (s *SentinelServer) Register(ctx context.Context, req *pb.RegisterReq) (*pb
 	log.Printf(" [Register] 收到注册请求: %s (%s)", req.Hostname, req.Ip)
 
 	var agent AgentModel
-	result := s.DB.Where("agent_id = ?", agentID).First(&agent)
+	err := s.DB.Where("agent_id = ?", agentID).First(&agent).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	known := err == nil
+
+	// 已有 credential 的 Agent 必须出示它, 之后才谈得上换项目; 新 Agent 和还没有 credential 的 Agent 在这里领取
+	project, credential, err := s.admitAgent(ctx, &agent, known, req)
+	if err != nil {
+		log.Printf(" [Register] 拒绝 %s 注册: %v", agentID, err)
+		s.auditRegister(ctx, agentID, req, "denied: "+err.Error())
+		switch {
+		case errors.Is(err, ErrAgentCredential):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, ErrEnrollmentRequired) || errors.Is(err, ErrInvalidEnrollment) || errors.Is(err, ErrAlreadyEnrolled):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
 
-	if result.Error != nil {
+	if !known {
 		newAgent := AgentModel{
-			AgentID:  agentID,
-			Hostname: req.Hostname,
-			IP:       req.Ip,
-			Status:   "online",
+			AgentID:        agentID,
+			Project:        project,
+			Hostname:       req.Hostname,
+			IP:             req.Ip,
+			Status:         AgentStatusOnline,
+			Tags:           joinTags(req.Tags),
+			LastSeen:       time.Now(),
+			Version:        req.Version,
+			OS:             req.Os,
+			Arch:           req.Arch,
+			CredentialHash: agent.CredentialHash,
 		}
-		s.DB.Create(&newAgent)
-		log.Println(" [DB] 新节点已入库")
+		// 同名 Agent 同时注册时只有一个能建成, 另一个拿不到 credential
+		if err := s.DB.Create(&newAgent).Error; err != nil {
+			log.Printf(" [DB] 新节点入库失败: %v", err)
+			return nil, status.Error(codes.Aborted, "agent registration conflict, retry")
+		}
+		log.Printf(" [DB] 新节点已入库, 项目: %s", project)
 	} else {
-		agent.Status = "Online"
+		if agent.Project != project {
+			log.Printf(" [Project] %s 从项目 %s 迁入 %s", agentID, agent.Project, project)
+			s.abandonProjectJobs(ctx, agentID, agent.Project, "agent moved to project "+project)
+			agent.Project = project
+		}
+		agent.Status = AgentStatusOnline
 		agent.IP = req.Ip
-		s.DB.Save(&agent)
+		agent.Tags = joinTags(req.Tags)
+		agent.LastSeen = time.Now()
+		agent.Version, agent.OS, agent.Arch = req.Version, req.Os, req.Arch
+		switch {
+		case req.UpdateError != "":
+			// 新版本没能注册上, Agent 已经回滚; 清掉目标版本免得反复升级
+			log.Printf(" [Release] %s 升级到 %s 失败, 已回滚到 %s: %s", agentID, agent.TargetVersion, req.Version, req.UpdateError)
+			agent.UpdateError = req.UpdateError
+			agent.TargetVersion = ""
+		case agent.TargetVersion != "" && agent.TargetVersion == req.Version:
+			log.Printf(" [Release] %s 已升级到 %s", agentID, req.Version)
+			agent.TargetVersion = ""
+			agent.UpdateError = ""
+		}
+		if err := s.DB.Save(&agent).Error; err != nil {
+			log.Printf(" [DB] 节点信息更新失败: %v", err)
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
 		log.Println(" [DB] 节点信息已更新")
 	}
+	if credential != "" {
+		log.Printf(" [Register] 已为 %s 签发 credential", agentID)
+	}
+
+	s.Events.Emit(events.TypeAgentOnline, events.AgentEvent{
+		AgentID:  agentID,
+		Project:  project,
+		Hostname: req.Hostname,
+		IP:       req.Ip,
+		Status:   AgentStatusOnline,
+		LastSeen: time.Now(),
+	})
+	s.auditRegister(ctx, agentID, req, "ok")
 
 	return &pb.RegisterResp{
-		AgentId: agentID,
-		Success: true,
+		AgentId:         agentID,
+		Success:         true,
+		AgentCredential: credential,
 	}, nil
 }
 
 func (s *SentinelServer) Heartbeat(stream pb.SentinelService_HeartbeatServer) error {
+	ctx := stream.Context()
+	// 身份由拦截器按 credential 校验过; 项目只在注册时变化, 每条心跳流取一次
+	agent := agentFrom(ctx)
+	agentID, project := agent.AgentID, agent.Project
+	var configVersion, updateSent string
+	var update *pb.AgentUpdate
+	var lastSync, lastSeen, lastMetric time.Time
+	claimed := false
+
+	defer func() {
+		if claimed {
+			if err := s.Node.Registry.Release(context.Background(), agentID); err != nil {
+				log.Printf("[Cluster] 释放 %s 归属失败: %v", agentID, err)
+			}
+		}
+	}()
+
 	for {
 
 		req, err := stream.Recv()
@@ -74,40 +255,189 @@ func (s *SentinelServer) Heartbeat(stream pb.SentinelService_HeartbeatServer) er
 			log.Printf(" 接收错误: %v", err)
 			return err
 		}
+		if err := checkAgentID(ctx, req.AgentId); err != nil {
+			return err
+		}
 
-		if val, ok := s.JobQueue.LoadAndDelete(req.AgentId); ok {
-			job := val.(*pb.Job)
-			log.Printf("[Dispatch] 发现信箱有任务! 派发给 %s -> %s", req.AgentId, job.Payload)
+		if !claimed {
+			log.Printf("[Cluster] Agent %s 的心跳流挂在本实例 %s", agentID, s.Node.ID)
+			claimed = true
+		}
+		if err := s.Node.Registry.Claim(ctx, agentID); err != nil {
+			log.Printf("[Cluster] 登记 %s 归属失败: %v", agentID, err)
+		}
+		if time.Since(lastSeen) > lastSeenWriteInterval {
+			s.DB.Model(&AgentModel{}).Where("agent_id = ?", agentID).
+				Updates(map[string]any{"last_seen": time.Now(), "status": AgentStatusOnline})
+			lastSeen = time.Now()
+		}
+		if time.Since(lastMetric) > metricsWriteInterval && s.recordMetrics(ctx, agentID, project, req) {
+			lastMetric = time.Now()
+		}
+
+		// 刚连上以及之后每隔一段时间, 从库里补一遍排队中的任务, 兜底丢失的转发消息
+		resync := time.Since(lastSync) > queueResyncInterval
+		if resync {
+			// credential 被管理员重置或者换过之后, 用旧 credential 建立的流不能继续收任务
+			if current, err := s.loadAgent(ctx, agentID); err == nil && current.CredentialHash != agent.CredentialHash {
+				log.Printf("[Auth] %s 的 credential 已变更, 断开心跳流", agentID)
+				return status.Error(codes.Unauthenticated, ErrAgentCredential.Error())
+			}
+			s.loadQueuedJobs(agentID)
+			lastSync = time.Now()
+		}
+
+		// 远程配置版本和升级目标跟着同一个节奏重算, 收到变更通知时立即重算
+		if resync || s.JobQueue.TakeRefresh(agentID) {
+			if cfg, err := s.EffectiveAgentConfig(ctx, agentID); err != nil {
+				log.Printf("[Config] 计算 %s 的配置失败: %v", agentID, err)
+			} else {
+				configVersion = cfg.Version
+			}
+			if update, err = s.pendingUpdate(ctx, agentID); err != nil {
+				log.Printf("[Release] 查询 %s 的升级目标失败: %v", agentID, err)
+			}
+		}
+		outdated := req.ConfigVersion != configVersion
+
+		// 同一条心跳流上每个目标版本只通知一次, Agent 升级重启后会换一条新流
+		if update != nil && update.Version != updateSent {
+			log.Printf("[Release] 通知 %s 升级到 %s", agentID, update.Version)
+			if err := stream.Send(&pb.HeartbeatResp{Update: update, ConfigOutdated: outdated}); err != nil {
+				return err
+			}
+			updateSent = update.Version
+		}
+
+		jobs, cancels := s.JobQueue.Drain(agentID)
+		sent := false
+
+		for _, job := range jobs {
+			if jobExpired(job) {
+				s.expireQueuedJob(job)
+				continue
+			}
+			var sealed []SealedSecret
+			if len(job.Secrets) > 0 {
+				if sealed, err = s.resolveAgentSecrets(ctx, agent, job); err != nil {
+					s.failQueuedJob(job, "secret unavailable: "+err.Error())
+					continue
+				}
+			}
+			if !s.markDispatched(agentID, project, job, sealed) {
+				continue
+			}
+			log.Printf("[Dispatch] 发现信箱有任务! 派发给 %s -> %s", agentID, s.Redactor.Redact(job.Payload))
+			if err := stream.Send(&pb.HeartbeatResp{Job: job, ConfigOutdated: outdated}); err != nil {
+				s.DB.Model(&JobRecord{}).
+					Where("job_id = ? AND status = ?", job.JobId, JobStatusDispatched).
+					Updates(map[string]any{"status": JobStatusQueued, "dispatched_at": nil})
+				return err
+			}
+			sent = true
+		}
 
-			err := stream.Send(&pb.HeartbeatResp{
-				Job: job,
-			})
-			if err != nil {
+		if len(cancels) > 0 {
+			log.Printf("[Dispatch] 通知 %s 取消任务: %v", agentID, cancels)
+			if err := stream.Send(&pb.HeartbeatResp{CancelJobIds: cancels, ConfigOutdated: outdated}); err != nil {
 				return err
 			}
-		} else {
-			stream.Send(&pb.HeartbeatResp{ConfigOutdated: false})
+			sent = true
+		}
+
+		if !sent {
+			stream.Send(&pb.HeartbeatResp{ConfigOutdated: outdated})
 		}
 	}
 }
 
+// reportableStatuses Agent 能汇报的终态
+var reportableStatuses = map[string]bool{
+	JobStatusSuccess:   true,
+	JobStatusFailed:    true,
+	JobStatusCancelled: true,
+	JobStatusRejected:  true,
+}
+
+// runningJobStatuses 已经派发给 Agent、还没有结果的状态, 只有这些任务接受汇报
+var runningJobStatuses = []string{JobStatusDispatched, JobStatusCancelling}
+
 func (s *SentinelServer) ReportJobStatus(ctx context.Context, req *pb.ReportJobReq) (*pb.ReportJobResp, error) {
+	if err := checkAgentID(ctx, req.AgentId); err != nil {
+		return nil, err
+	}
+	if !reportableStatuses[req.Status] {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown job status %q", req.Status)
+	}
+
+	// 只接受派发给这个 Agent、还在执行中的任务. 已经结束的任务 (比如重放被 Agent 拒绝的) 不能被改写,
+	// 别的 Agent 或者库里没有的 job_id 一律忽略并记下来
+	var refs JobRecord
+	err := s.DB.Select("secrets", "dispatched_secrets").
+		Where("job_id = ? AND agent_id = ? AND status IN ?", req.JobId, req.AgentId, runningJobStatuses).
+		First(&refs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[Report] ⚠️ 可疑汇报已忽略: Agent %s 汇报的任务 %s (%s) 不存在、不属于它或已经结束",
+			req.AgentId, req.JobId, req.Status)
+		return &pb.ReportJobResp{Received: false}, nil
+	}
+	if err != nil {
+		log.Printf("[DB] 查询任务记录失败: %v", err)
+		return nil, status.Error(codes.Unavailable, "job store unavailable")
+	}
+
+	// Agent 已经抹过一遍密钥, 服务端再按任务引用的密钥兜底, 然后跑脱敏规则, 之后才落库和打日志
+	if len(refs.Secrets) > 0 {
+		req.Result = redact.Values(req.Result, s.secretValues(ctx, &refs)...)
+	}
+	req.Result = s.Redactor.Redact(req.Result)
 
 	log.Printf(" [Report] 收到任务汇报! Agent: %s | Job: %s | 状态: %s | 结果: %s",
 		req.AgentId, req.JobId, req.Status, req.Result)
-	record := JobRecord{
-		JobID:      req.JobId,
-		AgentID:    req.AgentId,
-		Type:       "PING",
-		Payload:    "Unknown",
-		Result:     req.Result,
-		Status:     req.Status,
-		ExecutedAt: time.Now(),
+
+	res := s.DB.Model(&JobRecord{}).
+		Where("job_id = ? AND agent_id = ? AND status IN ?", req.JobId, req.AgentId, runningJobStatuses).
+		Select("result", "status", "executed_at").
+		Updates(&JobRecord{Result: req.Result, Status: req.Status, ExecutedAt: time.Now()})
+	if res.Error != nil {
+		log.Printf("[DB] 更新任务记录失败: %v", res.Error)
+		return nil, status.Error(codes.Unavailable, "job store unavailable")
 	}
-	if err := s.DB.Create(&record).Error; err != nil {
-		log.Printf("[DB] 保存任务记录失败: %v", err)
-	} else {
-		log.Printf("[DB] 任务记录已入库 (ID: %d)", record.ID)
+	if res.RowsAffected == 0 {
+		// 查询和更新之间任务被别处结束了 (比如过期清理)
+		return &pb.ReportJobResp{Received: false}, nil
+	}
+	log.Printf("[DB] 任务记录已更新 (Job: %s)", req.JobId)
+	var record JobRecord
+	if err := s.DB.Where("job_id = ?", req.JobId).First(&record).Error; err == nil {
+		s.emitJobEvent(finishedEventType(record.Status), &record)
+		s.indexJobOutput(ctx, &record)
 	}
 	return &pb.ReportJobResp{Received: true}, nil
 }
+
+func finishedEventType(status string) string {
+	switch status {
+	case JobStatusSuccess:
+		return events.TypeJobCompleted
+	case JobStatusCancelled:
+		return events.TypeJobCancelled
+	case JobStatusRejected:
+		return events.TypeJobRejected
+	default:
+		return events.TypeJobFailed
+	}
+}
+
+func (s *SentinelServer) emitJobEvent(eventType string, rec *JobRecord) {
+	s.Events.Emit(eventType, events.JobEvent{
+		JobID:       rec.JobID,
+		AgentID:     rec.AgentID,
+		Project:     rec.Project,
+		JobType:     rec.Type,
+		Status:      rec.Status,
+		OutputBytes: len(rec.Result),
+		SubmittedBy: rec.SubmittedBy,
+		ApprovedBy:  rec.ApprovedBy,
+	})
+}
@@ -1,18 +1,26 @@
 package server
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"math"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/audit"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
 	"gorm.io/gorm"
 )
 
 type HttpServer struct {
 	DB  *gorm.DB
 	Srv *SentinelServer
+
+	srv *http.Server
 }
 
 func NewHttpServer(db *gorm.DB, srv *SentinelServer) *HttpServer {
@@ -22,42 +30,832 @@ func NewHttpServer(db *gorm.DB, srv *SentinelServer) *HttpServer {
 	}
 }
 
-type JobRequest struct {
-	TargetAgent string `json:"target"`
-	Cmd         string `json:"cmd"`
-}
-
-func (h *HttpServer) Start() {
+// Start 阻塞运行 HTTP 服务, 直到 Shutdown 被调用 (返回 http.ErrServerClosed) 或出错.
+// 所有接口都要求 Authorization: Bearer <token>, 所需角色按路由对应的操作查 operations, 和 gRPC 共用; 每个请求都会写一条审计记录.
+// Agent 和任务相关的接口作用于 X-Sentinel-Project 指定的项目 (缺省 default), 按项目内的角色校验.
+func (h *HttpServer) Start(cfg config.ServerConfig) error {
 	r := gin.Default()
+	// 默认 gin 信任所有代理, 客户端伪造 X-Forwarded-For 就能改掉审计日志里的来源 IP
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return err
+	}
+	mountDashboard(r) // 要在审计中间件之前注册
+	r.Use(h.auditTrail())
+
+	r.GET("/agent", h.require("ListAgents"), func(c *gin.Context) {
+		agents, err := h.Srv.ListAgents(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": agents})
+	})
 
-	r.GET("/agent", func(c *gin.Context) {
-		var agents []AgentModel
-		h.DB.Find(&agents)
+	r.GET("/agent/:id", h.require("GetAgent"), func(c *gin.Context) {
+		agent, err := h.Srv.GetAgent(c.Request.Context(), c.Param("id"))
+		switch {
+		case errors.Is(err, ErrAgentNotFound):
+			c.JSON(404, gin.H{"error": "Agent 不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": agent})
+	})
+
+	// 预览 selector 会命中哪些 Agent, body 即 Selector, 不下发任何任务
+	r.POST("/agent/select", h.require("SelectAgents"), func(c *gin.Context) {
+		var sel Selector
+		if err := c.ShouldBindJSON(&sel); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		agents, err := h.Srv.SelectAgents(c.Request.Context(), sel)
+		switch {
+		case errors.Is(err, ErrInvalidSelector):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(200, gin.H{"code": 200, "data": agents})
 	})
 
-	r.POST("/job", func(c *gin.Context) {
+	// 各 Agent 最近 window (默认 1h, 最长 24h) 内每分钟一个的 CPU / 内存采样
+	r.GET("/agent-metrics", h.require("GetAgentMetrics"), func(c *gin.Context) {
+		window := time.Hour
+		if v := c.Query("window"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				c.JSON(400, gin.H{"error": "window 格式不对, 例如 30m / 6h"})
+				return
+			}
+			window = d
+		}
+		metrics, err := h.Srv.AgentMetrics(c.Request.Context(), window)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": metrics})
+	})
+
+	r.POST("/job", h.require("SubmitJob"), func(c *gin.Context) {
 		var req JobRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(400, gin.H{"error": "JSON 格式不对"})
 			return
 		}
+		user := currentUser(c)
+		records, err := h.Srv.Submit(c.Request.Context(), user.Name, req)
+		if writeSubmitError(c, err, req.TargetAgent) {
+			return
+		}
+		if req.Selector != nil {
+			jobs := make([]gin.H, len(records))
+			for i, r := range records {
+				jobs[i] = gin.H{"job": r.JobID, "agent": r.AgentID, "status": r.Status}
+			}
+			log.Printf("[HTTP] %s 批量下发任务 -> %s (%d 台) : %s", user.Name, currentProject(c), len(records), h.Srv.Redactor.Redact(req.Cmd))
+			c.JSON(200, gin.H{"code": 200, "project": currentProject(c), "jobs": jobs})
+			return
+		}
 
-		jobID := fmt.Sprintf("manual-%s-%d", req.TargetAgent, time.Now().UnixNano())
+		record := records[0]
+		c.Set(auditTargetKey, record.JobID)
+		log.Printf("[HTTP] %s 下发任务 -> %s/%s : %s", user.Name, record.Project, req.TargetAgent, h.Srv.Redactor.Redact(req.Cmd))
 
-		job := &pb.Job{
-			JobId:   jobID,
-			Type:    pb.JobType_PING,
-			Payload: req.Cmd,
+		msg := "任务已进入队列，等待 Agent 心跳领取"
+		if record.Status == JobStatusPendingApproval {
+			msg = "任务命中审批规则 " + record.ApprovalRule + "，需要其他 approver 批准后才会派发"
 		}
-		h.Srv.JobQueue.Store(req.TargetAgent, job)
-		log.Printf("[HTTP] 管理员下发任务 -> %s : %s", req.TargetAgent, req.Cmd)
+		c.JSON(200, gin.H{
+			"code":    200,
+			"msg":     msg,
+			"job":     record.JobID,
+			"project": record.Project,
+			"status":  record.Status,
+		})
+	})
+
+	r.POST("/job/:id/cancel", h.require("CancelJob"), func(c *gin.Context) {
+		record, err := h.Srv.CancelJob(c.Request.Context(), c.Param("id"))
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			c.JSON(404, gin.H{"error": "任务不存在"})
+			return
+		case errors.Is(err, ErrJobFinished):
+			c.JSON(409, gin.H{"error": "任务已结束，无法取消", "status": record.Status})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] %s 取消任务 -> %s", currentUser(c).Name, record.JobID)
 
 		c.JSON(200, gin.H{
-			"code": 200,
-			"msg":  "任务已进入队列，等待 Agent 心跳领取",
-			"job":  jobID,
+			"code":   200,
+			"job":    record.JobID,
+			"status": record.Status,
+		})
+	})
+
+	r.POST("/job/:id/approve", h.require("ApproveJob"), func(c *gin.Context) {
+		record, err := h.Srv.ApproveJob(c.Request.Context(), c.Param("id"), currentUser(c))
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			c.JSON(404, gin.H{"error": "任务不存在"})
+			return
+		case errors.Is(err, ErrSelfApproval):
+			c.JSON(403, gin.H{"error": "不能批准自己提交的任务"})
+			return
+		case errors.Is(err, ErrJobNotPending), errors.Is(err, ErrApprovalExpired):
+			c.JSON(409, gin.H{"error": err.Error(), "status": record.Status})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{
+			"code":        200,
+			"job":         record.JobID,
+			"status":      record.Status,
+			"approved_by": record.ApprovedBy,
+		})
+	})
+
+	// 任务历史: agent / status / type / submitted_by / since / until / q 过滤, sort 排序, cursor 翻页
+	r.GET("/jobs", h.require("ListJobs"), func(c *gin.Context) {
+		q, err := jobQuery(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "查询参数不对: " + err.Error()})
+			return
+		}
+		h.listJobs(c, q)
+	})
+
+	r.GET("/jobs/:id", h.require("GetJob"), func(c *gin.Context) {
+		record, err := h.Srv.GetJob(c.Request.Context(), c.Param("id"))
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			c.JSON(404, gin.H{"error": "任务不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": newJobView(record, true)})
+	})
+
+	// 执行中的增量输出, after 为上次拿到的最后一个 seq; finished 为 true 之后不会再有新的分片
+	r.GET("/jobs/:id/output", h.require("GetJobOutput"), func(c *gin.Context) {
+		after, err := strconv.ParseInt(c.DefaultQuery("after", "0"), 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "after 必须是整数"})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "limit 必须是整数"})
+			return
+		}
+		page, err := h.Srv.JobOutput(c.Request.Context(), c.Param("id"), after, limit)
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			c.JSON(404, gin.H{"error": "任务不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": page})
+	})
+
+	// 搜索任务输出: q 短语 (走索引) / regex 正则, agent / since / until 过滤, 返回高亮片段
+	r.GET("/search", h.require("SearchJobs"), func(c *gin.Context) {
+		q, err := searchQuery(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "查询参数不对: " + err.Error()})
+			return
+		}
+		hits, next, err := h.Srv.Search(c.Request.Context(), q)
+		switch {
+		case errors.Is(err, ErrInvalidSearch):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		resp := gin.H{"code": 200, "data": hits}
+		if next != "" {
+			resp["next_cursor"] = next
+		}
+		c.JSON(200, resp)
+	})
+
+	r.GET("/agent/:id/config", h.require("GetEffectiveAgentConfig"), func(c *gin.Context) {
+		if _, err := h.Srv.GetAgent(c.Request.Context(), c.Param("id")); errors.Is(err, ErrAgentNotFound) {
+			c.JSON(404, gin.H{"error": "Agent 不存在"})
+			return
+		} else if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		cfg, err := h.Srv.EffectiveAgentConfig(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": cfg})
+	})
+
+	// 作废 Agent 的 credential, 之后它要带项目的 enrollment token 重新注册
+	r.DELETE("/agent/:id/credential", h.require("ResetAgentCredential"), func(c *gin.Context) {
+		agent, err := h.Srv.ResetAgentCredential(c.Request.Context(), c.Param("id"))
+		switch {
+		case errors.Is(err, ErrAgentNotFound):
+			c.JSON(404, gin.H{"error": "Agent 不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": agent})
+	})
+
+	r.GET("/agent/:id/jobs", h.require("ListJobs"), func(c *gin.Context) {
+		if _, err := h.Srv.GetAgent(c.Request.Context(), c.Param("id")); errors.Is(err, ErrAgentNotFound) {
+			c.JSON(404, gin.H{"error": "Agent 不存在"})
+			return
+		} else if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		q, err := jobQuery(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "查询参数不对: " + err.Error()})
+			return
+		}
+		q.AgentID = c.Param("id")
+		h.listJobs(c, q)
+	})
+
+	// 配置可能按 Agent ID / 标签跨项目生效, 只有管理员能看全量
+	r.GET("/agent-config", h.require("ListAgentConfigRules"), func(c *gin.Context) {
+		rows, err := h.Srv.ListAgentConfigs(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": rows})
+	})
+
+	// scope 为 agent 或 tag, target 为 Agent ID 或标签名
+	r.PUT("/agent-config/:scope/:target", h.require("PutAgentConfigRule"), func(c *gin.Context) {
+		var req AgentConfigModel
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		req.Scope, req.Target = c.Param("scope"), c.Param("target")
+		saved, err := h.Srv.PutAgentConfig(c.Request.Context(), &req)
+		switch {
+		case errors.Is(err, ErrInvalidAgentConfig):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员更新 Agent 配置 -> %s/%s", saved.Scope, saved.Target)
+		c.JSON(200, gin.H{"code": 200, "data": saved})
+	})
+
+	r.DELETE("/agent-config/:scope/:target", h.require("DeleteAgentConfigRule"), func(c *gin.Context) {
+		err := h.Srv.DeleteAgentConfig(c.Request.Context(), c.Param("scope"), c.Param("target"))
+		switch {
+		case errors.Is(err, ErrAgentConfigNotFound):
+			c.JSON(404, gin.H{"error": "配置不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员删除 Agent 配置 -> %s/%s", c.Param("scope"), c.Param("target"))
+		c.JSON(200, gin.H{"code": 200})
+	})
+
+	// 上传 Agent 二进制: multipart 表单, 字段 version / os / arch / file
+	r.POST("/agent-release", h.require("UploadAgentRelease"), func(c *gin.Context) {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(400, gin.H{"error": "缺少 file 字段"})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+
+		rel, err := h.Srv.PublishRelease(c.Request.Context(), c.PostForm("version"), c.PostForm("os"), c.PostForm("arch"), f)
+		switch {
+		case errors.Is(err, ErrInvalidRelease):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrReleaseExists):
+			c.JSON(409, gin.H{"error": "该版本和平台的二进制已存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": rel})
+	})
+
+	r.GET("/agent-release", h.require("ListAgentReleases"), func(c *gin.Context) {
+		rels, err := h.Srv.ListReleases(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": rels})
+	})
+
+	// 按 selector 安排升级, body 即 Selector
+	r.POST("/agent-release/:version/rollout", h.require("RolloutAgentRelease"), func(c *gin.Context) {
+		var sel Selector
+		if err := c.ShouldBindJSON(&sel); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		res, err := h.Srv.RolloutRelease(c.Request.Context(), c.Param("version"), sel)
+		switch {
+		case errors.Is(err, ErrInvalidSelector):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrReleaseNotFound):
+			c.JSON(404, gin.H{"error": "该版本没有任何二进制"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员安排升级 -> %s (%d 台)", res.Version, len(res.Targeted))
+		c.JSON(200, gin.H{"code": 200, "data": res})
+	})
+
+	r.GET("/approval-rule", h.require("ListApprovalRules"), func(c *gin.Context) {
+		rules, err := h.Srv.ListApprovalRules(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": rules})
+	})
+
+	r.PUT("/approval-rule/:name", h.require("PutApprovalRule"), func(c *gin.Context) {
+		var req ApprovalRule
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		req.Name = c.Param("name")
+		saved, err := h.Srv.PutApprovalRule(c.Request.Context(), &req)
+		switch {
+		case errors.Is(err, ErrInvalidApprovalRule):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员更新审批规则 -> %s", saved.Name)
+		c.JSON(200, gin.H{"code": 200, "data": saved})
+	})
+
+	r.DELETE("/approval-rule/:name", h.require("DeleteApprovalRule"), func(c *gin.Context) {
+		err := h.Srv.DeleteApprovalRule(c.Request.Context(), c.Param("name"))
+		switch {
+		case errors.Is(err, ErrApprovalRuleNotFound):
+			c.JSON(404, gin.H{"error": "审批规则不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员删除审批规则 -> %s", c.Param("name"))
+		c.JSON(200, gin.H{"code": 200})
+	})
+
+	r.GET("/secret", h.require("ListSecrets"), func(c *gin.Context) {
+		secrets, err := h.Srv.ListSecrets(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": secrets})
+	})
+
+	// 新建或替换密钥, body 为 Secret 的元数据加上 value; 明文不会再通过任何接口返回
+	r.PUT("/secret/:name", h.require("PutSecret"), func(c *gin.Context) {
+		var req struct {
+			Secret
+			Value string `json:"value"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		req.Secret.Name = c.Param("name")
+		saved, err := h.Srv.PutSecret(c.Request.Context(), &req.Secret, req.Value, currentUser(c).Name)
+		switch {
+		case errors.Is(err, ErrInvalidSecret), errors.Is(err, ErrSecretsDisabled):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] %s 更新密钥 -> %s", saved.UpdatedBy, saved.Name)
+		c.JSON(200, gin.H{"code": 200, "data": saved})
+	})
+
+	r.DELETE("/secret/:name", h.require("DeleteSecret"), func(c *gin.Context) {
+		err := h.Srv.DeleteSecret(c.Request.Context(), c.Param("name"))
+		switch {
+		case errors.Is(err, ErrSecretNotFound):
+			c.JSON(404, gin.H{"error": "密钥不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] %s 删除密钥 -> %s", currentUser(c).Name, c.Param("name"))
+		c.JSON(200, gin.H{"code": 200})
+	})
+
+	r.GET("/user", h.require("ListUsers"), func(c *gin.Context) {
+		users, err := h.Srv.ListUsers(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": users})
+	})
+
+	// 创建用户, token 只在这里返回一次
+	r.POST("/user", h.require("CreateUser"), func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		u, token, err := h.Srv.CreateUser(c.Request.Context(), req.Name, req.Role)
+		switch {
+		case errors.Is(err, ErrInvalidUser):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrUserExists):
+			c.JSON(409, gin.H{"error": "用户已存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员创建用户 -> %s (%s)", u.Name, u.Role)
+		c.JSON(200, gin.H{"code": 200, "data": u, "token": token})
+	})
+
+	r.DELETE("/user/:name", h.require("DeleteUser"), func(c *gin.Context) {
+		err := h.Srv.DeleteUser(c.Request.Context(), c.Param("name"))
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			c.JSON(404, gin.H{"error": "用户不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员删除用户 -> %s", c.Param("name"))
+		c.JSON(200, gin.H{"code": 200})
+	})
+
+	// 当前用户能访问的项目
+	r.GET("/project", h.require("ListProjects"), func(c *gin.Context) {
+		projects, err := h.Srv.ListProjects(c.Request.Context(), currentUser(c))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": projects})
+	})
+
+	// 创建项目, enrollment token 只在这里 (以及轮换时) 返回一次
+	r.POST("/project", h.require("CreateProject"), func(c *gin.Context) {
+		var req struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		p, token, err := h.Srv.CreateProject(c.Request.Context(), req.Name, req.Description)
+		switch {
+		case errors.Is(err, ErrInvalidProject):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrProjectExists):
+			c.JSON(409, gin.H{"error": "项目已存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员创建项目 -> %s", p.Name)
+		c.JSON(200, gin.H{"code": 200, "data": p, "enrollment_token": token})
+	})
+
+	r.DELETE("/project/:project", h.require("DeleteProject"), func(c *gin.Context) {
+		err := h.Srv.DeleteProject(c.Request.Context(), c.Param("project"))
+		switch {
+		case errors.Is(err, ErrInvalidProject):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrProjectNotFound):
+			c.JSON(404, gin.H{"error": "项目不存在"})
+			return
+		case errors.Is(err, ErrProjectNotEmpty):
+			c.JSON(409, gin.H{"error": "项目里还有 Agent, 不能删除"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员删除项目 -> %s", c.Param("project"))
+		c.JSON(200, gin.H{"code": 200})
+	})
+
+	// 轮换 enrollment token, 旧 token 立即失效, 已加入的 Agent 不受影响
+	r.POST("/project/:project/enrollment-token", h.require("RotateEnrollmentToken"), func(c *gin.Context) {
+		token, err := h.Srv.RotateEnrollmentToken(c.Request.Context(), currentProject(c))
+		switch {
+		case errors.Is(err, ErrProjectNotFound):
+			c.JSON(404, gin.H{"error": "项目不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] %s 轮换项目 %s 的 enrollment token", currentUser(c).Name, currentProject(c))
+		c.JSON(200, gin.H{"code": 200, "enrollment_token": token})
+	})
+
+	r.GET("/project/:project/member", h.require("ListMembers"), func(c *gin.Context) {
+		members, err := h.Srv.ListMembers(c.Request.Context(), currentProject(c))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": members})
+	})
+
+	// 加入项目或修改角色, body 为 {"role": "operator"}
+	r.PUT("/project/:project/member/:user", h.require("PutMember"), func(c *gin.Context) {
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		m, err := h.Srv.PutMember(c.Request.Context(), currentProject(c), c.Param("user"), req.Role)
+		switch {
+		case errors.Is(err, ErrInvalidUser):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrUserNotFound):
+			c.JSON(404, gin.H{"error": "用户不存在"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] %s 设置项目成员 -> %s/%s (%s)", currentUser(c).Name, m.Project, m.User, m.Role)
+		c.JSON(200, gin.H{"code": 200, "data": m})
+	})
+
+	r.DELETE("/project/:project/member/:user", h.require("DeleteMember"), func(c *gin.Context) {
+		err := h.Srv.DeleteMember(c.Request.Context(), currentProject(c), c.Param("user"))
+		switch {
+		case errors.Is(err, ErrMemberNotFound):
+			c.JSON(404, gin.H{"error": "该用户不是项目成员"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] %s 移除项目成员 -> %s/%s", currentUser(c).Name, currentProject(c), c.Param("user"))
+		c.JSON(200, gin.H{"code": 200})
+	})
+
+	// 当前用户和当前项目的配额及用量
+	r.GET("/quota/usage", h.require("GetQuotaUsage"), func(c *gin.Context) {
+		user, err := h.Srv.QuotaUsage(c.Request.Context(), QuotaScopeUser, currentUser(c).Name)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		project, err := h.Srv.QuotaUsage(c.Request.Context(), QuotaScopeProject, currentProject(c))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": gin.H{"user": user, "project": project}})
+	})
+
+	r.GET("/quota", h.require("ListQuotas"), func(c *gin.Context) {
+		rows, err := h.Srv.ListQuotas(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"code": 200, "data": rows, "defaults": gin.H{"user": h.Srv.UserQuota, "project": h.Srv.ProjectQuota}})
+	})
+
+	// scope 为 user 或 project, target 为用户名或项目名; 字段为 0 沿用默认值, -1 表示不限制
+	r.PUT("/quota/:scope/:target", h.require("PutQuota"), func(c *gin.Context) {
+		var req QuotaModel
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "JSON 格式不对"})
+			return
+		}
+		req.Scope, req.Target = c.Param("scope"), c.Param("target")
+		saved, err := h.Srv.PutQuota(c.Request.Context(), &req)
+		switch {
+		case errors.Is(err, ErrInvalidQuota):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员调整配额 -> %s/%s", saved.Scope, saved.Target)
+		c.JSON(200, gin.H{"code": 200, "data": saved})
+	})
+
+	r.DELETE("/quota/:scope/:target", h.require("DeleteQuota"), func(c *gin.Context) {
+		err := h.Srv.DeleteQuota(c.Request.Context(), c.Param("scope"), c.Param("target"))
+		switch {
+		case errors.Is(err, ErrQuotaNotFound):
+			c.JSON(404, gin.H{"error": "没有这条配额调整"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("[HTTP] 管理员删除配额调整 -> %s/%s", c.Param("scope"), c.Param("target"))
+		c.JSON(200, gin.H{"code": 200})
+	})
+
+	r.GET("/audit", h.require("QueryAudit"), func(c *gin.Context) {
+		f, err := auditFilter(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "查询参数不对: " + err.Error()})
+			return
+		}
+		entries, err := h.Srv.Audit.Query(c.Request.Context(), f)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		resp := gin.H{"code": 200, "data": entries}
+		if len(entries) == f.Limit {
+			resp["next_after_seq"] = entries[len(entries)-1].Seq
+		}
+		c.JSON(200, resp)
+	})
+
+	// 导出为 JSON Lines, 每行一条完整记录 (含哈希), 可以在库外独立校验整条链
+	r.GET("/audit/export", h.require("ExportAudit"), func(c *gin.Context) {
+		f, err := auditFilter(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "查询参数不对: " + err.Error()})
+			return
+		}
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="audit-`+time.Now().UTC().Format("20060102T150405Z")+`.jsonl"`)
+		enc := json.NewEncoder(c.Writer)
+		err = h.Srv.Audit.Export(c.Request.Context(), f, func(e *audit.Entry) error {
+			return enc.Encode(e)
 		})
+		if err != nil {
+			// 响应头已经发出去了, 只能截断输出
+			log.Printf("[HTTP] 导出审计日志中断: %v", err)
+		}
 	})
-	r.Run(":8080")
+
+	r.GET("/audit/verify", h.require("VerifyAudit"), func(c *gin.Context) {
+		res, err := h.Srv.Audit.Verify(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if !res.Valid {
+			log.Printf("[Audit] ⚠️ 审计日志哈希链校验失败: seq=%d %s", res.BrokenAt, res.Reason)
+		}
+		c.JSON(200, gin.H{"code": 200, "data": res})
+	})
+
+	tlsCfg, err := cfg.TLS.Load()
+	if err != nil {
+		return err
+	}
+	h.srv = &http.Server{
+		Addr:         cfg.HTTPAddr,
+		Handler:      r,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		TLSConfig:    tlsCfg,
+	}
+	if tlsCfg != nil {
+		return h.srv.ListenAndServeTLS("", "")
+	}
+	return h.srv.ListenAndServe()
+}
+
+func (h *HttpServer) listJobs(c *gin.Context, q JobQuery) {
+	records, next, err := h.Srv.ListJobs(c.Request.Context(), q)
+	switch {
+	case errors.Is(err, ErrInvalidJobQuery):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	jobs := make([]JobView, len(records))
+	for i := range records {
+		jobs[i] = newJobView(&records[i], false)
+	}
+	resp := gin.H{"code": 200, "data": jobs}
+	if next != "" {
+		resp["next_cursor"] = next
+	}
+	c.JSON(200, resp)
+}
+
+// writeSubmitError 把提交任务的错误映射成响应, 返回 false 表示 err 为 nil 没有写响应
+func writeSubmitError(c *gin.Context, err error, target string) bool {
+	var qe *QuotaError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &qe):
+		quotaExceeded(c, qe)
+	case errors.Is(err, ErrAgentNotFound):
+		c.JSON(404, gin.H{"error": "Agent 不存在或不在项目 " + currentProject(c) + " 里: " + target})
+	case errors.Is(err, ErrNoAgentsMatched):
+		c.JSON(404, gin.H{"error": "selector 在项目 " + currentProject(c) + " 里没有命中任何 Agent"})
+	case errors.Is(err, ErrInvalidJobRequest), errors.Is(err, ErrInvalidSelector), errors.Is(err, ErrInvalidSecretRef), errors.Is(err, ErrSecretNotFound), errors.Is(err, ErrSecretsDisabled):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSecretDenied):
+		c.JSON(403, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": "任务入库失败: " + err.Error()})
+	}
+	return true
+}
+
+// quotaExceeded 429. 等一等就能放行的带上 Retry-After, fan-out 超上限这种等也没用的不带
+func quotaExceeded(c *gin.Context, qe *QuotaError) {
+	resp := gin.H{
+		"error":  qe.Error(),
+		"scope":  qe.Scope,
+		"target": qe.Target,
+		"limit":  qe.Limit,
+		"max":    qe.Max,
+	}
+	if qe.RetryAfter > 0 {
+		secs := int(math.Ceil(qe.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(secs))
+		resp["retry_after_seconds"] = secs
+	}
+	log.Printf("[Quota] %s 触发配额限制: %s", currentUser(c).Name, qe.Error())
+	c.JSON(429, resp)
+}
+
+func (h *HttpServer) Shutdown(ctx context.Context) error {
+	if h.srv == nil {
+		return nil
+	}
+	return h.srv.Shutdown(ctx)
 }
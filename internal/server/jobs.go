@@ -0,0 +1,280 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidJobQuery = errors.New("invalid job query")
+
+const (
+	defaultJobSort = "-created_at"
+	jobPageLimit   = 100
+	maxJobPage     = 1000
+
+	// 带文本过滤时一次请求最多扫描这么多行, 扫完还没凑满一页就先返回, 靠 next_cursor 接着往下翻
+	jobScanLimit = 5000
+	jobScanBatch = 500
+)
+
+// 可以排序的列, 都以 id 作为第二排序键保证游标稳定
+var jobSortColumns = map[string]bool{"created_at": true, "updated_at": true, "executed_at": true}
+
+// JobQuery 任务列表的过滤和翻页条件, 项目限制来自 ctx
+type JobQuery struct {
+	AgentID     string
+	Status      []string
+	Type        string
+	SubmittedBy string
+	Since       time.Time // created_at >= Since
+	Until       time.Time // created_at < Until
+	// Text 在 payload 和输出里做不区分大小写的子串匹配.
+	// 两者可能是加密存储的, 只能解密之后在 Go 里过滤, 不走索引.
+	Text   string
+	Sort   string // 列名, 前缀 "-" 表示降序, 默认 -created_at
+	Cursor string // 上一页返回的 next_cursor
+	Limit  int
+
+	// match 额外的过滤条件, 和 Text 一样在 Go 里判断, 见 Search
+	match func(*JobRecord) bool
+}
+
+// jobCursor 上一页最后一行的排序值和 id, base64 之后交给调用方
+type jobCursor struct {
+	Sort  string    `json:"s"`
+	Value time.Time `json:"v"`
+	ID    uint      `json:"id"`
+}
+
+func (q *JobQuery) sortColumn() (string, bool, error) {
+	if q.Sort == "" {
+		q.Sort = defaultJobSort
+	}
+	column, desc := strings.TrimPrefix(q.Sort, "-"), strings.HasPrefix(q.Sort, "-")
+	if !jobSortColumns[column] {
+		return "", false, fmt.Errorf("%w: cannot sort by %q", ErrInvalidJobQuery, q.Sort)
+	}
+	return column, desc, nil
+}
+
+func (q *JobQuery) apply(db *gorm.DB) (*gorm.DB, error) {
+	column, desc, err := q.sortColumn()
+	if err != nil {
+		return nil, err
+	}
+	if q.AgentID != "" {
+		db = db.Where("agent_id = ?", q.AgentID)
+	}
+	if len(q.Status) > 0 {
+		db = db.Where("status IN ?", q.Status)
+	}
+	if q.Type != "" {
+		db = db.Where("type = ?", q.Type)
+	}
+	if q.SubmittedBy != "" {
+		db = db.Where("submitted_by = ?", q.SubmittedBy)
+	}
+	if !q.Since.IsZero() {
+		db = db.Where("created_at >= ?", q.Since)
+	}
+	if !q.Until.IsZero() {
+		db = db.Where("created_at < ?", q.Until)
+	}
+	op, order := ">", "ASC"
+	if desc {
+		op, order = "<", "DESC"
+	}
+	if q.Cursor != "" {
+		cur, err := decodeJobCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cur.Sort != q.Sort {
+			return nil, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidJobQuery, cur.Sort)
+		}
+		db = db.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, op, column, op), cur.Value, cur.Value, cur.ID)
+	}
+	return db.Order(column + " " + order).Order("id " + order), nil
+}
+
+func (q *JobQuery) cursorFor(r *JobRecord) string {
+	cur := jobCursor{Sort: q.Sort, ID: r.ID}
+	switch strings.TrimPrefix(q.Sort, "-") {
+	case "created_at":
+		cur.Value = r.CreatedAt
+	case "updated_at":
+		cur.Value = r.UpdatedAt
+	case "executed_at":
+		cur.Value = r.ExecutedAt
+	}
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJobCursor(s string) (*jobCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad cursor", ErrInvalidJobQuery)
+	}
+	var cur jobCursor
+	if err := json.Unmarshal(b, &cur); err != nil {
+		return nil, fmt.Errorf("%w: bad cursor", ErrInvalidJobQuery)
+	}
+	return &cur, nil
+}
+
+func (q *JobQuery) filtered() bool { return q.Text != "" || q.match != nil }
+
+func (q *JobQuery) matches(r *JobRecord) bool {
+	if q.match != nil && !q.match(r) {
+		return false
+	}
+	if q.Text == "" {
+		return true
+	}
+	text := strings.ToLower(q.Text)
+	return strings.Contains(strings.ToLower(r.Payload), text) || strings.Contains(strings.ToLower(r.Result), text)
+}
+
+// ListJobs 按 q 返回一页任务, next 不为空表示可能还有下一页
+func (s *SentinelServer) ListJobs(ctx context.Context, q JobQuery) (jobs []JobRecord, next string, err error) {
+	if q.Limit <= 0 {
+		q.Limit = jobPageLimit
+	}
+	q.Limit = min(q.Limit, maxJobPage)
+	batch := q.Limit
+	if q.filtered() {
+		batch = max(q.Limit, jobScanBatch)
+	}
+	for scanned := 0; ; {
+		db, err := q.apply(s.DB.WithContext(ctx).Model(&JobRecord{}))
+		if err != nil {
+			return nil, "", err
+		}
+		var rows []JobRecord
+		if err := db.Limit(batch).Find(&rows).Error; err != nil {
+			return nil, "", err
+		}
+		for i := range rows {
+			if !q.matches(&rows[i]) {
+				continue
+			}
+			jobs = append(jobs, rows[i])
+			if len(jobs) == q.Limit {
+				return jobs, q.cursorFor(&rows[i]), nil
+			}
+		}
+		if len(rows) < batch {
+			return jobs, "", nil
+		}
+		q.Cursor = q.cursorFor(&rows[len(rows)-1])
+		if scanned += len(rows); scanned >= jobScanLimit {
+			return jobs, q.Cursor, nil
+		}
+	}
+}
+
+// GetJob 按任务 ID 查询, 项目限制来自 ctx
+func (s *SentinelServer) GetJob(ctx context.Context, jobID string) (*JobRecord, error) {
+	var record JobRecord
+	err := s.DB.WithContext(ctx).Where("job_id = ?", jobID).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// JobView 管理 API 返回的任务. 列表里不带输出, 单个任务才带.
+type JobView struct {
+	JobID             string      `json:"job_id"`
+	AgentID           string      `json:"agent_id"`
+	Project           string      `json:"project"`
+	Type              string      `json:"type"`
+	Status            string      `json:"status"`
+	Payload           string      `json:"payload"`
+	Result            *string     `json:"result,omitempty"`
+	SubmittedBy       string      `json:"submitted_by"`
+	Secrets           []SecretRef `json:"secrets,omitempty"`
+	ApprovalRule      string      `json:"approval_rule,omitempty"`
+	ApprovalExpiresAt *time.Time  `json:"approval_expires_at,omitempty"`
+	ApprovedBy        string      `json:"approved_by,omitempty"`
+	ApprovedAt        *time.Time  `json:"approved_at,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	DispatchedAt      *time.Time  `json:"dispatched_at,omitempty"`
+	ExecutedAt        *time.Time  `json:"executed_at,omitempty"`
+	ExpiresAt         *time.Time  `json:"expires_at,omitempty"`
+}
+
+func newJobView(r *JobRecord, withResult bool) JobView {
+	v := JobView{
+		JobID:             r.JobID,
+		AgentID:           r.AgentID,
+		Project:           r.Project,
+		Type:              r.Type,
+		Status:            r.Status,
+		Payload:           r.Payload,
+		SubmittedBy:       r.SubmittedBy,
+		Secrets:           r.Secrets,
+		ApprovalRule:      r.ApprovalRule,
+		ApprovalExpiresAt: r.ApprovalExpiresAt,
+		ApprovedBy:        r.ApprovedBy,
+		ApprovedAt:        r.ApprovedAt,
+		CreatedAt:         r.CreatedAt,
+		UpdatedAt:         r.UpdatedAt,
+		DispatchedAt:      r.DispatchedAt,
+		ExpiresAt:         r.ExpiresAt,
+	}
+	if !r.ExecutedAt.IsZero() {
+		v.ExecutedAt = &r.ExecutedAt
+	}
+	if withResult {
+		v.Result = &r.Result
+	}
+	return v
+}
+
+// jobQuery 从 URL 参数解析 JobQuery, status 可以用逗号分隔多个
+func jobQuery(c *gin.Context) (JobQuery, error) {
+	q := JobQuery{
+		AgentID:     c.Query("agent"),
+		Type:        strings.ToUpper(c.Query("type")),
+		SubmittedBy: c.Query("submitted_by"),
+		Text:        c.Query("q"),
+		Sort:        c.Query("sort"),
+		Cursor:      c.Query("cursor"),
+		Limit:       jobPageLimit,
+	}
+	if v := c.Query("status"); v != "" {
+		q.Status = strings.Split(v, ",")
+	}
+	var err error
+	if v := c.Query("since"); v != "" {
+		if q.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, err
+		}
+	}
+	if v := c.Query("until"); v != "" {
+		if q.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, err
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
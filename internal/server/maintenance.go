@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/cluster"
+	"github.com/stywzn/Go-Cloud-Compute/internal/events"
+	"gorm.io/gorm"
+)
+
+const (
+	// 超过这么久没有心跳就判定离线 (Agent 默认 5s 一次心跳)
+	agentOfflineAfter = 90 * time.Second
+	sweepInterval     = 30 * time.Second
+
+	purgeInterval  = time.Hour
+	purgeBatchSize = 1000
+)
+
+// OfflineSweeper 定期把长时间没有心跳的 Agent 标记为 Offline, 只应在 leader 上运行
+func (s *SentinelServer) OfflineSweeper(elector *cluster.Elector) cluster.LeaderTask {
+	return func(ctx context.Context, token int64) {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := elector.Check(ctx, token); err != nil {
+				log.Printf("[Sweeper] 租约校验失败, 跳过本轮 (token=%d): %v", token, err)
+				continue
+			}
+
+			s.sweepOfflineAgents(ctx, elector, token)
+			s.sweepOrphanedJobs(ctx, elector, token)
+		}
+	}
+}
+
+func (s *SentinelServer) sweepOfflineAgents(ctx context.Context, elector *cluster.Elector, token int64) {
+	cutoff := time.Now().Add(-agentOfflineAfter)
+	var stale []AgentModel
+	err := s.DB.WithContext(ctx).
+		Where("status = ? AND last_seen < ?", AgentStatusOnline, cutoff).
+		Find(&stale).Error
+	if err != nil {
+		log.Printf("[Sweeper] 查询离线节点失败: %v", err)
+		return
+	}
+
+	for _, agent := range stale {
+		// 条件更新: 查询之后 Agent 又发来心跳的话就不动它
+		var updated int64
+		err := elector.Fenced(ctx, token, func(tx *gorm.DB) error {
+			res := tx.Model(&AgentModel{}).
+				Where("id = ? AND status = ? AND last_seen < ?", agent.ID, AgentStatusOnline, cutoff).
+				Update("status", AgentStatusOffline)
+			updated = res.RowsAffected
+			return res.Error
+		})
+		if errors.Is(err, cluster.ErrLeaseLost) {
+			log.Printf("[Sweeper] 租约已失效, 中止本轮 (token=%d)", token)
+			return
+		}
+		if err != nil {
+			log.Printf("[Sweeper] 标记 %s 离线失败: %v", agent.AgentID, err)
+			continue
+		}
+		if updated == 0 {
+			continue
+		}
+		log.Printf("[Sweeper] 节点 %s 超过 %s 无心跳, 已标记为 Offline", agent.AgentID, agentOfflineAfter)
+		s.Events.Emit(events.TypeAgentOffline, events.AgentEvent{
+			AgentID:  agent.AgentID,
+			Project:  agent.Project,
+			Hostname: agent.Hostname,
+			IP:       agent.IP,
+			Status:   AgentStatusOffline,
+			LastSeen: agent.LastSeen,
+		})
+	}
+}
+
+// sweepOrphanedJobs 离线 Agent 手上派发出去的任务结束掉: Dispatched 判失败, Cancelling 判已取消.
+// 不结束的话它们一直占着并发配额, 也不会被保留期清理. 不重新排队, Agent 可能还在执行, 重发会执行两遍;
+// Agent 恢复之后再汇报的结果会被 ReportJobStatus 忽略.
+func (s *SentinelServer) sweepOrphanedJobs(ctx context.Context, elector *cluster.Elector, token int64) {
+	offline := s.DB.Model(&AgentModel{}).Select("agent_id").Where("status = ?", AgentStatusOffline)
+	var orphans []JobRecord
+	err := s.DB.WithContext(ctx).Select("job_id", "agent_id", "status").
+		Where("status IN ? AND agent_id IN (?)", runningJobStatuses, offline).
+		Limit(purgeBatchSize).Find(&orphans).Error
+	if err != nil {
+		log.Printf("[Sweeper] 查询离线节点的任务失败: %v", err)
+		return
+	}
+
+	for _, job := range orphans {
+		final, reason := JobStatusFailed, "agent went offline before reporting a result"
+		if job.Status == JobStatusCancelling {
+			final, reason = JobStatusCancelled, "agent went offline while the job was being cancelled"
+		}
+		var updated int64
+		err := elector.Fenced(ctx, token, func(tx *gorm.DB) error {
+			// 查询之后 Agent 又上线了的话就不动它的任务
+			res := tx.Model(&JobRecord{}).
+				Where("job_id = ? AND status = ? AND agent_id IN (?)", job.JobID, job.Status, offline).
+				Select("status", "result", "executed_at").
+				Updates(&JobRecord{Status: final, Result: reason, ExecutedAt: time.Now()})
+			updated = res.RowsAffected
+			return res.Error
+		})
+		if errors.Is(err, cluster.ErrLeaseLost) {
+			log.Printf("[Sweeper] 租约已失效, 中止本轮 (token=%d)", token)
+			return
+		}
+		if err != nil {
+			log.Printf("[Sweeper] 结束任务 %s 失败: %v", job.JobID, err)
+			continue
+		}
+		if updated == 0 {
+			continue
+		}
+		log.Printf("[Sweeper] 节点 %s 已离线, 任务 %s 判为 %s", job.AgentID, job.JobID, final)
+		var record JobRecord
+		if err := s.DB.WithContext(ctx).Where("job_id = ?", job.JobID).First(&record).Error; err == nil {
+			s.emitJobEvent(finishedEventType(record.Status), &record)
+		}
+	}
+}
+
+// RetentionPurger 分批物理删除超过保留期的任务记录, 只应在 leader 上运行
+func (s *SentinelServer) RetentionPurger(elector *cluster.Elector, retention time.Duration) cluster.LeaderTask {
+	return func(ctx context.Context, token int64) {
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			cutoff := time.Now().Add(-retention)
+			var total int64
+			for ctx.Err() == nil {
+				var deleted int64
+				err := elector.Fenced(ctx, token, func(tx *gorm.DB) error {
+					res := tx.Unscoped().
+						Where("created_at < ? AND status NOT IN ?", cutoff, []string{JobStatusPendingApproval, JobStatusQueued, JobStatusDispatched, JobStatusCancelling}).
+						Limit(purgeBatchSize).
+						Delete(&JobRecord{})
+					deleted = res.RowsAffected
+					return res.Error
+				})
+				if errors.Is(err, cluster.ErrLeaseLost) {
+					log.Printf("[Purger] 租约校验失败, 中止清理 (token=%d): %v", token, err)
+					break
+				}
+				if err != nil {
+					log.Printf("[Purger] 清理任务记录失败: %v", err)
+					break
+				}
+				total += deleted
+				if deleted < purgeBatchSize {
+					break
+				}
+			}
+			if total > 0 {
+				log.Printf("[Purger] 已清理 %d 条 %s 之前的任务记录", total, cutoff.Format(time.DateTime))
+			}
+			// 还没结束的任务没有输出也就没有索引, 索引按任务创建时间删即可
+			s.purgeOlderThan(ctx, elector, token, &JobTerm{}, "job_created_at", cutoff, "搜索索引")
+			s.purgeOlderThan(ctx, elector, token, &JobOutputChunk{}, "created_at", cutoff, "实时输出")
+			s.purgeOlderThan(ctx, elector, token, &AgentMetric{}, "sampled_at", time.Now().Add(-metricsRetention), "监控采样")
+		}
+	}
+}
+
+// purgeOlderThan 分批删掉 column 早于 cutoff 的行, 用于和任务记录一起过期的索引以及监控采样
+func (s *SentinelServer) purgeOlderThan(ctx context.Context, elector *cluster.Elector, token int64, model any, column string, cutoff time.Time, what string) {
+	var total int64
+	for ctx.Err() == nil {
+		var deleted int64
+		err := elector.Fenced(ctx, token, func(tx *gorm.DB) error {
+			res := tx.Where(column+" < ?", cutoff).Limit(purgeBatchSize).Delete(model)
+			deleted = res.RowsAffected
+			return res.Error
+		})
+		if errors.Is(err, cluster.ErrLeaseLost) {
+			log.Printf("[Purger] 租约校验失败, 中止清理%s (token=%d): %v", what, token, err)
+			return
+		}
+		if err != nil {
+			log.Printf("[Purger] 清理%s失败: %v", what, err)
+			return
+		}
+		total += deleted
+		if deleted < purgeBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("[Purger] 已清理 %d 条 %s 之前的%s", total, cutoff.Format(time.DateTime), what)
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+)
+
+const (
+	// 心跳 5s 一次, 落库按分钟降采样, 一台 Agent 一天 1440 行
+	metricsWriteInterval = time.Minute
+	metricsRetention     = 24 * time.Hour
+)
+
+// AgentMetric 心跳上报的 CPU / 内存使用率 (百分比), 给面板画趋势用
+type AgentMetric struct {
+	ID      uint      `gorm:"primaryKey" json:"-"`
+	AgentID string    `gorm:"size:191;index:idx_agent_metric,priority:1" json:"-"`
+	Project string    `gorm:"size:64;index" json:"-"`
+	At      time.Time `gorm:"column:sampled_at;index:idx_agent_metric,priority:2;index" json:"t"`
+	CPU     float64   `json:"cpu"`
+	Mem     float64   `json:"mem"`
+}
+
+// recordMetrics 保存一条采样. 老版本或者取不到使用率的 Agent 两个值都是 0, 不记.
+func (s *SentinelServer) recordMetrics(ctx context.Context, agentID, project string, req *pb.HeartbeatReq) bool {
+	if project == "" || (req.CpuUsage == 0 && req.MemUsage == 0) {
+		return false
+	}
+	m := &AgentMetric{AgentID: agentID, Project: project, At: time.Now(), CPU: req.CpuUsage, Mem: req.MemUsage}
+	if err := s.DB.WithContext(ctx).Create(m).Error; err != nil {
+		log.Printf("[Metrics] 保存 %s 的使用率失败: %v", agentID, err)
+		return false
+	}
+	return true
+}
+
+// AgentMetrics ctx 限定项目里各 Agent 最近 window 内的采样, 按时间升序
+func (s *SentinelServer) AgentMetrics(ctx context.Context, window time.Duration) (map[string][]AgentMetric, error) {
+	window = min(window, metricsRetention)
+	var rows []AgentMetric
+	err := s.DB.WithContext(ctx).Where("sampled_at >= ?", time.Now().Add(-window)).Order("agent_id, sampled_at").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]AgentMetric)
+	for _, m := range rows {
+		out[m.AgentID] = append(out[m.AgentID], m)
+	}
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/redact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// liveOutputLimit 每个任务最多保存多少实时输出, 超过之后只等任务结束时的完整输出.
+	// 每个分片都要连同之前的输出一起重新脱敏, 所以不能太大
+	liveOutputLimit = 1 << 20
+	// 一次最多返回多少个分片
+	defaultOutputPage = 200
+	maxOutputPage     = 1000
+)
+
+// JobOutputChunk 任务执行过程中 Agent 增量上报的输出, 按 Seq 拼起来就是到目前为止的输出.
+// 任务结束后以 JobRecord.Result 为准; 分片和任务记录一起按保留期清理.
+type JobOutputChunk struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	JobID     string    `gorm:"size:191;uniqueIndex:idx_job_output_seq,priority:1" json:"job_id"`
+	Seq       int64     `gorm:"uniqueIndex:idx_job_output_seq,priority:2" json:"seq"`
+	Project   string    `gorm:"size:64;index" json:"-"`
+	Data      string    `gorm:"serializer:envelope" json:"data"` // 和任务输出一样, 配置了 KEK 时加密存储
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (JobOutputChunk) TableName() string { return "job_output_chunks" }
+
+// ReportJobOutput 接收一个执行中任务的输出分片. 和 ReportJobStatus 一样只接受派发给这个 Agent、还没结束的任务,
+// 落库前同样抹掉密钥并跑脱敏规则. 跨分片的内容 (比如 PEM 块) 单看一个分片认不出来, 所以每次都对到目前为止的
+// 全部输出脱敏, 只存比上次多出来的部分. 同一个任务的 seq 已经存过的分片忽略.
+func (s *SentinelServer) ReportJobOutput(stream pb.SentinelService_ReportJobOutputServer) error {
+	ctx := stream.Context()
+	var job *JobRecord
+	var secrets []string
+	var raw strings.Builder
+	stored := ""
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.ReportJobResp{Received: job != nil})
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkAgentID(ctx, chunk.AgentId); err != nil {
+			return err
+		}
+		if job == nil {
+			var rec JobRecord
+			err := s.DB.Select("job_id", "project", "secrets", "dispatched_secrets").
+				Where("job_id = ? AND agent_id = ? AND status IN ?", chunk.JobId, chunk.AgentId, runningJobStatuses).
+				First(&rec).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("[Report] ⚠️ 可疑输出已忽略: Agent %s 上报的任务 %s 不存在、不属于它或已经结束", chunk.AgentId, chunk.JobId)
+				return stream.SendAndClose(&pb.ReportJobResp{Received: false})
+			}
+			if err != nil {
+				log.Printf("[DB] 查询任务记录失败: %v", err)
+				return status.Error(codes.Unavailable, "job store unavailable")
+			}
+			job = &rec
+			if len(rec.Secrets) > 0 {
+				secrets = s.secretValues(ctx, &rec)
+			}
+		} else if chunk.JobId != job.JobID {
+			return status.Error(codes.InvalidArgument, "one output stream carries one job")
+		}
+
+		if raw.Len()+len(chunk.Data) > liveOutputLimit {
+			log.Printf("[Report] 任务 %s 的实时输出超过 %d 字节, 之后只保存最终结果", job.JobID, liveOutputLimit)
+			return status.Errorf(codes.ResourceExhausted, "live output is limited to %d bytes per job", liveOutputLimit)
+		}
+		raw.WriteString(chunk.Data)
+		redacted := s.Redactor.Redact(redact.Values(raw.String(), secrets...))
+		if !strings.HasPrefix(redacted, stored) {
+			// 新的分片让已经存下的输出也命中了脱敏规则, 删掉实时输出, 只保留最终结果
+			log.Printf("[Report] ⚠️ 任务 %s 的实时输出跨分片命中脱敏规则, 已删除实时输出", job.JobID)
+			s.DB.Where("job_id = ?", job.JobID).Delete(&JobOutputChunk{})
+			return status.Error(codes.FailedPrecondition, "live output dropped by redaction")
+		}
+		data := redacted[len(stored):]
+		stored = redacted
+		row := JobOutputChunk{
+			JobID:   job.JobID,
+			Seq:     chunk.Seq,
+			Project: job.Project,
+			Data:    data,
+		}
+		if err := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+			log.Printf("[DB] 保存任务输出失败: %v", err)
+			return status.Error(codes.Unavailable, "job store unavailable")
+		}
+	}
+}
+
+// JobOutputPage 一页输出分片. Finished 表示任务已经结束并且这一页已经到头, 不会再有新的分片.
+type JobOutputPage struct {
+	Chunks   []JobOutputChunk `json:"chunks"`
+	Status   string           `json:"status"`
+	Finished bool             `json:"finished"`
+}
+
+// JobOutput 返回 seq 大于 after 的输出分片和任务当前的状态. 任务必须在 ctx 限定的项目里.
+// Agent 在汇报结束状态之前关闭输出流, 所以先查状态再查分片, 看到终态时分片已经全部落库.
+func (s *SentinelServer) JobOutput(ctx context.Context, jobID string, after int64, limit int) (*JobOutputPage, error) {
+	record, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultOutputPage
+	}
+	limit = min(limit, maxOutputPage)
+	page := &JobOutputPage{Chunks: []JobOutputChunk{}, Status: record.Status}
+	err = s.DB.WithContext(ctx).Where("job_id = ? AND seq > ?", jobID, after).
+		Order("seq").Limit(limit).Find(&page.Chunks).Error
+	if err != nil {
+		return nil, err
+	}
+	// Agent 能汇报的状态都是终态
+	page.Finished = reportableStatuses[record.Status] && len(page.Chunks) < limit
+	return page, nil
+}
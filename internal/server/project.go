@@ -0,0 +1,417 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/internal/events"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultProject 启用多项目之前的 Agent / 任务 / 用户都归到这里, 不带 enrollment token 注册的 Agent 也进这里
+const DefaultProject = "default"
+
+var (
+	ErrInvalidProject     = errors.New("invalid project")
+	ErrProjectExists      = errors.New("project already exists")
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrProjectNotEmpty    = errors.New("project still has agents")
+	ErrMemberNotFound     = errors.New("project member not found")
+	ErrAgentNotFound      = errors.New("agent not found")
+	ErrEnrollmentRequired = errors.New("agent must enroll with a project enrollment token")
+	ErrInvalidEnrollment  = errors.New("invalid enrollment token")
+	ErrAlreadyEnrolled    = errors.New("agent is already enrolled in another project")
+	ErrCrossProjectWrite  = errors.New("record belongs to another project")
+)
+
+var projectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Project 租户. Agent 注册时凭项目的 enrollment token 加入, 任务跟着目标 Agent 归属同一个项目.
+// 库里只存 token 的 sha256, 明文只在创建和轮换时返回一次.
+type Project struct {
+	gorm.Model
+	Name            string `gorm:"uniqueIndex;size:64" json:"name"`
+	Description     string `json:"description"`
+	EnrollTokenHash string `gorm:"index;size:64" json:"-"`
+}
+
+// ProjectMember 用户在某个项目里的角色, 取值和全局角色相同
+type ProjectMember struct {
+	gorm.Model
+	Project string `gorm:"uniqueIndex:idx_project_member;size:64" json:"project"`
+	User    string `gorm:"column:user_name;uniqueIndex:idx_project_member;size:191" json:"user"`
+	Role    string `gorm:"size:32" json:"role"`
+}
+
+type projectKey struct{}
+
+// WithProject 把查询限定在 project 里. 带着这个 ctx 的查询 / 更新 / 删除会自动加上 project = ? 条件,
+// 新建的记录自动归到 project, 见 RegisterProjectScope.
+func WithProject(ctx context.Context, project string) context.Context {
+	return context.WithValue(ctx, projectKey{}, project)
+}
+
+// ProjectFromContext 没有限定项目 (系统任务、全局管理接口) 时 ok 为 false
+func ProjectFromContext(ctx context.Context) (string, bool) {
+	project, ok := ctx.Value(projectKey{}).(string)
+	return project, ok
+}
+
+// withoutProject 去掉 ctx 里的项目限制, 用于需要跨项目统计的内部查询 (例如用户配额)
+func withoutProject(ctx context.Context) context.Context {
+	return context.WithValue(ctx, projectKey{}, nil)
+}
+
+// 按项目隔离的表, 它们都有 Project 字段
+var projectScopedModels = map[reflect.Type]bool{
+	reflect.TypeOf(AgentModel{}):     true,
+	reflect.TypeOf(JobRecord{}):      true,
+	reflect.TypeOf(JobTerm{}):        true,
+	reflect.TypeOf(JobOutputChunk{}): true,
+	reflect.TypeOf(AgentMetric{}):    true,
+}
+
+// RegisterProjectScope 在 gorm 的回调链上挂项目隔离: ctx 里带了项目时, 对隔离表的读写一律限定在该项目内.
+// 隔离放在查询层, 某个接口漏写了条件也查不到别的项目的数据.
+func RegisterProjectScope(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Query().Before("gorm:query").Register("sentinel:project_scope", scopeProject),
+		cb.Row().Before("gorm:row").Register("sentinel:project_scope", scopeProject),
+		cb.Update().Before("gorm:update").Register("sentinel:project_scope", scopeProject),
+		cb.Delete().Before("gorm:delete").Register("sentinel:project_scope", scopeProject),
+		cb.Create().Before("gorm:create").Register("sentinel:project_scope", assignProject),
+	)
+}
+
+func projectField(db *gorm.DB) (string, bool) {
+	project, ok := ProjectFromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil || !projectScopedModels[db.Statement.Schema.ModelType] {
+		return "", false
+	}
+	return project, true
+}
+
+func scopeProject(db *gorm.DB) {
+	project, ok := projectField(db)
+	if !ok {
+		return
+	}
+	// 已有的条件整体加括号再 AND 上项目, 防止 a OR b AND project = ? 这种优先级问题
+	stmt := db.Statement
+	where := clause.Where{}
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if w, ok := c.Expression.(clause.Where); ok && len(w.Exprs) > 0 {
+			where.Exprs = []clause.Expression{clause.And(w.Exprs...)}
+		}
+	}
+	where.Exprs = append(where.Exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "project"}, Value: project})
+	c := stmt.Clauses["WHERE"]
+	c.Name, c.Expression = "WHERE", where
+	stmt.Clauses["WHERE"] = c
+}
+
+func assignProject(db *gorm.DB) {
+	project, ok := projectField(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("Project")
+	set := func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		if rv.Kind() != reflect.Struct {
+			return
+		}
+		v, zero := field.ValueOf(db.Statement.Context, rv)
+		switch {
+		case zero:
+			db.AddError(field.Set(db.Statement.Context, rv, project))
+		case v != project:
+			db.AddError(fmt.Errorf("%w: %v", ErrCrossProjectWrite, v))
+		}
+	}
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			set(rv.Index(i))
+		}
+	default:
+		set(rv)
+	}
+}
+
+// CreateProject 返回新项目和明文 enrollment token
+func (s *SentinelServer) CreateProject(ctx context.Context, name, description string) (*Project, string, error) {
+	if !projectNamePattern.MatchString(name) {
+		return nil, "", fmt.Errorf("%w: name must match %s", ErrInvalidProject, projectNamePattern)
+	}
+	token, err := newToken("sne_")
+	if err != nil {
+		return nil, "", err
+	}
+	p := &Project{Name: name, Description: description, EnrollTokenHash: hashToken(token)}
+	var n int64
+	if err := s.DB.WithContext(ctx).Model(&Project{}).Where("name = ?", name).Count(&n).Error; err != nil {
+		return nil, "", err
+	}
+	if n > 0 {
+		return nil, "", ErrProjectExists
+	}
+	if err := s.DB.WithContext(ctx).Create(p).Error; err != nil {
+		return nil, "", err
+	}
+	return p, token, nil
+}
+
+// ListProjects admin 看到全部项目, 其他用户只看到自己所在的项目
+func (s *SentinelServer) ListProjects(ctx context.Context, u *User) ([]Project, error) {
+	var projects []Project
+	q := s.DB.WithContext(ctx).Order("name")
+	if !u.HasRole(RoleAdmin) {
+		q = q.Where("name IN (?)", s.DB.Model(&ProjectMember{}).Select("project").Where("user_name = ?", u.Name))
+	}
+	err := q.Find(&projects).Error
+	return projects, err
+}
+
+func (s *SentinelServer) findProject(ctx context.Context, name string) (*Project, error) {
+	var p Project
+	err := s.DB.WithContext(ctx).Where("name = ?", name).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeleteProject 还有 Agent 的项目不能删, 先把 Agent 下线清理掉; 任务记录保留到过期清理
+func (s *SentinelServer) DeleteProject(ctx context.Context, name string) error {
+	if name == DefaultProject {
+		return fmt.Errorf("%w: the default project cannot be deleted", ErrInvalidProject)
+	}
+	var n int64
+	if err := s.DB.WithContext(ctx).Model(&AgentModel{}).Where("project = ?", name).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrProjectNotEmpty
+	}
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("name = ?", name).Delete(&Project{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrProjectNotFound
+		}
+		return tx.Unscoped().Where("project = ?", name).Delete(&ProjectMember{}).Error
+	})
+}
+
+// RotateEnrollmentToken 旧 token 立即失效; 已经加入的 Agent 不受影响
+func (s *SentinelServer) RotateEnrollmentToken(ctx context.Context, name string) (string, error) {
+	token, err := newToken("sne_")
+	if err != nil {
+		return "", err
+	}
+	res := s.DB.WithContext(ctx).Model(&Project{}).Where("name = ?", name).Update("enroll_token_hash", hashToken(token))
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", ErrProjectNotFound
+	}
+	return token, nil
+}
+
+func (s *SentinelServer) ListMembers(ctx context.Context, project string) ([]ProjectMember, error) {
+	var members []ProjectMember
+	err := s.DB.WithContext(ctx).Where("project = ?", project).Order("user_name").Find(&members).Error
+	return members, err
+}
+
+// PutMember 把用户加入项目或修改其角色
+func (s *SentinelServer) PutMember(ctx context.Context, project, user, role string) (*ProjectMember, error) {
+	if roleRank[role] == 0 {
+		return nil, fmt.Errorf("%w: role must be one of viewer, operator, approver, admin", ErrInvalidUser)
+	}
+	if _, err := s.findProject(ctx, project); err != nil {
+		return nil, err
+	}
+	var n int64
+	if err := s.DB.WithContext(ctx).Model(&User{}).Where("name = ?", user).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	m := &ProjectMember{Project: project, User: user, Role: role}
+	var existing ProjectMember
+	err := s.DB.WithContext(ctx).Where("project = ? AND user_name = ?", project, user).First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.DB.WithContext(ctx).Create(m).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		m.Model = existing.Model
+		if err := s.DB.WithContext(ctx).Save(m).Error; err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (s *SentinelServer) DeleteMember(ctx context.Context, project, user string) error {
+	res := s.DB.WithContext(ctx).Unscoped().Where("project = ? AND user_name = ?", project, user).Delete(&ProjectMember{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// ProjectRole 用户在项目里的角色. 全局 admin 在所有存在的项目里都是 admin; 不是成员返回空串.
+func (s *SentinelServer) ProjectRole(ctx context.Context, u *User, project string) (string, error) {
+	if u.HasRole(RoleAdmin) {
+		if _, err := s.findProject(ctx, project); err != nil {
+			return "", err
+		}
+		return RoleAdmin, nil
+	}
+	var m ProjectMember
+	err := s.DB.WithContext(ctx).Where("project = ? AND user_name = ?", project, u.Name).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return m.Role, nil
+}
+
+// ListAgents ctx 限定项目里的全部 Agent
+func (s *SentinelServer) ListAgents(ctx context.Context) ([]AgentModel, error) {
+	var agents []AgentModel
+	err := s.DB.WithContext(ctx).Find(&agents).Error
+	return agents, err
+}
+
+// GetAgent 受 ctx 里的项目限制, 别的项目的 Agent 同样返回 ErrAgentNotFound
+func (s *SentinelServer) GetAgent(ctx context.Context, agentID string) (*AgentModel, error) {
+	var agent AgentModel
+	err := s.DB.WithContext(ctx).Where("agent_id = ?", agentID).First(&agent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAgentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// enrollmentProject 按注册请求确定 Agent 所属的项目. current 为 Agent 当前所在项目, 新 Agent 为空.
+// 已经加入项目的 Agent 重新注册时不需要再带 token; 带了别的项目的 token 只允许从 default 项目迁出.
+func (s *SentinelServer) enrollmentProject(ctx context.Context, current, token string) (string, error) {
+	if token == "" {
+		if current != "" {
+			return current, nil
+		}
+		if s.RequireEnrollment {
+			return "", ErrEnrollmentRequired
+		}
+		return DefaultProject, nil
+	}
+	var p Project
+	err := s.DB.WithContext(ctx).Where("enroll_token_hash = ?", hashToken(token)).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrInvalidEnrollment
+	}
+	if err != nil {
+		return "", err
+	}
+	if current != "" && current != DefaultProject && current != p.Name {
+		return "", fmt.Errorf("%w (%s)", ErrAlreadyEnrolled, current)
+	}
+	return p.Name, nil
+}
+
+// abandonProjectJobs Agent 换了项目, 原项目里还没派发的任务不能再发给它, 直接判失败
+func (s *SentinelServer) abandonProjectJobs(ctx context.Context, agentID, project, reason string) {
+	var records []JobRecord
+	err := s.DB.WithContext(ctx).
+		Where("agent_id = ? AND project = ? AND status IN ?", agentID, project, []string{JobStatusQueued, JobStatusPendingApproval}).
+		Find(&records).Error
+	if err != nil {
+		log.Printf("[Project] 查询 %s 在项目 %s 里的待派发任务失败: %v", agentID, project, err)
+		return
+	}
+	for i := range records {
+		rec := &records[i]
+		now := time.Now()
+		res := s.DB.WithContext(ctx).Model(&JobRecord{}).
+			Where("job_id = ? AND status IN ?", rec.JobID, []string{JobStatusQueued, JobStatusPendingApproval}).
+			Select("status", "result", "executed_at").
+			Updates(&JobRecord{Status: JobStatusFailed, Result: reason, ExecutedAt: now})
+		if res.Error != nil || res.RowsAffected != 1 {
+			continue
+		}
+		rec.Status, rec.Result, rec.ExecutedAt = JobStatusFailed, reason, now
+		s.emitJobEvent(events.TypeJobFailed, rec)
+	}
+	if len(records) > 0 {
+		log.Printf("[Project] %s 离开项目 %s, %d 个未派发的任务已判失败", agentID, project, len(records))
+	}
+}
+
+// EnsureDefaultProject 创建 default 项目, 并把启用多项目之前的 Agent 和任务归进去.
+// default 项目第一次创建时, 已有的非 admin 用户按原来的全局角色加入 default, 升级后能访问的范围不变.
+func (s *SentinelServer) EnsureDefaultProject(ctx context.Context) error {
+	if _, err := s.findProject(ctx, DefaultProject); errors.Is(err, ErrProjectNotFound) {
+		err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&Project{Name: DefaultProject, Description: "启用多项目之前的数据"}).Error; err != nil {
+				return err
+			}
+			var users []User
+			if err := tx.Where("role <> ?", RoleAdmin).Find(&users).Error; err != nil {
+				return err
+			}
+			for _, u := range users {
+				if err := tx.Create(&ProjectMember{Project: DefaultProject, User: u.Name, Role: u.Role}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("[Project] 已创建 %s 项目", DefaultProject)
+	} else if err != nil {
+		return err
+	}
+
+	for _, model := range []any{&AgentModel{}, &JobRecord{}} {
+		res := s.DB.WithContext(ctx).Model(model).Where("project = ?", "").UpdateColumn("project", DefaultProject)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			log.Printf("[Project] %d 条没有项目的 %T 已归入 %s", res.RowsAffected, model, DefaultProject)
+		}
+	}
+	return nil
+}
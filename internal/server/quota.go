@@ -0,0 +1,313 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stywzn/Go-Cloud-Compute/pkg/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 配额作用范围: API 用户或项目
+const (
+	QuotaScopeUser    = "user"
+	QuotaScopeProject = "project"
+)
+
+const (
+	rateWindow = time.Minute
+	// 并发数什么时候降下来没法预估, 给一个固定的建议重试间隔
+	concurrencyRetryHint = 15 * time.Second
+)
+
+var (
+	ErrQuotaExceeded   = errors.New("quota exceeded")
+	ErrInvalidQuota    = errors.New("invalid quota")
+	ErrQuotaNotFound   = errors.New("quota override not found")
+	ErrNoAgentsMatched = errors.New("selector matched no agents")
+)
+
+// 还没结束的任务, 计入并发配额
+var unfinishedJobStatuses = []string{JobStatusPendingApproval, JobStatusQueued, JobStatusDispatched, JobStatusCancelling}
+
+// QuotaLimits 0 或负数表示不限制
+type QuotaLimits struct {
+	JobsPerMinute     int `json:"jobs_per_minute"`
+	MaxConcurrentJobs int `json:"max_concurrent_jobs"`
+	MaxFanOut         int `json:"max_fan_out"`
+}
+
+// override 单独配置里非 0 的字段覆盖默认值, -1 表示对这个用户 / 项目不限制
+func (l QuotaLimits) override(o QuotaLimits) QuotaLimits {
+	if o.JobsPerMinute != 0 {
+		l.JobsPerMinute = o.JobsPerMinute
+	}
+	if o.MaxConcurrentJobs != 0 {
+		l.MaxConcurrentJobs = o.MaxConcurrentJobs
+	}
+	if o.MaxFanOut != 0 {
+		l.MaxFanOut = o.MaxFanOut
+	}
+	return l
+}
+
+// QuotaModel 个别用户 / 项目的配额调整, 字段含义见 QuotaLimits.override
+type QuotaModel struct {
+	gorm.Model
+	Scope       string `gorm:"uniqueIndex:idx_quota_target;size:16" json:"scope"`
+	Target      string `gorm:"uniqueIndex:idx_quota_target;size:191" json:"target"`
+	QuotaLimits `gorm:"embedded"`
+}
+
+func (QuotaModel) TableName() string { return "quotas" }
+
+func (m *QuotaModel) Validate() error {
+	if m.Scope != QuotaScopeUser && m.Scope != QuotaScopeProject {
+		return fmt.Errorf("%w: scope must be %q or %q", ErrInvalidQuota, QuotaScopeUser, QuotaScopeProject)
+	}
+	if m.Target == "" {
+		return fmt.Errorf("%w: target is required", ErrInvalidQuota)
+	}
+	if m.JobsPerMinute < -1 || m.MaxConcurrentJobs < -1 || m.MaxFanOut < -1 {
+		return fmt.Errorf("%w: limits must be -1 (unlimited), 0 (default) or positive", ErrInvalidQuota)
+	}
+	return nil
+}
+
+// QuotaError 超出配额. RetryAfter 为 0 表示等待也不会放行 (例如 fan-out 超过上限).
+type QuotaError struct {
+	Scope      string // user / project
+	Target     string // 用户名或项目名
+	Limit      string // jobs_per_minute / max_concurrent_jobs / max_fan_out
+	Max        int
+	RetryAfter time.Duration
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s %s exceeded %s (%d)", e.Scope, e.Target, e.Limit, e.Max)
+}
+
+func (e *QuotaError) Unwrap() error { return ErrQuotaExceeded }
+
+// RateLimiter 按 key 计数的滑动窗口. 多实例部署用 Redis 实现 (pkg/db.RateLimiter), 单实例用进程内实现.
+type RateLimiter interface {
+	AllowN(ctx context.Context, key string, n, limit int, window time.Duration) (db.RateResult, error)
+	Count(ctx context.Context, key string, window time.Duration) (int, error)
+}
+
+// localRateLimiter 单实例模式下的 RateLimiter
+type localRateLimiter struct {
+	mu   sync.Mutex
+	hits map[string][]time.Time
+}
+
+func newLocalRateLimiter() *localRateLimiter {
+	return &localRateLimiter{hits: make(map[string][]time.Time)}
+}
+
+// prune 调用方持有锁
+func (l *localRateLimiter) prune(key string, now time.Time, window time.Duration) []time.Time {
+	hits := l.hits[key]
+	i := sort.Search(len(hits), func(i int) bool { return hits[i].After(now.Add(-window)) })
+	hits = hits[i:]
+	if len(hits) == 0 {
+		delete(l.hits, key)
+	} else {
+		l.hits[key] = hits
+	}
+	return hits
+}
+
+func (l *localRateLimiter) AllowN(_ context.Context, key string, n, limit int, window time.Duration) (db.RateResult, error) {
+	if limit <= 0 {
+		return db.RateResult{Allowed: true, Remaining: -1}, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	hits := l.prune(key, now, window)
+	if len(hits)+n <= limit {
+		for range n {
+			hits = append(hits, now)
+		}
+		l.hits[key] = hits
+		return db.RateResult{Allowed: true, Remaining: limit - len(hits)}, nil
+	}
+	retry := window
+	if need := len(hits) + n - limit; need <= len(hits) {
+		retry = hits[need-1].Add(window).Sub(now)
+	}
+	return db.RateResult{RetryAfter: retry}, nil
+}
+
+func (l *localRateLimiter) Count(_ context.Context, key string, window time.Duration) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.prune(key, time.Now(), window)), nil
+}
+
+func quotaKey(scope, target string) string { return scope + ":" + target }
+
+// EffectiveQuota 默认配额叠加单独调整之后的结果
+func (s *SentinelServer) EffectiveQuota(ctx context.Context, scope, target string) (QuotaLimits, error) {
+	limits := s.UserQuota
+	if scope == QuotaScopeProject {
+		limits = s.ProjectQuota
+	}
+	var m QuotaModel
+	err := s.DB.WithContext(ctx).Where("scope = ? AND target = ?", scope, target).First(&m).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return limits, nil
+	case err != nil:
+		return limits, err
+	}
+	return limits.override(m.QuotaLimits), nil
+}
+
+// QuotaLock 并发配额的行锁, 每个用户 / 项目一行. 提交任务的事务先锁住它再统计未结束的任务,
+// 任务写入之后才提交, 同一个用户 / 项目的并发提交在这里排队, 不会都按旧的计数放行.
+type QuotaLock struct {
+	Scope  string `gorm:"primaryKey;size:16"`
+	Target string `gorm:"primaryKey;size:191"`
+}
+
+// lockQuota 锁一直持有到 tx 结束
+func lockQuota(tx *gorm.DB, scope, target string) error {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&QuotaLock{Scope: scope, Target: target}).Error
+	if err != nil {
+		return err
+	}
+	var row QuotaLock
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("scope = ? AND target = ?", scope, target).First(&row).Error
+}
+
+// unfinishedJobs 按提交者或项目统计还没结束的任务. 用户配额跨项目计算, 所以不受 ctx 里的项目限制.
+func unfinishedJobs(ctx context.Context, tx *gorm.DB, scope, target string) (int, error) {
+	column := "submitted_by"
+	if scope == QuotaScopeProject {
+		column = "project"
+	}
+	var n int64
+	err := tx.WithContext(withoutProject(ctx)).Model(&JobRecord{}).
+		Where(column+" = ? AND status IN ?", target, unfinishedJobStatuses).Count(&n).Error
+	return int(n), err
+}
+
+// reserveQuota 在提交任务的事务 tx 里检查 fan-out 和并发上限, 再占用每分钟的名额.
+// 并发上限按 QuotaLock 加锁计数, 调用方在同一个事务里写入任务. 每分钟的名额不在数据库里,
+// 项目被拒或事务回滚时已占的名额不退回, 宁可多限一点. 用户先锁、项目后锁, 多个项目按名字顺序调用.
+func (s *SentinelServer) reserveQuota(ctx context.Context, tx *gorm.DB, user, project string, n int) error {
+	targets := []struct{ scope, name string }{{QuotaScopeUser, user}, {QuotaScopeProject, project}}
+	limits := make([]QuotaLimits, len(targets))
+	for i, t := range targets {
+		l, err := s.EffectiveQuota(ctx, t.scope, t.name)
+		if err != nil {
+			return err
+		}
+		limits[i] = l
+		if l.MaxFanOut > 0 && n > l.MaxFanOut {
+			return &QuotaError{Scope: t.scope, Target: t.name, Limit: "max_fan_out", Max: l.MaxFanOut}
+		}
+		if l.MaxConcurrentJobs > 0 {
+			if err := lockQuota(tx, t.scope, t.name); err != nil {
+				return err
+			}
+			running, err := unfinishedJobs(ctx, tx, t.scope, t.name)
+			if err != nil {
+				return err
+			}
+			if running+n > l.MaxConcurrentJobs {
+				e := &QuotaError{Scope: t.scope, Target: t.name, Limit: "max_concurrent_jobs", Max: l.MaxConcurrentJobs}
+				if n <= l.MaxConcurrentJobs {
+					e.RetryAfter = concurrencyRetryHint
+				}
+				return e
+			}
+		}
+	}
+	for i, t := range targets {
+		res, err := s.RateLimiter.AllowN(ctx, quotaKey(t.scope, t.name), n, limits[i].JobsPerMinute, rateWindow)
+		if err != nil {
+			return err
+		}
+		if !res.Allowed {
+			e := &QuotaError{Scope: t.scope, Target: t.name, Limit: "jobs_per_minute", Max: limits[i].JobsPerMinute}
+			if n <= limits[i].JobsPerMinute {
+				e.RetryAfter = res.RetryAfter
+			}
+			return e
+		}
+	}
+	return nil
+}
+
+// QuotaUsage 某个用户或项目当前的配额和用量
+type QuotaUsage struct {
+	Scope          string      `json:"scope"`
+	Target         string      `json:"target"`
+	Limits         QuotaLimits `json:"limits"`
+	JobsLastMinute int         `json:"jobs_last_minute"`
+	UnfinishedJobs int         `json:"unfinished_jobs"`
+}
+
+func (s *SentinelServer) QuotaUsage(ctx context.Context, scope, target string) (*QuotaUsage, error) {
+	limits, err := s.EffectiveQuota(ctx, scope, target)
+	if err != nil {
+		return nil, err
+	}
+	u := &QuotaUsage{Scope: scope, Target: target, Limits: limits}
+	if u.JobsLastMinute, err = s.RateLimiter.Count(ctx, quotaKey(scope, target), rateWindow); err != nil {
+		return nil, err
+	}
+	if u.UnfinishedJobs, err = unfinishedJobs(ctx, s.DB, scope, target); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// PutQuota 新建或覆盖一条配额调整
+func (s *SentinelServer) PutQuota(ctx context.Context, m *QuotaModel) (*QuotaModel, error) {
+	m.Model = gorm.Model{}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	var existing QuotaModel
+	err := s.DB.WithContext(ctx).Where("scope = ? AND target = ?", m.Scope, m.Target).First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.DB.WithContext(ctx).Create(m).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		m.ID, m.CreatedAt = existing.ID, existing.CreatedAt
+		if err := s.DB.WithContext(ctx).Save(m).Error; err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (s *SentinelServer) DeleteQuota(ctx context.Context, scope, target string) error {
+	res := s.DB.WithContext(ctx).Unscoped().Where("scope = ? AND target = ?", scope, target).Delete(&QuotaModel{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrQuotaNotFound
+	}
+	return nil
+}
+
+func (s *SentinelServer) ListQuotas(ctx context.Context) ([]QuotaModel, error) {
+	var rows []QuotaModel
+	err := s.DB.WithContext(ctx).Order("scope, target").Find(&rows).Error
+	return rows, err
+}
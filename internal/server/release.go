@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"github.com/stywzn/Go-Cloud-Compute/internal/cluster"
+	"github.com/stywzn/Go-Cloud-Compute/pkg/jobsign"
+	"gorm.io/gorm"
+)
+
+// DownloadAgent 每次发送的块大小
+const agentChunkSize = 64 << 10
+
+var (
+	ErrInvalidRelease  = errors.New("invalid agent release")
+	ErrReleaseExists   = errors.New("agent release already exists")
+	ErrReleaseNotFound = errors.New("agent release not found")
+)
+
+// 版本号和平台名会拼进文件路径, 只允许安全字符
+var releaseNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// AgentRelease 服务端托管的 Agent 二进制. 同一个版本 + 平台发布后不可覆盖, 否则校验和就失去意义.
+type AgentRelease struct {
+	gorm.Model
+	Version string `gorm:"uniqueIndex:idx_agent_release;size:64" json:"version"`
+	OS      string `gorm:"uniqueIndex:idx_agent_release;size:32" json:"os"`
+	Arch    string `gorm:"uniqueIndex:idx_agent_release;size:32" json:"arch"`
+	SHA256  string `gorm:"size:64" json:"sha256"`
+	Size    int64  `json:"size"`
+	// Signature 上传时用任务签名私钥签的, 见 jobsign.ReleaseMessage; 没配私钥时为空, 配置了公钥的 Agent 不会安装
+	Signature []byte `gorm:"type:varbinary(64)" json:"-"`
+}
+
+// releasePath 二进制的位置由版本和平台推出来, 不信任数据库里的路径. 名字不合法时返回空串.
+func (s *SentinelServer) releasePath(version, goos, arch string) string {
+	for _, v := range []string{version, goos, arch} {
+		if !releaseNamePattern.MatchString(v) {
+			return ""
+		}
+	}
+	return filepath.Join(s.ReleaseDir, version, fmt.Sprintf("sentinel-agent-%s-%s", goos, arch))
+}
+
+// PublishRelease 把二进制写进 ReleaseDir 并记录校验和
+func (s *SentinelServer) PublishRelease(ctx context.Context, version, goos, arch string, r io.Reader) (*AgentRelease, error) {
+	for _, v := range []string{version, goos, arch} {
+		if !releaseNamePattern.MatchString(v) {
+			return nil, fmt.Errorf("%w: %q is not a valid version / os / arch", ErrInvalidRelease, v)
+		}
+	}
+	var count int64
+	s.DB.WithContext(ctx).Model(&AgentRelease{}).
+		Where("version = ? AND os = ? AND arch = ?", version, goos, arch).Count(&count)
+	if count > 0 {
+		return nil, ErrReleaseExists
+	}
+
+	path := s.releasePath(version, goos, arch)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("%w: empty binary", ErrInvalidRelease)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	rel := &AgentRelease{
+		Version: version,
+		OS:      goos,
+		Arch:    arch,
+		SHA256:  hex.EncodeToString(h.Sum(nil)),
+		Size:    size,
+	}
+	if s.SigningKey != nil {
+		rel.Signature = jobsign.SignRelease(s.SigningKey, version, goos, arch, rel.SHA256, size)
+	}
+	if err := s.DB.WithContext(ctx).Create(rel).Error; err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	log.Printf("[Release] 已发布 Agent %s (%s/%s) | %d bytes | sha256 %s", version, goos, arch, size, rel.SHA256)
+	return rel, nil
+}
+
+func (s *SentinelServer) ListReleases(ctx context.Context) ([]AgentRelease, error) {
+	var rels []AgentRelease
+	err := s.DB.WithContext(ctx).Order("id DESC").Find(&rels).Error
+	return rels, err
+}
+
+func (s *SentinelServer) findRelease(ctx context.Context, version, goos, arch string) (*AgentRelease, error) {
+	var rel AgentRelease
+	err := s.DB.WithContext(ctx).
+		Where("version = ? AND os = ? AND arch = ?", version, goos, arch).First(&rel).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReleaseNotFound
+	}
+	return &rel, err
+}
+
+// RolloutSkip 没能安排升级的 Agent 以及原因
+type RolloutSkip struct {
+	AgentID string `json:"agent_id"`
+	Reason  string `json:"reason"`
+}
+
+type RolloutResult struct {
+	Version  string        `json:"version"`
+	Targeted []string      `json:"targeted"`
+	Skipped  []RolloutSkip `json:"skipped"`
+}
+
+// RolloutRelease 给命中 selector 的 Agent 设置目标版本, 心跳流发现后下发 AgentUpdate.
+// 平台没有对应二进制的、已经是该版本的 Agent 会被跳过.
+func (s *SentinelServer) RolloutRelease(ctx context.Context, version string, sel Selector) (*RolloutResult, error) {
+	agents, err := s.SelectAgents(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	var rels []AgentRelease
+	if err := s.DB.WithContext(ctx).Where("version = ?", version).Find(&rels).Error; err != nil {
+		return nil, err
+	}
+	if len(rels) == 0 {
+		return nil, ErrReleaseNotFound
+	}
+	platforms := make(map[string]bool)
+	for _, r := range rels {
+		platforms[r.OS+"/"+r.Arch] = true
+	}
+
+	res := &RolloutResult{Version: version, Targeted: []string{}, Skipped: []RolloutSkip{}}
+	for _, a := range agents {
+		switch {
+		case a.Version == version:
+			res.Skipped = append(res.Skipped, RolloutSkip{a.AgentID, "already on this version"})
+		case !platforms[a.OS+"/"+a.Arch]:
+			res.Skipped = append(res.Skipped, RolloutSkip{a.AgentID, fmt.Sprintf("no binary for %s/%s", a.OS, a.Arch)})
+		default:
+			res.Targeted = append(res.Targeted, a.AgentID)
+		}
+	}
+	if len(res.Targeted) == 0 {
+		return res, nil
+	}
+
+	err = s.DB.WithContext(ctx).Model(&AgentModel{}).Where("agent_id IN ?", res.Targeted).
+		Updates(map[string]any{"target_version": version, "update_error": ""}).Error
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[Release] 升级到 %s: 安排 %d 台, 跳过 %d 台", version, len(res.Targeted), len(res.Skipped))
+	for _, id := range res.Targeted {
+		s.route(ctx, cluster.Message{Kind: cluster.KindRefresh, AgentID: id})
+	}
+	return res, nil
+}
+
+// pendingUpdate Agent 的目标版本和当前版本不一致且有对应平台的二进制时返回升级指令
+func (s *SentinelServer) pendingUpdate(ctx context.Context, agentID string) (*pb.AgentUpdate, error) {
+	var agent AgentModel
+	if err := s.DB.WithContext(ctx).Where("agent_id = ?", agentID).First(&agent).Error; err != nil {
+		return nil, err
+	}
+	if agent.TargetVersion == "" || agent.TargetVersion == agent.Version {
+		return nil, nil
+	}
+	rel, err := s.findRelease(ctx, agent.TargetVersion, agent.OS, agent.Arch)
+	if err != nil {
+		return nil, err
+	}
+	if s.SigningKey != nil && len(rel.Signature) == 0 {
+		log.Printf("[Release] ⚠️ %s (%s/%s) 上传时还没有配置签名私钥, 配置了公钥的 Agent 会拒绝安装, 请重新上传",
+			rel.Version, rel.OS, rel.Arch)
+	}
+	return &pb.AgentUpdate{Version: rel.Version, Sha256: rel.SHA256, Size: rel.Size, Signature: rel.Signature}, nil
+}
+
+func (s *SentinelServer) DownloadAgent(req *pb.DownloadAgentReq, stream pb.SentinelService_DownloadAgentServer) error {
+	rel, err := s.findRelease(stream.Context(), req.Version, req.Os, req.Arch)
+	if err != nil {
+		return err
+	}
+	path := s.releasePath(rel.Version, rel.OS, rel.Arch)
+	if path == "" {
+		return ErrInvalidRelease
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	log.Printf("[Release] 开始传输 Agent %s (%s/%s)", rel.Version, rel.OS, rel.Arch)
+	buf := make([]byte, agentChunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.AgentChunk{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
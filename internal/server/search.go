@@ -0,0 +1,424 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stywzn/Go-Cloud-Compute/internal/envelope"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidSearch = errors.New("invalid search")
+
+const (
+	minTermLen     = 2
+	maxTermLen     = 64
+	maxTermsPerJob = 5000 // 输出里更靠后的新词不再收录, 搜它们会漏掉这条任务
+
+	searchPageLimit = 20
+	maxSearchPage   = 100
+
+	snippetContext = 80 // 匹配处前后各带多少字节, 不跨行
+	maxSnippets    = 3
+
+	reindexBatchSize = 200
+)
+
+// JobTerm 任务输出的倒排索引, 每个 (任务, 词) 一行. 输出可能是加密存储的, 这里也只存词的摘要, 见 envelope.TermHasher.
+type JobTerm struct {
+	ID           uint      `gorm:"primaryKey"`
+	Term         string    `gorm:"size:32;index:idx_job_term_lookup,priority:1"`
+	Project      string    `gorm:"size:64;index:idx_job_term_lookup,priority:2"`
+	JobCreatedAt time.Time `gorm:"index:idx_job_term_lookup,priority:3"` // 冗余任务的创建时间, 时间过滤和翻页不用回表
+	JobID        string    `gorm:"size:191;index"`
+	AgentID      string    `gorm:"size:191"`
+}
+
+func (JobTerm) TableName() string { return "job_terms" }
+
+// searchTerms 把文本切成小写的词: 连续的字母、数字和下划线算一个词, 去重后按出现顺序返回
+func searchTerms(text string, limit int) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, w := range words {
+		if n := utf8.RuneCountInString(w); n < minTermLen || n > maxTermLen {
+			continue
+		}
+		w = strings.ToLower(w)
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w)
+		if len(terms) == limit {
+			break
+		}
+	}
+	return terms
+}
+
+// indexJob 用任务当前的输出重建它的索引行
+func indexJob(ctx context.Context, db *gorm.DB, r *JobRecord) error {
+	hash, err := envelope.TermHasher(ctx)
+	if err != nil {
+		return err
+	}
+	terms := searchTerms(r.Result, maxTermsPerJob)
+	rows := make([]JobTerm, len(terms))
+	for i, t := range terms {
+		rows[i] = JobTerm{Term: hash(t), Project: r.Project, JobCreatedAt: r.CreatedAt, JobID: r.JobID, AgentID: r.AgentID}
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", r.JobID).Delete(&JobTerm{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// indexJobOutput 汇报结果之后更新索引, 失败只影响搜索, 不影响汇报
+func (s *SentinelServer) indexJobOutput(ctx context.Context, r *JobRecord) {
+	if err := indexJob(ctx, s.DB, r); err != nil {
+		log.Printf("[Search] 索引任务 %s 的输出失败: %v", r.JobID, err)
+	}
+}
+
+// ReindexJobs 给所有有输出的任务重建索引, 返回处理的任务数. 升级后补历史数据, 或者启用加密之后执行一次.
+func ReindexJobs(ctx context.Context, db *gorm.DB) (int, error) {
+	var lastID uint
+	total := 0
+	for {
+		var rows []JobRecord
+		err := db.WithContext(ctx).Where("id > ? AND result <> ''", lastID).Order("id").Limit(reindexBatchSize).Find(&rows).Error
+		if err != nil {
+			return total, err
+		}
+		for i := range rows {
+			lastID = rows[i].ID
+			if err := indexJob(ctx, db, &rows[i]); err != nil {
+				return total, err
+			}
+			total++
+		}
+		if len(rows) < reindexBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// SearchQuery Phrase 和 Regex 至少给一个, 都给时两个条件都要满足
+type SearchQuery struct {
+	// Phrase 先按里面的词查索引, 再在原文里确认整句出现过 (整词匹配, 不区分大小写, 词之间的空白宽松匹配)
+	Phrase string
+	// Regex RE2 语法, 区分大小写 (需要时用 (?i)). 只有正则时用不上索引, 按时间倒序逐条扫描.
+	Regex   string
+	AgentID string
+	Since   time.Time
+	Until   time.Time
+	Cursor  string
+	Limit   int
+}
+
+// SnippetPart 片段按匹配与否切开, 前端把 Match 的部分高亮即可
+type SnippetPart struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+type Snippet struct {
+	Line  int           `json:"line"` // 片段所在行, 从 1 开始
+	Parts []SnippetPart `json:"parts"`
+}
+
+type SearchHit struct {
+	Job      JobView   `json:"job"`
+	Matches  int       `json:"matches"`
+	Snippets []Snippet `json:"snippets"`
+}
+
+// searchCursor 索引路径按 (任务创建时间, 任务 ID) 倒序翻页
+type searchCursor struct {
+	Time  time.Time `json:"t"`
+	JobID string    `json:"j"`
+}
+
+func (q *SearchQuery) compile() ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	phrase := strings.TrimSpace(q.Phrase)
+	if words := strings.Fields(phrase); len(words) > 0 {
+		for i, w := range words {
+			words[i] = regexp.QuoteMeta(w)
+		}
+		// 索引是按整词建的, 短语两头也按整词匹配. RE2 的 \b 只认 ASCII, 其他字符开头结尾的不加.
+		expr := `(?i)` + strings.Join(words, `\s+`)
+		if isASCIIWordByte(phrase[0]) {
+			expr = `\b` + expr
+		}
+		if isASCIIWordByte(phrase[len(phrase)-1]) {
+			expr += `\b`
+		}
+		res = append(res, regexp.MustCompile(expr))
+	}
+	if q.Regex != "" {
+		re, err := regexp.Compile(q.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSearch, err)
+		}
+		res = append(res, re)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%w: q or regex is required", ErrInvalidSearch)
+	}
+	return res, nil
+}
+
+func isASCIIWordByte(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// Search 在 ctx 限定的项目里搜索任务输出, 每页按任务创建时间倒序.
+// 和 ListJobs 一样, 一次请求最多检查 jobScanLimit 条候选, 没凑满一页也会带上 next.
+func (s *SentinelServer) Search(ctx context.Context, q SearchQuery) (hits []SearchHit, next string, err error) {
+	res, err := q.compile()
+	if err != nil {
+		return nil, "", err
+	}
+	if q.Limit <= 0 {
+		q.Limit = searchPageLimit
+	}
+	q.Limit = min(q.Limit, maxSearchPage)
+	match := func(r *JobRecord) bool {
+		for _, re := range res {
+			if !re.MatchString(r.Result) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var records []JobRecord
+	if terms := searchTerms(q.Phrase, -1); len(terms) > 0 {
+		records, next, err = s.searchIndex(ctx, q, terms, match)
+	} else {
+		// 没有可以查索引的词, 退回到逐条扫描
+		records, next, err = s.ListJobs(ctx, JobQuery{
+			AgentID: q.AgentID,
+			Since:   q.Since,
+			Until:   q.Until,
+			Sort:    defaultJobSort,
+			Cursor:  q.Cursor,
+			Limit:   q.Limit,
+			match:   match,
+		})
+		if errors.Is(err, ErrInvalidJobQuery) {
+			err = fmt.Errorf("%w: bad cursor", ErrInvalidSearch)
+		}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	hits = make([]SearchHit, len(records))
+	for i := range records {
+		hits[i] = SearchHit{Job: newJobView(&records[i], false)}
+		hits[i].Matches, hits[i].Snippets = snippets(records[i].Result, res)
+	}
+	return hits, next, nil
+}
+
+// searchIndex 先从索引里找出包含全部词的任务, 再解密输出逐条确认
+func (s *SentinelServer) searchIndex(ctx context.Context, q SearchQuery, terms []string, match func(*JobRecord) bool) ([]JobRecord, string, error) {
+	hash, err := envelope.TermHasher(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	hashes := make([]string, len(terms))
+	for i, t := range terms {
+		hashes[i] = hash(t)
+	}
+	var cur *searchCursor
+	if q.Cursor != "" {
+		if cur, err = decodeSearchCursor(q.Cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	type candidate struct {
+		JobID        string
+		JobCreatedAt time.Time
+	}
+	batch := max(q.Limit, jobScanBatch)
+	var jobs []JobRecord
+	for scanned := 0; ; {
+		db := s.DB.WithContext(ctx).Model(&JobTerm{}).Where("term IN ?", hashes)
+		if q.AgentID != "" {
+			db = db.Where("agent_id = ?", q.AgentID)
+		}
+		if !q.Since.IsZero() {
+			db = db.Where("job_created_at >= ?", q.Since)
+		}
+		if !q.Until.IsZero() {
+			db = db.Where("job_created_at < ?", q.Until)
+		}
+		if cur != nil {
+			db = db.Where("(job_created_at < ? OR (job_created_at = ? AND job_id < ?))", cur.Time, cur.Time, cur.JobID)
+		}
+		var cands []candidate
+		err := db.Select("job_id, job_created_at").Group("job_id, job_created_at").
+			Having("COUNT(DISTINCT term) = ?", len(hashes)).
+			Order("job_created_at DESC, job_id DESC").Limit(batch).Scan(&cands).Error
+		if err != nil {
+			return nil, "", err
+		}
+
+		ids := make([]string, len(cands))
+		for i, c := range cands {
+			ids[i] = c.JobID
+		}
+		var rows []JobRecord
+		if len(ids) > 0 {
+			if err := s.DB.WithContext(ctx).Where("job_id IN ?", ids).Find(&rows).Error; err != nil {
+				return nil, "", err
+			}
+		}
+		byID := make(map[string]*JobRecord, len(rows))
+		for i := range rows {
+			byID[rows[i].JobID] = &rows[i]
+		}
+		for _, c := range cands {
+			cur = &searchCursor{Time: c.JobCreatedAt, JobID: c.JobID}
+			// 任务记录已经被清理, 索引还没来得及删
+			r, ok := byID[c.JobID]
+			if !ok || !match(r) {
+				continue
+			}
+			jobs = append(jobs, *r)
+			if len(jobs) == q.Limit {
+				return jobs, cur.encode(), nil
+			}
+		}
+		if len(cands) < batch {
+			return jobs, "", nil
+		}
+		if scanned += len(cands); scanned >= jobScanLimit {
+			return jobs, cur.encode(), nil
+		}
+	}
+}
+
+func (c *searchCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSearchCursor(s string) (*searchCursor, error) {
+	var cur searchCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &cur)
+	}
+	if err != nil || cur.JobID == "" {
+		return nil, fmt.Errorf("%w: bad cursor", ErrInvalidSearch)
+	}
+	return &cur, nil
+}
+
+// snippets 返回匹配次数和前 maxSnippets 个高亮片段. 多个表达式的匹配合并在一起高亮, 空匹配不算.
+func snippets(text string, res []*regexp.Regexp) (int, []Snippet) {
+	var spans [][]int
+	for _, re := range res {
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			if m[0] < m[1] {
+				spans = append(spans, m)
+			}
+		}
+	}
+	slices.SortFunc(spans, func(a, b []int) int { return a[0] - b[0] })
+	var merged [][]int
+	for _, m := range spans {
+		if n := len(merged); n > 0 && m[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], m[1])
+			continue
+		}
+		merged = append(merged, []int{m[0], m[1]})
+	}
+
+	var out []Snippet
+	for i := 0; i < len(merged) && len(out) < maxSnippets; {
+		start, end := merged[i][0], merged[i][1]
+		from := max(start-snippetContext, strings.LastIndexByte(text[:start], '\n')+1)
+		to := min(end+snippetContext, len(text))
+		if nl := strings.IndexByte(text[end:], '\n'); nl >= 0 {
+			to = min(to, end+nl)
+		}
+		for from < start && !utf8.RuneStart(text[from]) {
+			from++
+		}
+		for to < len(text) && to > end && !utf8.RuneStart(text[to]) {
+			to--
+		}
+
+		sn := Snippet{Line: strings.Count(text[:start], "\n") + 1}
+		pos := from
+		// 同一个窗口里的后续匹配放进同一个片段
+		for ; i < len(merged) && merged[i][0] < to; i++ {
+			s, e := merged[i][0], merged[i][1]
+			to = max(to, e)
+			if s > pos {
+				sn.Parts = append(sn.Parts, SnippetPart{Text: text[pos:s]})
+			}
+			sn.Parts = append(sn.Parts, SnippetPart{Text: text[s:e], Match: true})
+			pos = e
+		}
+		if pos < to {
+			sn.Parts = append(sn.Parts, SnippetPart{Text: text[pos:to]})
+		}
+		out = append(out, sn)
+	}
+	return len(merged), out
+}
+
+// searchQuery 从 URL 参数解析 SearchQuery
+func searchQuery(c *gin.Context) (SearchQuery, error) {
+	q := SearchQuery{
+		Phrase:  c.Query("q"),
+		Regex:   c.Query("regex"),
+		AgentID: c.Query("agent"),
+		Cursor:  c.Query("cursor"),
+		Limit:   searchPageLimit,
+	}
+	var err error
+	if v := c.Query("since"); v != "" {
+		if q.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, err
+		}
+	}
+	if v := c.Query("until"); v != "" {
+		if q.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return q, err
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
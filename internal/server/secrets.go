@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSecretsDisabled  = errors.New("secrets store is not configured (server.secrets_key_file)")
+	ErrInvalidSecret    = errors.New("invalid secret")
+	ErrSecretNotFound   = errors.New("secret not found")
+	ErrSecretDenied     = errors.New("secret is not available to this user or agent")
+	ErrInvalidSecretRef = errors.New("invalid secret reference")
+)
+
+var (
+	secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,190}$`)
+	envNamePattern    = regexp.MustCompile(`^[A-Z_][A-Z0-9_]{0,127}$`)
+)
+
+// 会改变 shell 或动态链接行为的环境变量不允许被密钥覆盖
+var reservedEnv = map[string]bool{
+	"PATH": true, "HOME": true, "SHELL": true, "USER": true, "LOGNAME": true,
+	"IFS": true, "ENV": true, "BASH_ENV": true, "PS4": true, "LANG": true,
+}
+
+// Secret 服务端加密保存的密钥. 明文只在派发任务时解密, 通过 API 永远读不回来.
+// Project 限制只能用在哪个项目的任务里 (为空表示所有项目), Agents 限制可以下发到哪些机器,
+// AllowedUsers 限制谁能在任务里引用它 (为空表示所有 operator).
+type Secret struct {
+	gorm.Model
+	Name         string   `gorm:"uniqueIndex;size:191" json:"name"`
+	Description  string   `json:"description"`
+	Project      string   `gorm:"index;size:64" json:"project"`
+	Agents       Selector `gorm:"serializer:json" json:"agents"`
+	AllowedUsers []string `gorm:"serializer:json" json:"allowed_users"`
+	UpdatedBy    string   `gorm:"size:191" json:"updated_by"`
+	Nonce        []byte   `gorm:"type:varbinary(12)" json:"-"`
+	Ciphertext   []byte   `gorm:"type:blob" json:"-"`
+}
+
+// SecretRef 任务引用的密钥: 以环境变量 Env 的形式注入名为 Name 的密钥
+type SecretRef struct {
+	Env  string `json:"env"`
+	Name string `json:"name"`
+}
+
+// SealedSecret 派发时密钥密文的快照, 和 Secret 一样由 SecretsKey 加密并绑定密钥名.
+// 任务执行期间密钥被替换或删除, 汇报时仍然按派发出去的值抹掉.
+type SealedSecret struct {
+	Name       string `json:"name"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// LoadSecretsKey 读取 base64 编码的 32 字节 AES-256 密钥 (openssl rand -base64 32 的输出).
+// 文件对组或其他用户可读时拒绝加载.
+func LoadSecretsKey(path string) (cipher.AEAD, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("%s is accessible by group or others (mode %s), chmod 600 it", path, info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%s: key must be 32 bytes, got %d", path, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// 密文绑定密钥名, 把一行的密文挪到另一行解不开
+func (s *SentinelServer) sealSecret(sec *Secret, value string) error {
+	sec.Nonce = make([]byte, s.SecretsKey.NonceSize())
+	if _, err := rand.Read(sec.Nonce); err != nil {
+		return err
+	}
+	sec.Ciphertext = s.SecretsKey.Seal(nil, sec.Nonce, []byte(value), []byte(sec.Name))
+	return nil
+}
+
+func (s *SentinelServer) openSecret(sec *Secret) (string, error) {
+	plain, err := s.SecretsKey.Open(nil, sec.Nonce, sec.Ciphertext, []byte(sec.Name))
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret %s: %w", sec.Name, err)
+	}
+	return string(plain), nil
+}
+
+// PutSecret 新建或整体替换一个密钥
+func (s *SentinelServer) PutSecret(ctx context.Context, sec *Secret, value, actor string) (*Secret, error) {
+	if s.SecretsKey == nil {
+		return nil, ErrSecretsDisabled
+	}
+	sec.Model = gorm.Model{}
+	if !secretNamePattern.MatchString(sec.Name) {
+		return nil, fmt.Errorf("%w: name must match %s", ErrInvalidSecret, secretNamePattern)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: value is required", ErrInvalidSecret)
+	}
+	if err := sec.Agents.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: agents: %v", ErrInvalidSecret, err)
+	}
+	if sec.Project != "" {
+		if _, err := s.findProject(ctx, sec.Project); errors.Is(err, ErrProjectNotFound) {
+			return nil, fmt.Errorf("%w: project %s does not exist", ErrInvalidSecret, sec.Project)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	sec.UpdatedBy = actor
+	if err := s.sealSecret(sec, value); err != nil {
+		return nil, err
+	}
+
+	var existing Secret
+	err := s.DB.WithContext(ctx).Where("name = ?", sec.Name).First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.DB.WithContext(ctx).Create(sec).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		sec.ID, sec.CreatedAt = existing.ID, existing.CreatedAt
+		if err := s.DB.WithContext(ctx).Save(sec).Error; err != nil {
+			return nil, err
+		}
+	}
+	return sec, nil
+}
+
+func (s *SentinelServer) DeleteSecret(ctx context.Context, name string) error {
+	res := s.DB.WithContext(ctx).Unscoped().Where("name = ?", name).Delete(&Secret{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSecretNotFound
+	}
+	return nil
+}
+
+// ListSecrets 只返回元数据
+func (s *SentinelServer) ListSecrets(ctx context.Context) ([]Secret, error) {
+	var secrets []Secret
+	err := s.DB.WithContext(ctx).Order("name").Find(&secrets).Error
+	return secrets, err
+}
+
+func (s *SentinelServer) findSecret(ctx context.Context, name string) (*Secret, error) {
+	var sec Secret
+	err := s.DB.WithContext(ctx).Where("name = ?", name).First(&sec).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sec, nil
+}
+
+// usableBy 目标 Agent 是否在密钥的项目和 Agent 范围内
+func (sec *Secret) usableBy(agent *AgentModel) bool {
+	return (sec.Project == "" || sec.Project == agent.Project) && sec.Agents.Matches(agent)
+}
+
+// checkSecretRefs 提交任务时校验: 环境变量名合法且不重复, 密钥存在, 提交者和目标 Agent 都在密钥的授权范围内
+func (s *SentinelServer) checkSecretRefs(ctx context.Context, submitter string, agent *AgentModel, refs []SecretRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	if s.SecretsKey == nil {
+		return ErrSecretsDisabled
+	}
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if !envNamePattern.MatchString(ref.Env) || reservedEnv[ref.Env] || strings.HasPrefix(ref.Env, "LD_") {
+			return fmt.Errorf("%w: env %q is not allowed", ErrInvalidSecretRef, ref.Env)
+		}
+		if seen[ref.Env] {
+			return fmt.Errorf("%w: env %q is used twice", ErrInvalidSecretRef, ref.Env)
+		}
+		seen[ref.Env] = true
+
+		sec, err := s.findSecret(ctx, ref.Name)
+		if err != nil {
+			return err
+		}
+		if len(sec.AllowedUsers) > 0 && !slices.Contains(sec.AllowedUsers, submitter) {
+			return fmt.Errorf("%w: %s cannot use %s", ErrSecretDenied, submitter, sec.Name)
+		}
+		if !sec.usableBy(agent) {
+			return fmt.Errorf("%w: %s is not allowed on %s", ErrSecretDenied, sec.Name, agent.AgentID)
+		}
+	}
+	if !hasCredential(agent) {
+		return fmt.Errorf("%w: %s has no agent credential yet, re-register it with the enrollment token", ErrSecretDenied, agent.AgentID)
+	}
+	return nil
+}
+
+// resolveAgentSecrets 派发前把密钥明文填进任务, 返回这些值的密文快照, 随派发状态一起落库, 见 secretValues.
+// authed 是心跳流按 credential 认证出的 Agent.
+// 密钥的 Agent 范围在提交之后可能收紧了, credential 也可能被重置了, 这里按库里的最新状态再校验一次.
+func (s *SentinelServer) resolveAgentSecrets(ctx context.Context, authed *AgentModel, job *pb.Job) ([]SealedSecret, error) {
+	if s.SecretsKey == nil {
+		return nil, ErrSecretsDisabled
+	}
+	agent, err := s.loadAgent(ctx, authed.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasCredential(agent) || agent.CredentialHash != authed.CredentialHash {
+		return nil, fmt.Errorf("%w: %s is not authenticated by an agent credential", ErrSecretDenied, agent.AgentID)
+	}
+	var sealed []SealedSecret
+	for _, env := range job.Secrets {
+		sec, err := s.findSecret(ctx, env.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !sec.usableBy(agent) {
+			return nil, fmt.Errorf("%w: %s is not allowed on %s", ErrSecretDenied, sec.Name, agent.AgentID)
+		}
+		if env.Value, err = s.openSecret(sec); err != nil {
+			return nil, err
+		}
+		sealed = append(sealed, SealedSecret{Name: sec.Name, Nonce: sec.Nonce, Ciphertext: sec.Ciphertext})
+	}
+	return sealed, nil
+}
+
+// secretValues 返回派发给 Agent 的密钥明文, 用于从输出里抹掉. 用的是派发时的快照, 之后替换或删除密钥不影响;
+// 升级之前派发的任务没有快照, 退回按当前的值. 解不开的跳过.
+func (s *SentinelServer) secretValues(ctx context.Context, job *JobRecord) []string {
+	if s.SecretsKey == nil {
+		return nil
+	}
+	var values []string
+	if len(job.DispatchedSecrets) > 0 {
+		for _, sealed := range job.DispatchedSecrets {
+			sec := &Secret{Name: sealed.Name, Nonce: sealed.Nonce, Ciphertext: sealed.Ciphertext}
+			if v, err := s.openSecret(sec); err == nil {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	for _, ref := range job.Secrets {
+		sec, err := s.findSecret(ctx, ref.Name)
+		if err != nil {
+			continue
+		}
+		if v, err := s.openSecret(sec); err == nil {
+			values = append(values, v)
+		}
+	}
+	return values
+}
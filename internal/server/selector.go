@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidSelector = errors.New("invalid selector")
+
+// Selector 按 ID / 标签 / 状态挑选一组 Agent, 各条件之间是 AND 关系.
+// 为了防止手滑作用到全部机器, 条件全空时必须显式写 all=true.
+type Selector struct {
+	All      bool     `json:"all"`
+	AgentIDs []string `json:"agent_ids"`
+	Tags     []string `json:"tags"`   // 必须同时带有这些标签
+	Status   string   `json:"status"` // Online / Offline, 为空不限
+}
+
+func (sel Selector) IsEmpty() bool {
+	return len(sel.AgentIDs) == 0 && len(sel.Tags) == 0 && sel.Status == ""
+}
+
+func (sel Selector) Validate() error {
+	if sel.IsEmpty() && !sel.All {
+		return fmt.Errorf("%w: at least one of agent_ids, tags, status is required (or set all=true)", ErrInvalidSelector)
+	}
+	for _, t := range sel.Tags {
+		if !validTag(t) {
+			return fmt.Errorf("%w: tag %q must be non-empty and must not contain ',', '%%' or '_'", ErrInvalidSelector, t)
+		}
+	}
+	if sel.Status != "" && sel.Status != AgentStatusOnline && sel.Status != AgentStatusOffline {
+		return fmt.Errorf("%w: status must be %q or %q", ErrInvalidSelector, AgentStatusOnline, AgentStatusOffline)
+	}
+	return nil
+}
+
+// Apply 把条件拼到 AgentModel 的查询上
+func (sel Selector) Apply(q *gorm.DB) *gorm.DB {
+	if len(sel.AgentIDs) > 0 {
+		q = q.Where("agent_id IN ?", sel.AgentIDs)
+	}
+	for _, t := range sel.Tags {
+		q = q.Where("tags LIKE ?", "%,"+t+",%")
+	}
+	if sel.Status != "" {
+		q = q.Where("status = ?", sel.Status)
+	}
+	return q
+}
+
+// Matches 在内存里判断单个 Agent 是否命中, 语义和 Apply 一致
+func (sel Selector) Matches(agent *AgentModel) bool {
+	if len(sel.AgentIDs) > 0 && !slices.Contains(sel.AgentIDs, agent.AgentID) {
+		return false
+	}
+	for _, t := range sel.Tags {
+		if !strings.Contains(agent.Tags, ","+t+",") {
+			return false
+		}
+	}
+	return sel.Status == "" || sel.Status == agent.Status
+}
+
+// validTag 标签会被拼进 LIKE 条件, 不允许出现分隔符和通配符
+func validTag(t string) bool {
+	return t != "" && !strings.ContainsAny(t, ",%_")
+}
+
+// SelectAgents 按 agent_id 排序返回命中的 Agent
+func (s *SentinelServer) SelectAgents(ctx context.Context, sel Selector) ([]AgentModel, error) {
+	if err := sel.Validate(); err != nil {
+		return nil, err
+	}
+	var agents []AgentModel
+	err := sel.Apply(s.DB.WithContext(ctx).Model(&AgentModel{})).Order("agent_id").Find(&agents).Error
+	return agents, err
+}
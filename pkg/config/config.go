@@ -1,44 +1,384 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// 配置优先级 (高 -> 低): 命令行参数 > 环境变量 > 配置文件 > 默认值.
+// 环境变量统一以 SENTINEL_ 开头, 层级用下划线连接, 例如 database.password -> SENTINEL_DATABASE_PASSWORD.
+const envPrefix = "SENTINEL"
+
+// Component 不同进程需要校验的配置段不一样
+type Component string
+
+const (
+	ComponentServer     Component = "server"
+	ComponentAgent      Component = "agent"
+	ComponentAPIServer  Component = "api-server"
+	ComponentScanWorker Component = "scan-worker"
+)
+
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	RabbitMQ RabbitMQConfig
+	Server   ServerConfig   `mapstructure:"server"`
+	Agent    AgentConfig    `mapstructure:"agent"`
+	Database DatabaseConfig `mapstructure:"database"`
+	RabbitMQ RabbitMQConfig `mapstructure:"rabbitmq"`
+	Redis    RedisConfig    `mapstructure:"redis"`
+	Cluster  ClusterConfig  `mapstructure:"cluster"`
+	Scan     ScanConfig     `mapstructure:"scan"`
 }
 
 type ServerConfig struct {
-	Port string
+	HTTPAddr        string           `mapstructure:"http_addr"`
+	GRPCAddr        string           `mapstructure:"grpc_addr"`
+	ReadTimeout     time.Duration    `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration    `mapstructure:"write_timeout"`
+	ShutdownTimeout time.Duration    `mapstructure:"shutdown_timeout"`
+	JobRetention    time.Duration    `mapstructure:"job_retention"`
+	AgentReleaseDir string           `mapstructure:"agent_release_dir"`
+	JobSigningKey   string           `mapstructure:"job_signing_key"`       // Ed25519 PKCS#8 PEM, 为空表示不签名
+	JobTTL          time.Duration    `mapstructure:"job_ttl"`               // 签名有效期, 超时未执行的任务作废
+	ApprovalTTL     time.Duration    `mapstructure:"approval_ttl"`          // 审批规则未单独配置时, 等待审批的最长时间
+	BootstrapToken  string           `mapstructure:"bootstrap_admin_token"` // 库里还没有用户时用它创建管理员 admin
+	SecretsKeyFile  string           `mapstructure:"secrets_key_file"`      // base64 的 32 字节 AES 密钥, 为空表示不启用密钥库
+	RequireEnroll   bool             `mapstructure:"require_enrollment"`    // 新 Agent 必须带项目的 enrollment token 注册
+	TrustedProxies  []string         `mapstructure:"trusted_proxies"`       // 信任其 X-Forwarded-For 的反向代理 (IP / CIDR), 为空表示一个都不信
+	Redaction       RedactionConfig  `mapstructure:"redaction"`
+	Encryption      EncryptionConfig `mapstructure:"encryption"`
+	Quotas          QuotasConfig     `mapstructure:"quotas"`
+	TLS             ServerTLSConfig  `mapstructure:"tls"`
+}
+
+// QuotasConfig 提交任务的默认配额, 每个 API 用户和每个项目各自计算, 两边都不超才放行.
+// 个别用户 / 项目可以通过 PUT /quota/:scope/:target 单独调整.
+type QuotasConfig struct {
+	User    QuotaConfig `mapstructure:"user"`
+	Project QuotaConfig `mapstructure:"project"`
+}
+
+// QuotaConfig 0 表示不限制
+type QuotaConfig struct {
+	JobsPerMinute     int `mapstructure:"jobs_per_minute"`
+	MaxConcurrentJobs int `mapstructure:"max_concurrent_jobs"` // 还没结束的任务 (等待审批 / 排队 / 执行中)
+	MaxFanOut         int `mapstructure:"max_fan_out"`         // 一次按 selector 下发时最多命中的 Agent 数
+}
+
+// EncryptionConfig 任务 payload / result 的信封加密. KEK 为 base64 编码的 32 字节密钥 (openssl rand -base64 32),
+// kek 和 kek_file 二选一, 都为空表示不加密. 轮换时把新密钥配成 kek, 旧密钥挪到 previous_*, 再执行 server rotate-keys.
+type EncryptionConfig struct {
+	KEK              string   `mapstructure:"kek"` // 建议用环境变量 SENTINEL_SERVER_ENCRYPTION_KEK
+	KEKFile          string   `mapstructure:"kek_file"`
+	PreviousKEKs     []string `mapstructure:"previous_keks"`
+	PreviousKEKFiles []string `mapstructure:"previous_kek_files"`
+}
+
+// Enabled 配置了 KEK
+func (e EncryptionConfig) Enabled() bool { return e.KEK != "" || e.KEKFile != "" }
+
+// RedactionConfig 任务输出落库和打日志之前的脱敏规则, 内置检测器见 internal/redact.Builtin
+type RedactionConfig struct {
+	DisabledDetectors []string `mapstructure:"disabled_detectors"` // 要关闭的内置检测器名字
+	Patterns          []string `mapstructure:"patterns"`           // 自定义正则, 有名为 secret 的分组时只替换该分组
+}
+
+type AgentConfig struct {
+	ServerAddr        string          `mapstructure:"server_addr"`
+	HeartbeatInterval time.Duration   `mapstructure:"heartbeat_interval"`
+	JobTimeout        time.Duration   `mapstructure:"job_timeout"`
+	ReportTimeout     time.Duration   `mapstructure:"report_timeout"`
+	ReconnectDelay    time.Duration   `mapstructure:"reconnect_delay"`
+	Tags              []string        `mapstructure:"tags"`
+	WorkerPoolSize    int             `mapstructure:"worker_pool_size"`
+	AllowedJobTypes   []string        `mapstructure:"allowed_job_types"` // 为空表示不限制
+	LogLevel          string          `mapstructure:"log_level"`
+	PolicyFile        string          `mapstructure:"policy_file"`      // 为空表示不限制, 见 internal/agent.Policy
+	JobPublicKey      string          `mapstructure:"job_public_key"`   // 配置后只执行用对应私钥签名的任务
+	EnrollmentToken   string          `mapstructure:"enrollment_token"` // 首次注册时加入对应项目, 为空进 default 项目
+	StateDir          string          `mapstructure:"state_dir"`        // 已执行任务记录等本地状态
+	Sandbox           SandboxConfig   `mapstructure:"sandbox"`
+	TLS               ClientTLSConfig `mapstructure:"tls"`
+}
+
+// SandboxConfig 任务子进程的运行身份和资源限制, 零值表示不限制.
+// rlimit 对每个任务单独生效; cgroup 限制的是整个任务进程树, 需要 cgroup v2 且 Agent 有权限创建子 cgroup.
+type SandboxConfig struct {
+	User       string        `mapstructure:"user"`  // 用户名或 uid, 为空沿用 Agent 自己的身份
+	Group      string        `mapstructure:"group"` // 组名或 gid, 为空使用 user 的主组
+	WorkDir    string        `mapstructure:"work_dir"`
+	CPUTime    time.Duration `mapstructure:"cpu_time"`   // RLIMIT_CPU
+	MemoryMB   int           `mapstructure:"memory_mb"`  // RLIMIT_AS, 开启 cgroup 时同时写入 memory.max
+	OpenFiles  int           `mapstructure:"open_files"` // RLIMIT_NOFILE
+	Processes  int           `mapstructure:"processes"`  // RLIMIT_NPROC (按 uid 计数), 开启 cgroup 时同时写入 pids.max
+	Cgroup     bool          `mapstructure:"cgroup"`
+	CgroupRoot string        `mapstructure:"cgroup_root"`
+	CPUPercent int           `mapstructure:"cpu_percent"` // cgroup cpu.max, 100 表示一个核
 }
 
 type DatabaseConfig struct {
-	Driver string
-	Source string
+	Host            string        `mapstructure:"host"`
+	Port            int           `mapstructure:"port"`
+	User            string        `mapstructure:"user"`
+	Password        string        `mapstructure:"password"`
+	DBName          string        `mapstructure:"dbname"`
+	Params          string        `mapstructure:"params"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+}
+
+// DSN 拼出 go-sql-driver/mysql 的连接串. 由驱动负责转义, 密码里有 @ / ? 之类的字符也没问题.
+// Params 解析失败的部分会被忽略, Validate 会先把它报出来.
+func (d DatabaseConfig) DSN() string {
+	cfg := mysql.NewConfig()
+	cfg.User = d.User
+	cfg.Passwd = d.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(d.Host, fmt.Sprint(d.Port))
+	cfg.DBName = d.DBName
+	if params, _ := url.ParseQuery(d.Params); len(params) > 0 {
+		cfg.Params = make(map[string]string, len(params))
+		for k, v := range params {
+			cfg.Params[k] = v[len(v)-1]
+		}
+	}
+	return cfg.FormatDSN()
 }
 
 type RabbitMQConfig struct {
-	Url       string
-	QueueName string
+	// URL 优先; 为空时由 host/port/user/password 拼出, host 也为空则使用进程内 broker
+	URL            string `mapstructure:"url"`
+	Host           string `mapstructure:"host"`
+	Port           int    `mapstructure:"port"`
+	User           string `mapstructure:"user"`
+	Password       string `mapstructure:"password"`
+	QueueName      string `mapstructure:"queue_name"`
+	EventsExchange string `mapstructure:"events_exchange"`
+}
+
+// BrokerURL 返回 mq.New 可以识别的地址, "" 表示进程内 broker
+func (r RabbitMQConfig) BrokerURL() string {
+	if r.URL != "" || r.Host == "" {
+		return r.URL
+	}
+	return fmt.Sprintf("amqp://%s:%s@%s/", r.User, r.Password, net.JoinHostPort(r.Host, fmt.Sprint(r.Port)))
+}
+
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+type ClusterConfig struct {
+	// InstanceID 为空时自动生成 (主机名 + 随机后缀)
+	InstanceID string        `mapstructure:"instance_id"`
+	OwnerTTL   time.Duration `mapstructure:"owner_ttl"`
+	LeaderTTL  time.Duration `mapstructure:"leader_ttl"`
+}
+
+type ScanConfig struct {
+	Workers     int           `mapstructure:"workers"`
+	Concurrency int           `mapstructure:"concurrency"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+	Deadline    time.Duration `mapstructure:"deadline"`
 }
 
 var GlobalConfig Config
 
-func InitConfig() {
-	viper.SetConfigName("config") // 配置文件名 (不带后缀)
-	viper.SetConfigType("yaml")   // 文件格式
-	viper.AddConfigPath(".")      // 搜索路径 (当前目录)
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.http_addr", ":8080")
+	v.SetDefault("server.grpc_addr", ":9090")
+	v.SetDefault("server.read_timeout", "15s")
+	v.SetDefault("server.write_timeout", "30s")
+	v.SetDefault("server.shutdown_timeout", "10s")
+	v.SetDefault("server.job_retention", "720h")
+	v.SetDefault("server.agent_release_dir", "data/agent-releases")
+	v.SetDefault("server.job_signing_key", "")
+	v.SetDefault("server.job_ttl", "24h")
+	v.SetDefault("server.approval_ttl", "1h")
+	v.SetDefault("server.bootstrap_admin_token", "")
+	v.SetDefault("server.secrets_key_file", "")
+	v.SetDefault("server.require_enrollment", false)
+	v.SetDefault("server.trusted_proxies", []string{})
+	v.SetDefault("server.redaction.disabled_detectors", []string{})
+	v.SetDefault("server.redaction.patterns", []string{})
+	v.SetDefault("server.encryption.kek", "")
+	v.SetDefault("server.encryption.kek_file", "")
+	v.SetDefault("server.encryption.previous_keks", []string{})
+	v.SetDefault("server.encryption.previous_kek_files", []string{})
+	v.SetDefault("server.quotas.user.jobs_per_minute", 60)
+	v.SetDefault("server.quotas.user.max_concurrent_jobs", 200)
+	v.SetDefault("server.quotas.user.max_fan_out", 100)
+	v.SetDefault("server.quotas.project.jobs_per_minute", 600)
+	v.SetDefault("server.quotas.project.max_concurrent_jobs", 2000)
+	v.SetDefault("server.quotas.project.max_fan_out", 500)
+	v.SetDefault("server.tls.cert_file", "")
+	v.SetDefault("server.tls.key_file", "")
+	v.SetDefault("server.tls.client_ca_file", "")
+
+	v.SetDefault("agent.server_addr", "127.0.0.1:9090")
+	v.SetDefault("agent.heartbeat_interval", "5s")
+	v.SetDefault("agent.job_timeout", "10s")
+	v.SetDefault("agent.report_timeout", "5s")
+	v.SetDefault("agent.reconnect_delay", "3s")
+	v.SetDefault("agent.tags", []string{})
+	v.SetDefault("agent.worker_pool_size", 4)
+	v.SetDefault("agent.allowed_job_types", []string{})
+	v.SetDefault("agent.log_level", "info")
+	v.SetDefault("agent.policy_file", "")
+	v.SetDefault("agent.job_public_key", "")
+	v.SetDefault("agent.enrollment_token", "")
+	v.SetDefault("agent.state_dir", "data/agent")
+	v.SetDefault("agent.sandbox.user", "")
+	v.SetDefault("agent.sandbox.group", "")
+	v.SetDefault("agent.sandbox.work_dir", "")
+	v.SetDefault("agent.sandbox.cpu_time", "0s")
+	v.SetDefault("agent.sandbox.memory_mb", 0)
+	v.SetDefault("agent.sandbox.open_files", 0)
+	v.SetDefault("agent.sandbox.processes", 0)
+	v.SetDefault("agent.sandbox.cgroup", false)
+	v.SetDefault("agent.sandbox.cgroup_root", "/sys/fs/cgroup/sentinel")
+	v.SetDefault("agent.sandbox.cpu_percent", 0)
+	v.SetDefault("agent.tls.enabled", false)
+	v.SetDefault("agent.tls.ca_file", "")
+	v.SetDefault("agent.tls.cert_file", "")
+	v.SetDefault("agent.tls.key_file", "")
+	v.SetDefault("agent.tls.server_name", "")
+
+	v.SetDefault("database.host", "127.0.0.1")
+	v.SetDefault("database.port", 3306)
+	v.SetDefault("database.user", "root")
+	v.SetDefault("database.password", "")
+	v.SetDefault("database.dbname", "cloud_compute")
+	v.SetDefault("database.params", "charset=utf8mb4&parseTime=True&loc=Local")
+	v.SetDefault("database.max_open_conns", 50)
+	v.SetDefault("database.max_idle_conns", 10)
+	v.SetDefault("database.conn_max_lifetime", "1h")
+
+	v.SetDefault("rabbitmq.url", "")
+	v.SetDefault("rabbitmq.host", "")
+	v.SetDefault("rabbitmq.port", 5672)
+	v.SetDefault("rabbitmq.user", "guest")
+	v.SetDefault("rabbitmq.password", "guest")
+	v.SetDefault("rabbitmq.queue_name", "scan_tasks")
+	v.SetDefault("rabbitmq.events_exchange", "sentinel.events")
+
+	v.SetDefault("redis.addr", "")
+	v.SetDefault("redis.password", "")
+	v.SetDefault("redis.db", 0)
+
+	v.SetDefault("cluster.instance_id", "")
+	v.SetDefault("cluster.owner_ttl", "30s")
+	v.SetDefault("cluster.leader_ttl", "15s")
+
+	v.SetDefault("scan.workers", 10)
+	v.SetDefault("scan.concurrency", 200)
+	v.SetDefault("scan.timeout", "2s")
+	v.SetDefault("scan.deadline", "20m")
+}
+
+// legacyEnv 早期 docker-compose 里用过的环境变量, 继续兼容
+var legacyEnv = map[string]string{
+	"database.host":            "DB_HOST",
+	"agent.server_addr":        "SERVER_ADDR",
+	"redis.addr":               "REDIS_ADDR",
+	"redis.password":           "REDIS_PASSWORD",
+	"cluster.instance_id":      "INSTANCE_ID",
+	"rabbitmq.url":             "RABBITMQ_URL",
+	"rabbitmq.events_exchange": "EVENTS_EXCHANGE",
+}
+
+// flagKeys 命令行参数 -> 配置键. 密码类配置故意不提供参数, 避免出现在 ps 里.
+var flagKeys = map[string]string{
+	"http-addr":   "server.http_addr",
+	"grpc-addr":   "server.grpc_addr",
+	"tls-cert":    "server.tls.cert_file",
+	"tls-key":     "server.tls.key_file",
+	"signing-key": "server.job_signing_key",
+	"public-key":  "agent.job_public_key",
+	"server-addr": "agent.server_addr",
+	"tls-ca":      "agent.tls.ca_file",
+	"policy":      "agent.policy_file",
+	"db-host":     "database.host",
+	"db-port":     "database.port",
+	"db-user":     "database.user",
+	"db-name":     "database.dbname",
+	"redis-addr":  "redis.addr",
+	"instance-id": "cluster.instance_id",
+}
+
+// Load 按 "默认值 < 配置文件 < 环境变量 < 命令行参数" 合并配置并按组件校验.
+// 配置文件路径取 --config 或 SENTINEL_CONFIG; 都没指定时在 . 和 /etc/sentinel 下找 config.yaml, 找不到不算错误.
+func Load(component Component, args []string) (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	fs := pflag.NewFlagSet(string(component), pflag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv(envPrefix+"_CONFIG"), "config file path")
+	for name, key := range flagKeys {
+		fs.String(name, v.GetString(key), "overrides "+key)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	for name, key := range flagKeys {
+		if err := v.BindPFlag(key, fs.Lookup(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for key, legacy := range legacyEnv {
+		v.BindEnv(key, envPrefix+"_"+strings.ToUpper(strings.ReplaceAll(key, ".", "_")), legacy)
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Error reading config file, %s", err)
+	if *configFile != "" {
+		v.SetConfigFile(*configFile)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(".")
+		v.AddConfigPath("/etc/sentinel")
 	}
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if *configFile != "" || !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	if err := cfg.Validate(component); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	if used := v.ConfigFileUsed(); used != "" {
+		log.Printf("Configuration loaded from %s", used)
+	}
+	return &cfg, nil
+}
 
-	if err := viper.Unmarshal(&GlobalConfig); err != nil {
-		log.Fatalf("Unable to decode into struct, %v", err)
+// MustLoad 加载失败直接退出, 同时写入 GlobalConfig
+func MustLoad(component Component) *Config {
+	cfg, err := Load(component, os.Args[1:])
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
-	log.Println("Configuration loaded successfully")
+	GlobalConfig = *cfg
+	return cfg
 }
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+)
+
+// Validate 只校验该组件真正会用到的配置段, 所有问题一次性报出来
+func (c *Config) Validate(component Component) error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("  - "+format, args...))
+		}
+	}
+
+	validateDatabase := func() {
+		d := c.Database
+		check(d.Host != "", "database.host is required")
+		check(d.Port > 0 && d.Port < 65536, "database.port %d out of range", d.Port)
+		check(d.User != "", "database.user is required")
+		check(d.DBName != "", "database.dbname is required")
+		check(d.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+		_, err := url.ParseQuery(d.Params)
+		check(err == nil, "database.params: %v", err)
+	}
+	validateAddr := func(key, addr string, needHost bool) {
+		host, port, err := net.SplitHostPort(addr)
+		check(err == nil && port != "" && (!needHost || host != ""), "%s %q is not a valid host:port", key, addr)
+	}
+	validateProxies := func() {
+		for _, p := range c.Server.TrustedProxies {
+			_, _, err := net.ParseCIDR(p)
+			check(err == nil || net.ParseIP(p) != nil, "server.trusted_proxies: %q is not an IP or CIDR", p)
+		}
+	}
+	validateTimeout := func(key string, d time.Duration) {
+		check(d > 0, "%s must be positive, got %s", key, d)
+	}
+	validateFile := func(key, path string) {
+		if path == "" {
+			return
+		}
+		_, err := os.Stat(path)
+		check(err == nil, "%s: %v", key, err)
+	}
+
+	switch component {
+	case ComponentServer:
+		validateDatabase()
+		validateAddr("server.http_addr", c.Server.HTTPAddr, false)
+		validateAddr("server.grpc_addr", c.Server.GRPCAddr, false)
+		validateProxies()
+		validateTimeout("server.read_timeout", c.Server.ReadTimeout)
+		validateTimeout("server.write_timeout", c.Server.WriteTimeout)
+		validateTimeout("server.shutdown_timeout", c.Server.ShutdownTimeout)
+		check(c.Server.JobRetention >= time.Hour, "server.job_retention must be at least 1h, got %s", c.Server.JobRetention)
+		check(c.Server.AgentReleaseDir != "", "server.agent_release_dir is required")
+		validateFile("server.job_signing_key", c.Server.JobSigningKey)
+		validateFile("server.secrets_key_file", c.Server.SecretsKeyFile)
+		check(c.Server.JobTTL >= time.Minute, "server.job_ttl must be at least 1m, got %s", c.Server.JobTTL)
+		check(c.Server.ApprovalTTL >= time.Minute, "server.approval_ttl must be at least 1m, got %s", c.Server.ApprovalTTL)
+		check(c.Server.BootstrapToken == "" || len(c.Server.BootstrapToken) >= 16,
+			"server.bootstrap_admin_token must be at least 16 characters")
+
+		for _, expr := range c.Server.Redaction.Patterns {
+			_, err := regexp.Compile(expr)
+			check(err == nil, "server.redaction.patterns: %v", err)
+		}
+
+		e := c.Server.Encryption
+		check(e.KEK == "" || e.KEKFile == "", "server.encryption.kek and server.encryption.kek_file are mutually exclusive")
+		check(e.Enabled() || (len(e.PreviousKEKs) == 0 && len(e.PreviousKEKFiles) == 0),
+			"server.encryption.previous_* requires a current kek")
+		validateFile("server.encryption.kek_file", e.KEKFile)
+		for _, f := range e.PreviousKEKFiles {
+			validateFile("server.encryption.previous_kek_files", f)
+		}
+
+		for _, q := range []struct {
+			key string
+			QuotaConfig
+		}{{"server.quotas.user", c.Server.Quotas.User}, {"server.quotas.project", c.Server.Quotas.Project}} {
+			check(q.JobsPerMinute >= 0 && q.MaxConcurrentJobs >= 0 && q.MaxFanOut >= 0,
+				"%s limits must not be negative (0 means unlimited)", q.key)
+		}
+
+		t := c.Server.TLS
+		check((t.CertFile == "") == (t.KeyFile == ""), "server.tls.cert_file and server.tls.key_file must be set together")
+		check(t.ClientCAFile == "" || t.CertFile != "", "server.tls.client_ca_file requires server.tls.cert_file")
+		validateFile("server.tls.cert_file", t.CertFile)
+		validateFile("server.tls.key_file", t.KeyFile)
+		validateFile("server.tls.client_ca_file", t.ClientCAFile)
+
+		if c.Redis.Addr != "" {
+			validateAddr("redis.addr", c.Redis.Addr, true)
+		}
+		validateTimeout("cluster.owner_ttl", c.Cluster.OwnerTTL)
+		check(c.Cluster.LeaderTTL >= 3*time.Second, "cluster.leader_ttl must be at least 3s, got %s", c.Cluster.LeaderTTL)
+
+	case ComponentAgent:
+		validateAddr("agent.server_addr", c.Agent.ServerAddr, true)
+		check(c.Agent.HeartbeatInterval >= time.Second, "agent.heartbeat_interval must be at least 1s, got %s", c.Agent.HeartbeatInterval)
+		validateTimeout("agent.job_timeout", c.Agent.JobTimeout)
+		validateTimeout("agent.report_timeout", c.Agent.ReportTimeout)
+		validateTimeout("agent.reconnect_delay", c.Agent.ReconnectDelay)
+		check(c.Agent.WorkerPoolSize > 0, "agent.worker_pool_size must be positive")
+		check(agentLogLevels[c.Agent.LogLevel], "agent.log_level %q must be one of debug, info, warn, error", c.Agent.LogLevel)
+
+		t := c.Agent.TLS
+		check((t.CertFile == "") == (t.KeyFile == ""), "agent.tls.cert_file and agent.tls.key_file must be set together")
+		validateFile("agent.policy_file", c.Agent.PolicyFile)
+		validateFile("agent.job_public_key", c.Agent.JobPublicKey)
+		check(c.Agent.StateDir != "", "agent.state_dir is required")
+		sb := c.Agent.Sandbox
+		check(sb.CPUTime == 0 || sb.CPUTime >= time.Second, "agent.sandbox.cpu_time must be 0 or at least 1s, got %s", sb.CPUTime)
+		check(sb.MemoryMB >= 0 && sb.OpenFiles >= 0 && sb.Processes >= 0 && sb.CPUPercent >= 0,
+			"agent.sandbox limits must not be negative")
+		check(sb.Group == "" || sb.User != "", "agent.sandbox.group requires agent.sandbox.user")
+		check(!sb.Cgroup || sb.CgroupRoot != "", "agent.sandbox.cgroup_root is required when cgroup is enabled")
+		validateFile("agent.tls.ca_file", t.CAFile)
+		validateFile("agent.tls.cert_file", t.CertFile)
+		validateFile("agent.tls.key_file", t.KeyFile)
+
+	case ComponentAPIServer:
+		validateDatabase()
+		validateAddr("server.http_addr", c.Server.HTTPAddr, false)
+		validateProxies()
+		check(c.RabbitMQ.QueueName != "", "rabbitmq.queue_name is required")
+		c.validateScan(check)
+
+	case ComponentScanWorker:
+		validateDatabase()
+		check(c.RabbitMQ.BrokerURL() != "", "rabbitmq.url or rabbitmq.host is required for scan-worker")
+		check(c.RabbitMQ.QueueName != "", "rabbitmq.queue_name is required")
+		c.validateScan(check)
+
+	default:
+		return fmt.Errorf("unknown component %q", component)
+	}
+
+	return errors.Join(errs...)
+}
+
+var agentLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+func (c *Config) validateScan(check func(bool, string, ...any)) {
+	check(c.Scan.Workers > 0, "scan.workers must be positive")
+	check(c.Scan.Concurrency > 0, "scan.concurrency must be positive")
+	check(c.Scan.Timeout > 0, "scan.timeout must be positive")
+	// 超过 RabbitMQ 默认 30 分钟的 consumer timeout 会被当成卡死的消费者重投
+	check(c.Scan.Deadline > 0 && c.Scan.Deadline < 30*time.Minute, "scan.deadline must be positive and below 30m, got %s", c.Scan.Deadline)
+}
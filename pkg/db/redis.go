@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"time"
 
@@ -9,31 +10,78 @@ import (
 	"github.com/stywzn/Go-Cloud-Compute/pkg/config"
 )
 
-var DB *redis.Client
+var RDB *redis.Client
 
+// InitRedis 按 config.GlobalConfig.Redis 建立全局连接, 失败直接退出 (与 Init 保持一致)
 func InitRedis() {
-	cfg := config.Conf.Redis
-	RDB = redis.NewClient(&redis.Options{
-		Addr:     cfg.Addr,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
-
-	_, err := RDB.Ping(context.Background()).Result()
+	client, err := NewRedis(config.GlobalConfig.Redis)
 	if err != nil {
-		log.Fatal("Redis connect failed: %v", err)
+		log.Fatalf("Redis connect failed: %v", err)
 	}
+	RDB = client
 	log.Println("Redis connected")
+}
+
+// NewRedis 创建客户端并 Ping 一次, 连不上就返回错误而不是留一个半残的 client
+func NewRedis(cfg config.RedisConfig) (*redis.Client, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("redis addr is empty")
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:         cfg.Addr,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("ping redis %s: %w", cfg.Addr, err)
+	}
+	return client, nil
+}
 
+// RedisHealth 一次健康检查的结果
+type RedisHealth struct {
+	Healthy    bool          `json:"healthy"`
+	Latency    time.Duration `json:"latency"`
+	Error      string        `json:"error,omitempty"`
+	TotalConns uint32        `json:"total_conns"`
+	IdleConns  uint32        `json:"idle_conns"`
 }
 
+// CheckRedis 用 PING 探测 Redis, 同时带上连接池状态, 供健康检查接口使用
+func CheckRedis(ctx context.Context, client *redis.Client) RedisHealth {
+	if client == nil {
+		return RedisHealth{Error: "redis not initialized"}
+	}
+	start := time.Now()
+	err := client.Ping(ctx).Err()
+	stats := client.PoolStats()
+
+	h := RedisHealth{
+		Healthy:    err == nil,
+		Latency:    time.Since(start),
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+	}
+	if err != nil {
+		h.Error = err.Error()
+	}
+	return h
+}
+
+// LockTask 简单的一次性任务锁, 不续期; 需要续期请用 ObtainLock
 func LockTask(taskKey string, ttl time.Duration) bool {
 	ctx := context.Background()
-	success, err := RDB.SetNX(ctx, taskKey, "processing", ttl).Rsult()
+	success, err := RDB.SetNX(ctx, taskKey, "processing", ttl).Result()
 	if err != nil {
 		log.Printf("Redis error: %v", err)
 		return false
 	}
 	return success
-
 }
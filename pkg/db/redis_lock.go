@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrLockNotObtained = errors.New("redis lock: already held by someone else")
+	ErrLockNotHeld     = errors.New("redis lock: not held")
+)
+
+// 只有 value 等于自己的 token 才能续期/释放, 避免误删别人的锁
+var (
+	refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// Lock 基于 SET NX PX 的分布式锁, 每个持有者有唯一 token
+type Lock struct {
+	client *redis.Client
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+// ObtainLock 尝试获取锁, 被别人占用时返回 ErrLockNotObtained
+func ObtainLock(ctx context.Context, client *redis.Client, key string, ttl time.Duration) (*Lock, error) {
+	token := uuid.NewString()
+	ok, err := client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockNotObtained
+	}
+	return &Lock{client: client, key: key, token: token, ttl: ttl}, nil
+}
+
+func (l *Lock) Key() string   { return l.key }
+func (l *Lock) Token() string { return l.token }
+
+// Refresh 把锁的过期时间重置为 ttl, 锁已经丢了返回 ErrLockNotHeld
+func (l *Lock) Refresh(ctx context.Context) error {
+	n, err := refreshScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release 释放锁; 已经过期或被抢走时返回 ErrLockNotHeld
+func (l *Lock) Release(ctx context.Context) error {
+	n, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// KeepAlive 后台每 ttl/3 续期一次, 直到 ctx 结束或锁丢失.
+// 返回的 channel 在锁丢失时关闭, 调用方应立即停止受保护的工作.
+// 单次续期网络失败不算丢锁, 只要在 ttl 内再次续上即可.
+func (l *Lock) KeepAlive(ctx context.Context) <-chan struct{} {
+	lost := make(chan struct{})
+	go func() {
+		interval := l.ttl / 3
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		deadline := time.Now().Add(l.ttl)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rctx, cancel := context.WithTimeout(ctx, interval)
+				err := l.Refresh(rctx)
+				cancel()
+				switch {
+				case err == nil:
+					deadline = time.Now().Add(l.ttl)
+				case errors.Is(err, ErrLockNotHeld) || time.Now().After(deadline):
+					log.Printf("[Redis] 锁 %s 已丢失: %v", l.key, err)
+					close(lost)
+					return
+				default:
+					log.Printf("[Redis] 锁 %s 续期失败, 稍后重试: %v", l.key, err)
+				}
+			}
+		}
+	}()
+	return lost
+}
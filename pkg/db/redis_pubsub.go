@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PublishJSON 把 v 序列化成 JSON 发到 channel
+func PublishJSON(ctx context.Context, client *redis.Client, channel string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return client.Publish(ctx, channel, body).Err()
+}
+
+// Subscribe 订阅 channels 并在当前 goroutine 里逐条回调 handler, 直到 ctx 结束.
+// 首次订阅失败直接返回错误; 之后的断线由 go-redis 自动重连并重新订阅.
+// 注意 Redis Pub/Sub 是 at-most-once 的, 断线期间的消息会丢, 不要拿它当持久队列.
+func Subscribe(ctx context.Context, client *redis.Client, handler func(channel string, payload []byte), channels ...string) error {
+	ps := client.Subscribe(ctx, channels...)
+	defer ps.Close()
+
+	// 等待订阅确认, 这样调用方能拿到第一次的连接错误
+	if _, err := ps.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Printf("[Redis] 订阅回调 panic (channel=%s): %v", msg.Channel, r)
+					}
+				}()
+				handler(msg.Channel, []byte(msg.Payload))
+			}()
+		}
+	}
+}
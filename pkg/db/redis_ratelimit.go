@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 滑动窗口日志: ZSET 里存窗口内每次请求的时间戳(ms), 放不下这 n 次就拒绝, 并算出要等多久才能腾出 n 个名额
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local n = tonumber(ARGV[5])
+redis.call("ZREMRANGEBYSCORE", key, 0, now - window)
+local count = redis.call("ZCARD", key)
+if count + n <= limit then
+	for i = 1, n do
+		redis.call("ZADD", key, now, ARGV[4] .. ":" .. i)
+	end
+	redis.call("PEXPIRE", key, window)
+	return {1, limit - count - n, 0}
+end
+local retry = window
+local need = count + n - limit
+if need <= count then
+	local oldest = redis.call("ZRANGE", key, need - 1, need - 1, "WITHSCORES")
+	if oldest[2] then
+		retry = tonumber(oldest[2]) + window - now
+	end
+end
+return {0, 0, retry}`)
+
+// RateResult 一次限流判定的结果
+type RateResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// RateLimiter 多实例共享的滑动窗口限流器, 所有 key 都带 prefix
+type RateLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRateLimiter(client *redis.Client, prefix string) *RateLimiter {
+	return &RateLimiter{client: client, prefix: prefix}
+}
+
+// Allow 在 window 内最多放行 limit 次, 被拒绝时 RetryAfter 给出建议的重试等待时间
+func (r *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (RateResult, error) {
+	return r.AllowN(ctx, key, 1, limit, window)
+}
+
+// AllowN 一次占用 n 个名额, 要么全部放行要么全部拒绝. n 超过 limit 时永远不会放行, RetryAfter 为 window.
+func (r *RateLimiter) AllowN(ctx context.Context, key string, n, limit int, window time.Duration) (RateResult, error) {
+	if limit <= 0 {
+		return RateResult{Allowed: true, Remaining: -1}, nil
+	}
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%s", now, uuid.NewString())
+	vals, err := slidingWindowScript.Run(ctx, r.client, []string{r.prefix + key},
+		now, window.Milliseconds(), limit, member, n).Int64Slice()
+	if err != nil {
+		return RateResult{}, err
+	}
+	return RateResult{
+		Allowed:    vals[0] == 1,
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+	}, nil
+}
+
+// Count 返回窗口内已经放行的次数 (只读, 不占名额)
+func (r *RateLimiter) Count(ctx context.Context, key string, window time.Duration) (int, error) {
+	from := time.Now().Add(-window).UnixMilli()
+	n, err := r.client.ZCount(ctx, r.prefix+key, fmt.Sprintf("(%d", from), "+inf").Result()
+	return int(n), err
+}
@@ -0,0 +1,122 @@
+package jobsign
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+)
+
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+func signedJob(priv ed25519.PrivateKey, now time.Time) *pb.Job {
+	job := &pb.Job{
+		JobId:     "job-1",
+		AgentId:   "agent-1",
+		Type:      pb.JobType_SHELL,
+		Payload:   "uptime",
+		ExpiresAt: now.Add(time.Hour).Unix(),
+		Secrets:   []*pb.SecretEnv{{Env: "DB_PASSWORD", Name: "db-prod"}},
+	}
+	Sign(priv, job)
+	return job
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv := newKey(t)
+	otherPub, _ := newKey(t)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		pub    ed25519.PublicKey
+		agent  string
+		at     time.Time
+		mutate func(*pb.Job)
+		want   error
+	}{
+		{name: "valid", want: nil},
+		{name: "value is not signed", mutate: func(j *pb.Job) { j.Secrets[0].Value = "hunter2" }, want: nil},
+		{name: "within clock skew", at: now.Add(time.Hour + ClockSkew/2), want: nil},
+		{name: "unsigned", mutate: func(j *pb.Job) { j.Signature = nil }, want: ErrUnsigned},
+		{name: "other key", pub: otherPub, want: ErrBadSignature},
+		{name: "tampered type", mutate: func(j *pb.Job) { j.Type = pb.JobType_SCAN }, want: ErrBadSignature},
+		{name: "tampered payload", mutate: func(j *pb.Job) { j.Payload = "rm -rf /" }, want: ErrBadSignature},
+		{name: "tampered job id", mutate: func(j *pb.Job) { j.JobId = "job-2" }, want: ErrBadSignature},
+		{name: "tampered agent", mutate: func(j *pb.Job) { j.AgentId = "agent-2" }, agent: "agent-2", want: ErrBadSignature},
+		{name: "tampered expiry", mutate: func(j *pb.Job) { j.ExpiresAt += 3600 }, want: ErrBadSignature},
+		{name: "tampered secret name", mutate: func(j *pb.Job) { j.Secrets[0].Name = "db-staging" }, want: ErrBadSignature},
+		{name: "tampered secret env", mutate: func(j *pb.Job) { j.Secrets[0].Env = "PATH" }, want: ErrBadSignature},
+		{name: "added secret", mutate: func(j *pb.Job) { j.Secrets = append(j.Secrets, &pb.SecretEnv{Env: "X", Name: "y"}) }, want: ErrBadSignature},
+		{name: "removed secret", mutate: func(j *pb.Job) { j.Secrets = nil }, want: ErrBadSignature},
+		{name: "wrong agent", agent: "agent-2", want: ErrWrongAgent},
+		{name: "expired", at: now.Add(time.Hour + 2*ClockSkew), want: ErrExpired},
+		{
+			name: "release signature",
+			mutate: func(j *pb.Job) {
+				j.Signature = SignRelease(priv, "v1.2.3", "linux", "amd64", "abc", 42)
+			},
+			want: ErrBadSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := signedJob(priv, now)
+			if tt.mutate != nil {
+				tt.mutate(job)
+			}
+			key, agent, at := pub, "agent-1", now
+			if tt.pub != nil {
+				key = tt.pub
+			}
+			if tt.agent != "" {
+				agent = tt.agent
+			}
+			if !tt.at.IsZero() {
+				at = tt.at
+			}
+			err := Verify(key, job, agent, at)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRelease(t *testing.T) {
+	pub, priv := newKey(t)
+	job := signedJob(priv, time.Now())
+
+	tests := []struct {
+		name     string
+		sig      []byte
+		goos     string
+		arch     string
+		sha, ver string
+		want     error
+	}{
+		{name: "valid", sig: SignRelease(priv, "v1", "linux", "amd64", "abc", 42), goos: "linux", arch: "amd64", sha: "abc", ver: "v1"},
+		{name: "unsigned", goos: "linux", arch: "amd64", sha: "abc", ver: "v1", want: ErrReleaseUnsigned},
+		{name: "other platform", sig: SignRelease(priv, "v1", "linux", "amd64", "abc", 42), goos: "linux", arch: "arm64", sha: "abc", ver: "v1", want: ErrReleaseBadSignature},
+		{name: "tampered checksum", sig: SignRelease(priv, "v1", "linux", "amd64", "abc", 42), goos: "linux", arch: "amd64", sha: "def", ver: "v1", want: ErrReleaseBadSignature},
+		{name: "job signature", sig: job.Signature, goos: "linux", arch: "amd64", sha: "abc", ver: "v1", want: ErrReleaseBadSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upd := &pb.AgentUpdate{Version: tt.ver, Sha256: tt.sha, Size: 42, Signature: tt.sig}
+			err := VerifyRelease(pub, upd, tt.goos, tt.arch)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyRelease = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package jobsign
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+
+	pb "github.com/stywzn/Go-Cloud-Compute/api/proto"
+)
+
+// 自更新指令用单独的前缀, 任务签名不能被拿来冒充升级指令, 反之亦然
+const releasePrefix = "sentinel-release-v1"
+
+var (
+	ErrReleaseUnsigned     = errors.New("agent update is not signed")
+	ErrReleaseBadSignature = errors.New("agent update signature is invalid")
+)
+
+// ReleaseMessage 自更新指令签名覆盖的内容: 版本、平台、二进制的 SHA-256 和大小.
+// 签名在上传时由控制面生成, 数据库里改了校验和或者换了文件都对不上.
+func ReleaseMessage(version, goos, arch, sha256 string, size int64) []byte {
+	var b []byte
+	b = append(b, releasePrefix...)
+	for _, f := range []string{version, goos, arch, sha256} {
+		b = appendField(b, f)
+	}
+	return binary.BigEndian.AppendUint64(b, uint64(size))
+}
+
+func SignRelease(key ed25519.PrivateKey, version, goos, arch, sha256 string, size int64) []byte {
+	return ed25519.Sign(key, ReleaseMessage(version, goos, arch, sha256, size))
+}
+
+// VerifyRelease goos / arch 由 Agent 自己填, 别的平台的二进制通不过校验
+func VerifyRelease(pub ed25519.PublicKey, upd *pb.AgentUpdate, goos, arch string) error {
+	if len(upd.Signature) == 0 {
+		return ErrReleaseUnsigned
+	}
+	if !ed25519.Verify(pub, ReleaseMessage(upd.Version, goos, arch, upd.Sha256, upd.Size), upd.Signature) {
+		return ErrReleaseBadSignature
+	}
+	return nil
+}
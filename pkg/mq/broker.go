@@ -0,0 +1,148 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message 与具体中间件无关的消息
+type Message struct {
+	ID          string
+	ContentType string
+	Type        string
+	Headers     map[string]any
+	Body        []byte
+	Timestamp   time.Time
+}
+
+// Delivery 投递给订阅者的一条消息. 处理完必须 Ack 或 Nack 其一:
+// Nack 会按 ConsumeOptions.RetryDelays 进入下一级重试, 用完 (或错误被 Permanent 包装) 后进入死信队列.
+type Delivery struct {
+	Message
+	Exchange   string
+	RoutingKey string
+	// Attempt 这条消息之前已经失败过的次数
+	Attempt int
+
+	ack     func() error
+	nack    func(reason error) error
+	settled bool
+}
+
+func (d *Delivery) Ack() error {
+	if d.settled {
+		return nil
+	}
+	d.settled = true
+	return d.ack()
+}
+
+func (d *Delivery) Nack(reason error) error {
+	if d.settled {
+		return nil
+	}
+	d.settled = true
+	if reason == nil {
+		reason = errors.New("nacked")
+	}
+	return d.nack(reason)
+}
+
+// DeliveryHandler 同步处理一条消息; 返回时还没 Ack/Nack 的消息按 Nack 处理
+type DeliveryHandler func(ctx context.Context, d *Delivery)
+
+// Handler 返回 nil 表示处理成功 (ack); 返回错误则进入下一级重试队列;
+// 返回 Permanent 包装的错误则跳过重试直接进入死信队列.
+type Handler func(ctx context.Context, d *Delivery) error
+
+// Handle 把"返回 error"风格的 Handler 适配成 DeliveryHandler
+func Handle(h Handler) DeliveryHandler {
+	return func(ctx context.Context, d *Delivery) {
+		if err := safeCall(ctx, h, d); err != nil {
+			d.Nack(err)
+			return
+		}
+		d.Ack()
+	}
+}
+
+// Broker 消息中间件的抽象. AMQPBroker 对接 RabbitMQ, MemoryBroker 用于本地运行和单元测试.
+type Broker interface {
+	// DeclareExchange 声明 topic exchange, 重复声明是幂等的
+	DeclareExchange(ctx context.Context, name string) error
+	// Publish 发布到 exchange; exchange 为空时直接投递到名为 key 的队列
+	Publish(ctx context.Context, exchange, key string, msg Message) error
+	// Subscribe 声明工作队列 (及其重试 / 死信队列) 并阻塞消费, 直到 ctx 结束
+	Subscribe(ctx context.Context, opts ConsumeOptions, handler DeliveryHandler) error
+	Close() error
+}
+
+// New 按 URL 选择实现: 空串或 memory:// 使用进程内实现, amqp:// / amqps:// 使用 RabbitMQ
+func New(url string) (Broker, error) {
+	switch {
+	case url == "" || strings.HasPrefix(url, "memory://"):
+		return NewMemoryBroker(), nil
+	case strings.HasPrefix(url, "amqp://"), strings.HasPrefix(url, "amqps://"):
+		return NewAMQPBroker(url)
+	default:
+		return nil, fmt.Errorf("mq: unsupported broker url %q", url)
+	}
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记不可重试的错误 (比如消息格式非法), 重试多少次都不会成功
+func Permanent(err error) error { return permanentError{err} }
+
+func isPermanent(err error) bool {
+	var perm permanentError
+	return errors.As(err, &perm)
+}
+
+func safeCall(ctx context.Context, handler Handler, d *Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, d)
+}
+
+// DefaultRetryDelays 默认三级退避: 5s -> 30s -> 2min, 仍失败进入死信队列
+var DefaultRetryDelays = []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute}
+
+// ConsumeOptions 一个工作队列及其重试 / 死信队列的定义
+type ConsumeOptions struct {
+	Queue string
+	// Exchange / RoutingKeys 可选: 把工作队列绑定到 topic exchange 上
+	Exchange    string
+	RoutingKeys []string
+	Prefetch    int
+	// RetryDelays 每一级重试的延迟, 为空时使用 DefaultRetryDelays
+	RetryDelays []time.Duration
+}
+
+func (o ConsumeOptions) retryQueue(level int) string {
+	return fmt.Sprintf("%s.retry.%d", o.Queue, level+1)
+}
+func (o ConsumeOptions) deadLetterQueue() string { return o.Queue + ".dlq" }
+
+func (o ConsumeOptions) delays() []time.Duration {
+	if len(o.RetryDelays) > 0 {
+		return o.RetryDelays
+	}
+	return DefaultRetryDelays
+}
+
+func (o ConsumeOptions) prefetch() int {
+	if o.Prefetch <= 0 {
+		return 1
+	}
+	return o.Prefetch
+}
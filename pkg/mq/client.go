@@ -0,0 +1,214 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+var ErrClosed = errors.New("mq: client closed")
+
+const (
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// Topology 每次(重)连上之后都要重新声明的 exchange / queue / binding
+type Topology func(ch *amqp.Channel) error
+
+// Client 带自动重连的 RabbitMQ 连接.
+// 断线后按指数退避重连, 重新打开 confirm 模式的发布 channel 并重放所有 Topology,
+// 正在运行的 Consume 会在新连接上自动恢复.
+type Client struct {
+	url string
+
+	mu         sync.Mutex
+	conn       *amqp.Connection
+	ready      chan struct{} // 连接可用时关闭, 断线时换一个新的
+	topologies []Topology
+
+	pubMu    sync.Mutex
+	pubCh    *amqp.Channel
+	confirms chan amqp.Confirmation
+	pubSeq   uint64 // 当前发布 channel 上最后一条消息的 delivery tag
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewClient 首次连接失败直接返回错误, 之后的断线由 Client 自己处理
+func NewClient(url string) (*Client, error) {
+	c := &Client{
+		url:    url,
+		ready:  make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) connect() error {
+	conn, err := amqp.Dial(c.url)
+	if err != nil {
+		return fmt.Errorf("connect rabbitmq: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("open channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return fmt.Errorf("enable publisher confirms: %w", err)
+	}
+
+	c.mu.Lock()
+	topologies := append([]Topology(nil), c.topologies...)
+	c.mu.Unlock()
+	for _, t := range topologies {
+		if err := t(ch); err != nil {
+			conn.Close()
+			return fmt.Errorf("declare topology: %w", err)
+		}
+	}
+
+	c.pubMu.Lock()
+	c.pubCh = ch
+	c.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 16))
+	c.pubSeq = 0
+	c.pubMu.Unlock()
+
+	c.mu.Lock()
+	c.conn = conn
+	close(c.ready)
+	c.mu.Unlock()
+
+	go c.watch(conn)
+	return nil
+}
+
+// watch 等待连接断开, 然后一直重连直到成功或 Client 被关闭
+func (c *Client) watch(conn *amqp.Connection) {
+	closeErr := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	c.mu.Lock()
+	c.ready = make(chan struct{})
+	c.mu.Unlock()
+
+	select {
+	case <-c.closed:
+		return
+	default:
+	}
+	log.Printf("[MQ] 连接断开: %v, 开始重连", closeErr)
+
+	delay := reconnectMinDelay
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-time.After(delay):
+		}
+		if err := c.connect(); err != nil {
+			log.Printf("[MQ] 重连失败, %s 后重试: %v", delay, err)
+			delay *= 2
+			if delay > reconnectMaxDelay {
+				delay = reconnectMaxDelay
+			}
+			continue
+		}
+		log.Println("[MQ] 重连成功, 拓扑已重新声明")
+		return
+	}
+}
+
+// connection 阻塞直到有可用连接
+func (c *Client) connection(ctx context.Context) (*amqp.Connection, error) {
+	for {
+		c.mu.Lock()
+		ready, conn := c.ready, c.conn
+		c.mu.Unlock()
+
+		select {
+		case <-ready:
+			if !conn.IsClosed() {
+				return conn, nil
+			}
+			// 连接刚断, watch 还没来得及换 ready, 稍等再看
+			select {
+			case <-time.After(100 * time.Millisecond):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		case <-c.closed:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Declare 立即声明一次拓扑, 并在之后每次重连时重放
+func (c *Client) Declare(t Topology) error {
+	c.mu.Lock()
+	c.topologies = append(c.topologies, t)
+	c.mu.Unlock()
+
+	c.pubMu.Lock()
+	defer c.pubMu.Unlock()
+	return t(c.pubCh)
+}
+
+// Publish 发布并等待 broker 确认 (publisher confirm), 收到 nack 或超时都返回错误
+func (c *Client) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	if _, err := c.connection(ctx); err != nil {
+		return err
+	}
+
+	c.pubMu.Lock()
+	defer c.pubMu.Unlock()
+
+	if err := c.pubCh.Publish(exchange, key, false, false, msg); err != nil {
+		return err
+	}
+	c.pubSeq++
+	for {
+		select {
+		case confirm, ok := <-c.confirms:
+			if !ok {
+				return errors.New("mq: channel closed before confirm")
+			}
+			// 之前超时放弃等待的消息, 它们的确认会晚到, 跳过
+			if confirm.DeliveryTag < c.pubSeq {
+				continue
+			}
+			if !confirm.Ack {
+				return fmt.Errorf("mq: broker nacked message (tag=%d)", confirm.DeliveryTag)
+			}
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.mu.Lock()
+		conn := c.conn
+		c.mu.Unlock()
+		if conn != nil {
+			err = conn.Close()
+		}
+	})
+	return err
+}
@@ -0,0 +1,186 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const retryCountHeader = "x-retry-count"
+
+// DeclareWorkQueue 声明工作队列、每一级重试队列和死信队列:
+//
+//	queue          --nack(requeue=false)-->  queue.dlq
+//	queue.retry.N  --TTL 到期-->              queue  (经默认 exchange 回到工作队列)
+//
+// 注意: 带参数的队列不能与已存在的同名无参数队列共存, 迁移时需要先删掉旧队列.
+func DeclareWorkQueue(ch *amqp.Channel, o ConsumeOptions) error {
+	if _, err := ch.QueueDeclare(o.deadLetterQueue(), true, false, false, false, nil); err != nil {
+		return err
+	}
+	_, err := ch.QueueDeclare(o.Queue, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": o.deadLetterQueue(),
+	})
+	if err != nil {
+		return err
+	}
+	for i, delay := range o.delays() {
+		_, err := ch.QueueDeclare(o.retryQueue(i), true, false, false, false, amqp.Table{
+			"x-message-ttl":             int32(delay.Milliseconds()),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": o.Queue,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, key := range o.RoutingKeys {
+		if err := ch.QueueBind(o.Queue, key, o.Exchange, false, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Consume 阻塞消费直到 ctx 结束. 手动 ack; 断线后等 Client 重连成功再重新声明队列并继续消费.
+func (c *Client) Consume(ctx context.Context, o ConsumeOptions, handler DeliveryHandler) error {
+	if err := c.Declare(func(ch *amqp.Channel) error { return DeclareWorkQueue(ch, o) }); err != nil {
+		return err
+	}
+	for {
+		err := c.consumeOnce(ctx, o, handler)
+		if ctx.Err() != nil || errors.Is(err, ErrClosed) {
+			return nil
+		}
+		log.Printf("[MQ] 消费 %s 中断: %v, 等待重连", o.Queue, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectMinDelay):
+		}
+	}
+}
+
+func (c *Client) consumeOnce(ctx context.Context, o ConsumeOptions, handler DeliveryHandler) error {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.Qos(o.prefetch(), 0, false); err != nil {
+		return err
+	}
+	deliveries, err := ch.Consume(o.Queue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	log.Printf("[MQ] 开始消费队列 %s (prefetch=%d)", o.Queue, o.prefetch())
+
+	// prefetch 个 goroutine 并发处理, 与 MemoryBroker 的语义一致
+	errs := make(chan error, o.prefetch())
+	var wg sync.WaitGroup
+	for i := 0; i < o.prefetch(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				case d, ok := <-deliveries:
+					if !ok {
+						errs <- errors.New("delivery channel closed")
+						return
+					}
+					delivery := c.wrap(o, d)
+					handler(ctx, delivery)
+					delivery.Nack(errors.New("handler returned without ack"))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return <-errs
+}
+
+func (c *Client) wrap(o ConsumeOptions, d amqp.Delivery) *Delivery {
+	headers := make(map[string]any, len(d.Headers))
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	return &Delivery{
+		Message: Message{
+			ID:          d.MessageId,
+			ContentType: d.ContentType,
+			Type:        d.Type,
+			Headers:     headers,
+			Body:        d.Body,
+			Timestamp:   d.Timestamp,
+		},
+		Exchange:   d.Exchange,
+		RoutingKey: d.RoutingKey,
+		Attempt:    retryCount(d),
+		ack:        func() error { return d.Ack(false) },
+		nack:       func(reason error) error { return c.settle(o, d, reason) },
+	}
+}
+
+// settle 失败的消息转投到下一级重试队列或死信队列
+func (c *Client) settle(o ConsumeOptions, d amqp.Delivery, reason error) error {
+	attempt := retryCount(d)
+	delays := o.delays()
+	target := o.deadLetterQueue()
+	if !isPermanent(reason) && attempt < len(delays) {
+		target = o.retryQueue(attempt)
+	}
+
+	// 先确认转投成功再 ack 原消息, 保证至少一次
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[retryCountHeader] = int32(attempt + 1)
+	headers["x-last-error"] = reason.Error()
+
+	pctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	perr := c.Publish(pctx, "", target, amqp.Publishing{
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Type:         d.Type,
+		Headers:      headers,
+		Body:         d.Body,
+	})
+	if perr != nil {
+		// 转投失败: 放回原队列, 下次再试
+		log.Printf("[MQ] 消息 %s 转投 %s 失败, 重新入队: %v", d.MessageId, target, perr)
+		return d.Nack(false, true)
+	}
+	log.Printf("[MQ] 消息 %s 处理失败 (第 %d 次): %v -> %s", d.MessageId, attempt+1, reason, target)
+	return d.Ack(false)
+}
+
+func retryCount(d amqp.Delivery) int {
+	switch v := d.Headers[retryCountHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,252 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryBroker 进程内的 Broker 实现, 语义与 AMQPBroker 对齐 (topic 路由、手动 ack、重试、死信),
+// 但不持久化: 进程退出消息就没了. 适合本地开发和单元测试.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	exchanges map[string][]memBinding
+	queues    map[string]*memQueue
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type memBinding struct {
+	pattern string
+	queue   string
+}
+
+type memItem struct {
+	msg        Message
+	exchange   string
+	routingKey string
+	attempt    int
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		exchanges: make(map[string][]memBinding),
+		queues:    make(map[string]*memQueue),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (b *MemoryBroker) DeclareExchange(ctx context.Context, name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.exchanges[name]; !ok {
+		b.exchanges[name] = nil
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, exchange, key string, msg Message) error {
+	select {
+	case <-b.closed:
+		return ErrClosed
+	default:
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+	item := memItem{msg: msg, exchange: exchange, routingKey: key}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if exchange == "" {
+		b.queue(key).push(item)
+		return nil
+	}
+	bindings, ok := b.exchanges[exchange]
+	if !ok {
+		return fmt.Errorf("mq: exchange %q not declared", exchange)
+	}
+	// 和 RabbitMQ 一样, 没有队列匹配的消息直接丢弃
+	for _, bd := range bindings {
+		if topicMatch(bd.pattern, key) {
+			b.queue(bd.queue).push(item)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, opts ConsumeOptions, handler DeliveryHandler) error {
+	b.mu.Lock()
+	q := b.queue(opts.Queue)
+	b.queue(opts.deadLetterQueue())
+	for _, key := range opts.RoutingKeys {
+		if _, ok := b.exchanges[opts.Exchange]; !ok {
+			b.mu.Unlock()
+			return fmt.Errorf("mq: exchange %q not declared", opts.Exchange)
+		}
+		b.exchanges[opts.Exchange] = append(b.exchanges[opts.Exchange], memBinding{pattern: key, queue: opts.Queue})
+	}
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-b.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.prefetch(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, ok := q.pop(ctx)
+				if !ok {
+					return
+				}
+				d := b.delivery(opts, item)
+				handler(ctx, d)
+				d.Nack(errors.New("handler returned without ack"))
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (b *MemoryBroker) delivery(o ConsumeOptions, item memItem) *Delivery {
+	return &Delivery{
+		Message:    item.msg,
+		Exchange:   item.exchange,
+		RoutingKey: item.routingKey,
+		Attempt:    item.attempt,
+		ack:        func() error { return nil },
+		nack: func(reason error) error {
+			delays := o.delays()
+			item.attempt++
+			if isPermanent(reason) || item.attempt > len(delays) {
+				log.Printf("[MQ] 消息 %s 处理失败 (第 %d 次): %v -> %s", item.msg.ID, item.attempt, reason, o.deadLetterQueue())
+				b.mu.Lock()
+				b.queue(o.deadLetterQueue()).push(item)
+				b.mu.Unlock()
+				return nil
+			}
+			log.Printf("[MQ] 消息 %s 处理失败 (第 %d 次): %v, %s 后重试", item.msg.ID, item.attempt, reason, delays[item.attempt-1])
+			time.AfterFunc(delays[item.attempt-1], func() {
+				b.mu.Lock()
+				b.queue(o.Queue).push(item)
+				b.mu.Unlock()
+			})
+			return nil
+		},
+	}
+}
+
+// DeadLetters 返回某个工作队列死信队列里的消息 (不会移除), 便于排查和测试断言
+func (b *MemoryBroker) DeadLetters(queue string) []Message {
+	b.mu.Lock()
+	q := b.queue(ConsumeOptions{Queue: queue}.deadLetterQueue())
+	b.mu.Unlock()
+	return q.snapshot()
+}
+
+func (b *MemoryBroker) Close() error {
+	b.closeOnce.Do(func() { close(b.closed) })
+	return nil
+}
+
+// queue 调用方需持有 b.mu
+func (b *MemoryBroker) queue(name string) *memQueue {
+	q, ok := b.queues[name]
+	if !ok {
+		q = &memQueue{notify: make(chan struct{}, 1)}
+		b.queues[name] = q
+	}
+	return q
+}
+
+// memQueue 无界 FIFO
+type memQueue struct {
+	mu     sync.Mutex
+	items  []memItem
+	notify chan struct{}
+}
+
+func (q *memQueue) push(item memItem) {
+	q.mu.Lock()
+	q.items = append(q.items, item)
+	q.mu.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *memQueue) pop(ctx context.Context) (memItem, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			item := q.items[0]
+			q.items = q.items[1:]
+			more := len(q.items) > 0
+			q.mu.Unlock()
+			if more {
+				// 唤醒其他 worker
+				select {
+				case q.notify <- struct{}{}:
+				default:
+				}
+			}
+			return item, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return memItem{}, false
+		case <-q.notify:
+		}
+	}
+}
+
+func (q *memQueue) snapshot() []Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]Message, len(q.items))
+	for i, item := range q.items {
+		out[i] = item.msg
+	}
+	return out
+}
+
+// topicMatch AMQP topic 语义: 以 "." 分词, "*" 匹配一个词, "#" 匹配零个或多个词
+func topicMatch(pattern, key string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(key, "."))
+}
+
+func matchWords(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if matchWords(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) > 0 && matchWords(pattern[1:], key[1:])
+	default:
+		return len(key) > 0 && pattern[0] == key[0] && matchWords(pattern[1:], key[1:])
+	}
+}
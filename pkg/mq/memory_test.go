@@ -0,0 +1,263 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTopicMatch(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"job.completed", "job.completed", true},
+		{"job.completed", "job.failed", false},
+		{"job.*", "job.failed", true},
+		{"job.*", "job", false},
+		{"job.*", "job.failed.retry", false},
+		{"*.offline", "agent.offline", true},
+		{"#", "agent.online", true},
+		{"#", "", true},
+		{"job.#", "job", true},
+		{"job.#", "job.a.b.c", true},
+		{"job.#", "agent.online", false},
+		{"#.offline", "agent.offline", true},
+		{"#.offline", "a.b.offline", true},
+		{"a.#.z", "a.z", true},
+		{"a.#.z", "a.b.c.z", true},
+		{"a.#.z", "a.b.c", false},
+		{"a.*.#", "a", false},
+		{"a.*.#", "a.b", true},
+	}
+	for _, tt := range tests {
+		if got := topicMatch(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("topicMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+// subscribe 在后台消费, 等到绑定生效之后才返回, 否则之前发布的消息会因为没有队列匹配被丢弃
+func subscribe(t *testing.T, b *MemoryBroker, opts ConsumeOptions, h DeliveryHandler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := b.Subscribe(ctx, opts, h); err != nil {
+			t.Errorf("Subscribe: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	deadline := time.Now().Add(time.Second)
+	for {
+		b.mu.Lock()
+		_, bound := b.queues[opts.Queue]
+		b.mu.Unlock()
+		if bound {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscription was not bound in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestMemoryBrokerRouting(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBroker()
+	defer b.Close()
+	if err := b.DeclareExchange(ctx, "events"); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	got := map[string][]string{}
+	record := func(queue string) DeliveryHandler {
+		return Handle(func(ctx context.Context, d *Delivery) error {
+			mu.Lock()
+			got[queue] = append(got[queue], d.RoutingKey)
+			mu.Unlock()
+			return nil
+		})
+	}
+	subscribe(t, b, ConsumeOptions{Queue: "jobs", Exchange: "events", RoutingKeys: []string{"job.*"}}, record("jobs"))
+	subscribe(t, b, ConsumeOptions{Queue: "offline", Exchange: "events", RoutingKeys: []string{"#.offline"}}, record("offline"))
+
+	for _, key := range []string{"job.queued", "agent.online", "agent.offline", "job.completed"} {
+		if err := b.Publish(ctx, "events", key, Message{ID: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Publish(ctx, "missing", "job.queued", Message{}); err == nil {
+		t.Error("publishing to an undeclared exchange should fail")
+	}
+
+	want := map[string][]string{
+		"jobs":    {"job.queued", "job.completed"},
+		"offline": {"agent.offline"},
+	}
+	waitFor(t, "deliveries", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got["jobs"]) == 2 && len(got["offline"]) == 1
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	for queue, keys := range want {
+		for i, key := range keys {
+			if got[queue][i] != key {
+				t.Errorf("%s[%d] = %q, want %q", queue, i, got[queue][i], key)
+			}
+		}
+	}
+}
+
+func TestMemoryBrokerRetryAndDeadLetter(t *testing.T) {
+	delays := []time.Duration{time.Millisecond, 2 * time.Millisecond}
+	tests := []struct {
+		name string
+		// fail 决定第 attempt 次投递 (从 0 开始) 的处理结果, nil 表示 ack
+		fail         func(attempt int) error
+		wantAttempts int
+		wantDead     bool
+	}{
+		{"ack first time", func(int) error { return nil }, 1, false},
+		{"succeed on retry", func(attempt int) error {
+			if attempt == 0 {
+				return errors.New("transient")
+			}
+			return nil
+		}, 2, false},
+		{"retries exhausted", func(int) error { return errors.New("still failing") }, len(delays) + 1, true},
+		{"permanent skips retries", func(int) error { return Permanent(errors.New("bad message")) }, 1, true},
+		{"panic is retried", func(attempt int) error {
+			if attempt == 0 {
+				panic("boom")
+			}
+			return nil
+		}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewMemoryBroker()
+			defer b.Close()
+
+			var mu sync.Mutex
+			var attempts []int
+			opts := ConsumeOptions{Queue: "work", RetryDelays: delays}
+			subscribe(t, b, opts, Handle(func(ctx context.Context, d *Delivery) error {
+				mu.Lock()
+				attempts = append(attempts, d.Attempt)
+				mu.Unlock()
+				return tt.fail(d.Attempt)
+			}))
+			if err := b.Publish(context.Background(), "", "work", Message{ID: "m1", Body: []byte("x")}); err != nil {
+				t.Fatal(err)
+			}
+
+			waitFor(t, "attempts", func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(attempts) >= tt.wantAttempts
+			})
+			if tt.wantDead {
+				waitFor(t, "dead letter", func() bool { return len(b.DeadLetters("work")) == 1 })
+			}
+			// 再等几个重试周期, 确认没有多余的投递
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(attempts) != tt.wantAttempts {
+				t.Fatalf("delivered %d times, want %d", len(attempts), tt.wantAttempts)
+			}
+			for i, a := range attempts {
+				if a != i {
+					t.Errorf("delivery %d has Attempt %d", i, a)
+				}
+			}
+			dead := b.DeadLetters("work")
+			if tt.wantDead != (len(dead) == 1) {
+				t.Fatalf("dead letters = %d, want dead=%v", len(dead), tt.wantDead)
+			}
+			if tt.wantDead && dead[0].ID != "m1" {
+				t.Errorf("dead letter ID = %q, want m1", dead[0].ID)
+			}
+		})
+	}
+}
+
+func TestMemoryBrokerUnsettledDeliveryIsNacked(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	var mu sync.Mutex
+	var n int
+	opts := ConsumeOptions{Queue: "work", RetryDelays: []time.Duration{time.Millisecond}}
+	subscribe(t, b, opts, func(ctx context.Context, d *Delivery) {
+		mu.Lock()
+		n++
+		mu.Unlock()
+	})
+	if err := b.Publish(context.Background(), "", "work", Message{ID: "m1"}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "dead letter", func() bool { return len(b.DeadLetters("work")) == 1 })
+	mu.Lock()
+	defer mu.Unlock()
+	if n != 2 {
+		t.Errorf("delivered %d times, want 2", n)
+	}
+}
+
+func TestMemoryBrokerAckIsFinal(t *testing.T) {
+	d := &Delivery{}
+	var acks, nacks int
+	d.ack = func() error { acks++; return nil }
+	d.nack = func(error) error { nacks++; return nil }
+	d.Ack()
+	d.Nack(errors.New("late"))
+	d.Ack()
+	if acks != 1 || nacks != 0 {
+		t.Errorf("acks=%d nacks=%d, want 1 and 0", acks, nacks)
+	}
+}
+
+func TestMemoryBrokerClosed(t *testing.T) {
+	b := NewMemoryBroker()
+	b.Close()
+	if err := b.Publish(context.Background(), "", "work", Message{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close = %v, want ErrClosed", err)
+	}
+	// Close 之后 Subscribe 立即返回
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Subscribe(context.Background(), ConsumeOptions{Queue: "work"}, func(context.Context, *Delivery) {})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Subscribe after Close = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe did not return after Close")
+	}
+}
@@ -1,62 +1,59 @@
 package mq
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"time"
 
-	"github.com/spf13/viper"
 	"github.com/streadway/amqp"
 )
 
-var Conn *amqp.Connection
-var Channel *amqp.Channel
-var QueueName string
-
-func Init() {
-	// 读取配置
-	url := fmt.Sprintf("amqp://%s:%s@%s:%s/",
-		viper.GetString("rabbitmq.user"),
-		viper.GetString("rabbitmq.password"),
-		viper.GetString("rabbitmq.host"),
-		viper.GetString("rabbitmq.port"),
-	)
-
-	var err error
-	Conn, err = amqp.Dial(url)
-	if err != nil {
-		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
-	}
+// AMQPBroker 基于 RabbitMQ 的 Broker 实现, 断线重连 / publisher confirm / 重试队列都由 Client 负责
+type AMQPBroker struct {
+	client *Client
+}
 
-	Channel, err = Conn.Channel()
+func NewAMQPBroker(url string) (*AMQPBroker, error) {
+	client, err := NewClient(url)
 	if err != nil {
-		log.Fatalf("Failed to open a channel: %v", err)
+		return nil, err
 	}
+	return &AMQPBroker{client: client}, nil
+}
 
-	QueueName = viper.GetString("rabbitmq.queue_name")
-
-	// 声明队列
-	_, err = Channel.QueueDeclare(
-		QueueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
-	if err != nil {
-		log.Fatalf("Failed to declare a queue: %v", err)
+func (b *AMQPBroker) DeclareExchange(ctx context.Context, name string) error {
+	return b.client.Declare(func(ch *amqp.Channel) error {
+		return ch.ExchangeDeclare(
+			name,    // name
+			"topic", // kind
+			true,    // durable
+			false,   // auto-deleted
+			false,   // internal
+			false,   // no-wait
+			nil,     // arguments
+		)
+	})
+}
+
+func (b *AMQPBroker) Publish(ctx context.Context, exchange, key string, msg Message) error {
+	ts := msg.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
 	}
-	log.Println("RabbitMQ connected.")
+	return b.client.Publish(ctx, exchange, key, amqp.Publishing{
+		ContentType:  msg.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.ID,
+		Timestamp:    ts,
+		Type:         msg.Type,
+		Headers:      amqp.Table(msg.Headers),
+		Body:         msg.Body,
+	})
+}
+
+func (b *AMQPBroker) Subscribe(ctx context.Context, opts ConsumeOptions, handler DeliveryHandler) error {
+	return b.client.Consume(ctx, opts, handler)
 }
 
-func Publish(body string) error {
-	return Channel.Publish(
-		"",        // exchange
-		QueueName, // routing key
-		false,     // mandatory
-		false,     // immediate
-		amqp.Publishing{
-			ContentType: "text/plain",
-			Body:        []byte(body),
-		})
+func (b *AMQPBroker) Close() error {
+	return b.client.Close()
 }